@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// previewSize is the maximum number of bytes of data shown per record.
+const previewSize = 32
+
+// counter counts the number of bytes read, to report offsets.
+type counter struct {
+	in     io.Reader
+	offset int64
+}
+
+func (c *counter) Read(p []byte) (int, error) {
+	n, err := c.in.Read(p)
+	c.offset += int64(n)
+	return n, err
+}
+
+// dumper prints every header in the stream, without interpreting the values. Data of keys and bytes-values
+// is previewed, as text if valid printable UTF-8, otherwise as hexadecimal.
+type dumper struct {
+	in  *counter
+	out io.Writer
+	buf [prefixed.SIZE_2BYTE_MAX]byte
+	// open indicates that the current line is not yet completed.
+	open bool
+	// nesting is the nesting depth of composite values, counted as in `prefixed.Limits`.
+	nesting uint
+}
+
+// dump prints every header in the stream of values. In case of malformed data, everything up to the error is
+// printed, followed by the error with its offset.
+func dump(in io.Reader, out io.Writer) error {
+	var d = dumper{in: &counter{in: in}, out: out}
+	for {
+		var offset = d.in.offset
+		err := d.value(0)
+		if err == io.EOF && d.in.offset == offset {
+			return nil
+		} else if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			if d.open {
+				io.WriteString(out, "\n")
+			}
+			return errors.Context(err, fmt.Sprintf("at offset %08x", d.in.offset))
+		}
+	}
+}
+
+// header reads and prints a header. The header is printed before verifying the expected type, such that the
+// offending header is visible in the output.
+func (d *dumper) header(depth int, label string) (prefixed.Header, error) {
+	var offset = d.in.offset
+	h, err := prefixed.ReadHeader(d.in)
+	if err != nil {
+		return prefixed.Header{}, err
+	}
+	var flags = []byte("----")
+	if h.Terminated {
+		flags[0] = 'T'
+	}
+	if h.Vtype == prefixed.TYPE_KEYVALUE || h.Vtype == prefixed.TYPE_MAP {
+		flags[1] = 'K'
+	}
+	if h.Vtype == prefixed.TYPE_SEQUENCE || h.Vtype == prefixed.TYPE_MAP {
+		flags[2] = 'M'
+	}
+	if d.in.offset-offset == 2 {
+		flags[3] = 'H'
+	}
+	if label == "" {
+		label = h.Vtype.String()
+	}
+	var unit = "size"
+	if h.Vtype == prefixed.TYPE_SEQUENCE || h.Vtype == prefixed.TYPE_MAP {
+		unit = "count"
+	}
+	_, err = fmt.Fprintf(d.out, "%08x  %s%-10s %s %s=%d", offset, strings.Repeat("  ", depth), label,
+		string(flags), unit, h.Size)
+	d.open = true
+	return h, err
+}
+
+// end completes the current line with `suffix`.
+func (d *dumper) end(suffix string) error {
+	d.open = false
+	_, err := io.WriteString(d.out, suffix+"\n")
+	return err
+}
+
+// enter registers descending one level of nesting, failing if this exceeds the maximum depth of `limits`.
+func (d *dumper) enter() error {
+	if d.nesting >= limits.MaxDepth {
+		return errors.Context(prefixed.ErrLimitExceeded, "maximum nesting depth reached")
+	}
+	d.nesting++
+	return nil
+}
+
+// value dumps the next value.
+func (d *dumper) value(depth int) error {
+	h, err := d.header(depth, "")
+	if err != nil {
+		return err
+	}
+	switch h.Vtype {
+	case prefixed.TYPE_BYTES:
+		return d.raw(depth, h, "bytes")
+	case prefixed.TYPE_KEYVALUE:
+		if err = d.enter(); err != nil {
+			return err
+		}
+		defer func() { d.nesting-- }()
+		if err = d.raw(depth, h, "key"); err != nil {
+			return err
+		}
+		return d.value(depth + 1)
+	case prefixed.TYPE_SEQUENCE, prefixed.TYPE_MAP:
+		if err = d.enter(); err != nil {
+			return err
+		}
+		defer func() { d.nesting-- }()
+		var vtype = h.Vtype
+		for {
+			if err = d.end(""); err != nil {
+				return err
+			}
+			for i := uint16(0); i < h.Size; i++ {
+				if vtype == prefixed.TYPE_SEQUENCE {
+					err = d.value(depth + 1)
+				} else {
+					err = d.entry(depth + 1)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			if h.Terminated {
+				return nil
+			}
+			if h, err = d.header(depth, vtype.String()); err != nil {
+				return err
+			} else if h.Vtype != vtype {
+				return errors.Context(errors.ErrIllegal, "continuation has different type: "+h.Vtype.String())
+			}
+		}
+	default:
+		panic("BUG: should not be reached")
+	}
+}
+
+// entry dumps the next map-entry.
+func (d *dumper) entry(depth int) error {
+	h, err := d.header(depth, "key")
+	if err != nil {
+		return err
+	}
+	if h.Vtype != prefixed.TYPE_KEYVALUE {
+		return errors.Context(errors.ErrIllegal,
+			"expected key-value-pair as map-entry, got "+h.Vtype.String())
+	}
+	if err = d.raw(depth, h, "key"); err != nil {
+		return err
+	}
+	return d.value(depth + 1)
+}
+
+// raw previews the data of a key or bytes-value, including continuation records, which are labeled as
+// `label`. The header of the first record is already printed.
+func (d *dumper) raw(depth int, h prefixed.Header, label string) error {
+	var vtype = h.Vtype
+	var err error
+	for {
+		var data = d.buf[:h.Size]
+		if _, err = io.ReadFull(d.in, data); err != nil {
+			return err
+		}
+		if err = d.end(" " + preview(data)); err != nil {
+			return err
+		}
+		if h.Terminated {
+			return nil
+		}
+		if h, err = d.header(depth, label); err != nil {
+			return err
+		} else if h.Vtype != vtype {
+			return errors.Context(errors.ErrIllegal, "continuation has different type: "+h.Vtype.String())
+		}
+	}
+}
+
+// preview formats (the start of) the data.
+func preview(data []byte) string {
+	if printable(data) {
+		var n = len(data)
+		if n > previewSize {
+			// Cut the text at the start of a character.
+			for n = previewSize; !utf8.RuneStart(data[n]); n-- {
+			}
+		}
+		return strconv.Quote(string(data[:n])) + ellipsis(n < len(data))
+	}
+	var n = min(len(data), previewSize)
+	return "h'" + hex.EncodeToString(data[:n]) + "'" + ellipsis(n < len(data))
+}
+
+func ellipsis(truncated bool) string {
+	if truncated {
+		return "..."
+	}
+	return ""
+}
+
+// printable checks whether data is valid UTF-8 consisting of printable characters.
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !strconv.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestDump(t *testing.T) {
+	var data = encode(t, document)
+	code, stdout, stderr := execute([]string{"dump"}, data)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "", stderr)
+	assert.Equal(t, `00000000  map        TKM- count=3
+00000001    key        TK-- size=2 "kv"
+00000004      key-value  TK-- size=1 "k"
+00000006        bytes      T--- size=1 "v"
+00000008    key        TK-- size=4 "name"
+0000000d      bytes      T--- size=5 "hello"
+00000013    key        TK-- size=4 "tags"
+00000018      sequence   T-M- count=2
+00000019        bytes      T--- size=1 "a"
+0000001b        bytes      T--- size=2 h'00ff'
+`, stdout)
+}
+
+func TestDumpContinuation(t *testing.T) {
+	var b bytes.Buffer
+	_, err := prefixed.Bytes(strings.Repeat("x", 5000)).WriteTo(&b)
+	assert.Nil(t, err)
+	code, stdout, _ := execute([]string{"dump"}, b.Bytes())
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `00000000  bytes      ---H size=4096 "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"...
+00001002  bytes      T--H size=904 "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"...
+`, stdout)
+}
+
+func TestDumpMalformed(t *testing.T) {
+	var data = encode(t, document)
+	code, stdout, stderr := execute([]string{"dump"}, data[:0x14])
+	assert.Equal(t, 1, code)
+	assert.True(t, strings.HasSuffix(stdout, "00000013    key        TK-- size=4\n"))
+	assert.Equal(t, "dump: at offset 00000014: unexpected EOF\n", stderr)
+	// a map-entry that is not a key-value-pair
+	code, stdout, stderr = execute([]string{"dump"}, []byte{0xe1, 0x80})
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "00000000  map        TKM- count=1\n00000001    key        T--- size=0\n", stdout)
+	assert.True(t, strings.HasPrefix(stderr, "dump: at offset 00000002: expected key-value-pair"))
+}
+
+func TestDumpDepth(t *testing.T) {
+	var nested = strings.Repeat(`"k": `, 64) + `"v"`
+	code, stdout, _ := execute([]string{"dump"}, encode(t, nested))
+	assert.Equal(t, 0, code)
+	assert.Equal(t, 65, strings.Count(stdout, "\n"))
+	code, stdout, stderr := execute([]string{"dump"}, encode(t, "["+nested+"]"))
+	assert.Equal(t, 1, code)
+	// the offending header is printed before the error
+	assert.Equal(t, 65, strings.Count(stdout, "\n"))
+	assert.True(t, strings.Contains(stderr, prefixed.ErrLimitExceeded.Error()))
+}
+
+func TestPreview(t *testing.T) {
+	assert.Equal(t, `""`, preview(nil))
+	assert.Equal(t, `"héllo"`, preview([]byte("héllo")))
+	assert.Equal(t, `h'0a'`, preview([]byte("\n")))
+	assert.Equal(t, `h'ff'`, preview([]byte{0xff}))
+	// text is cut at the start of a character
+	assert.Equal(t, `"`+strings.Repeat("x", 31)+`"...`, preview([]byte(strings.Repeat("x", 31)+"éé")))
+	assert.Equal(t, "h'"+strings.Repeat("00", 32)+"'...", preview(make([]byte, 33)))
+}
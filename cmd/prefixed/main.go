@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Command prefixed inspects prefixed-compact encoded data. It reads a stream of values from a file or, if
+// no file is specified or the file is `-`, from stdin.
+//
+// Usage:
+//
+//	prefixed verify [file]    verify the structure of all values
+//	prefixed dump [file]      print every header with offset, flags and size
+//	prefixed tojson [file]    convert values to JSON, one document per line
+//	prefixed fromjson [file]  convert JSON documents to values
+//	prefixed stats [file]     print depth, counts and largest values
+//
+// The JSON mapping is that of `prefixed.ToJSON` and `prefixed.FromJSON`.
+//
+// Input may be untrusted, therefore values are decoded within limits: a nesting depth of at most 64, at most
+// 1048576 entries and at most 64 MiB per value.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+const usage = `Usage: prefixed <command> [file]
+
+Commands:
+  verify    verify the structure of all values
+  dump      print every header with offset, flags and size
+  tojson    convert values to JSON, one document per line
+  fromjson  convert JSON documents to values
+  stats     print depth, counts and largest values
+
+Input is read from file, or from stdin if file is absent or '-'.
+`
+
+// limits restricts the resources used for decoding each value.
+var limits = prefixed.Limits{MaxDepth: 64, MaxEntries: 1 << 20, MaxInput: 64 << 20}
+
+var commands = map[string]func(in io.Reader, out io.Writer) error{
+	"verify":   verify,
+	"dump":     dump,
+	"tojson":   toJSON,
+	"fromjson": fromJSON,
+	"stats":    stats,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command as specified by the arguments, returning the exit-code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) < 1 || len(args) > 2 {
+		io.WriteString(stderr, usage)
+		return 2
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		io.WriteString(stderr, "Unknown command: "+args[0]+"\n\n"+usage)
+		return 2
+	}
+	var in = stdin
+	if len(args) == 2 && args[1] != "-" {
+		f, err := os.Open(args[1])
+		if err != nil {
+			io.WriteString(stderr, "Failed to open input: "+err.Error()+"\n")
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+	var out = bufio.NewWriter(stdout)
+	var err = command(bufio.NewReader(in), out)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		io.WriteString(stderr, args[0]+": "+err.Error()+"\n")
+		return 1
+	}
+	return 0
+}
+
+func verify(in io.Reader, out io.Writer) error {
+	// Verification does not decode values in memory, therefore only the nesting depth is limited.
+	if err := (prefixed.Limits{MaxDepth: limits.MaxDepth}).Verify(in); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "ok\n")
+	return err
+}
+
+func toJSON(in io.Reader, out io.Writer) error {
+	for {
+		v, err := limits.ReadValue(in)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		var doc []byte
+		if doc, err = prefixed.ToJSON(v); err != nil {
+			return err
+		}
+		if _, err = out.Write(append(doc, '\n')); err != nil {
+			return err
+		}
+	}
+}
+
+func fromJSON(in io.Reader, out io.Writer) error {
+	var decoder = json.NewDecoder(in)
+	for {
+		var doc json.RawMessage
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Context(errors.ErrIllegal, "invalid JSON: "+err.Error())
+		}
+		var v prefixed.Value
+		if v, err = prefixed.FromJSON(doc); err != nil {
+			return err
+		}
+		if _, err = v.WriteTo(out); err != nil {
+			return err
+		}
+	}
+}
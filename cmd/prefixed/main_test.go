@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+const document = `{"name": "hello", "tags": ["a", h'00ff'], "kv": "k": "v"}`
+
+func encode(t testing.TB, text string) []byte {
+	v, err := prefixed.Parse(text)
+	assert.Nil(t, err)
+	var b bytes.Buffer
+	_, err = v.WriteTo(&b)
+	assert.Nil(t, err)
+	return b.Bytes()
+}
+
+func execute(args []string, input []byte) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	var code = run(args, bytes.NewReader(input), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunUsage(t *testing.T) {
+	code, stdout, stderr := execute(nil, nil)
+	assert.Equal(t, 2, code)
+	assert.Equal(t, "", stdout)
+	assert.True(t, strings.HasPrefix(stderr, "Usage:"))
+	code, _, stderr = execute([]string{"verify", "a", "b"}, nil)
+	assert.Equal(t, 2, code)
+	assert.True(t, strings.HasPrefix(stderr, "Usage:"))
+	code, _, stderr = execute([]string{"bogus"}, nil)
+	assert.Equal(t, 2, code)
+	assert.True(t, strings.HasPrefix(stderr, "Unknown command: bogus"))
+}
+
+func TestRunFile(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "input.bin")
+	assert.Nil(t, os.WriteFile(path, encode(t, document), 0o600))
+	code, stdout, _ := execute([]string{"verify", path}, nil)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "ok\n", stdout)
+	code, _, stderr := execute([]string{"verify", filepath.Join(t.TempDir(), "missing.bin")}, nil)
+	assert.Equal(t, 1, code)
+	assert.True(t, strings.HasPrefix(stderr, "Failed to open input:"))
+}
+
+func TestVerify(t *testing.T) {
+	var data = encode(t, document)
+	code, stdout, stderr := execute([]string{"verify", "-"}, append(data, data...))
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "ok\n", stdout)
+	assert.Equal(t, "", stderr)
+	code, stdout, stderr = execute([]string{"verify"}, data[:len(data)-1])
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "", stdout)
+	assert.Equal(t, "verify: unexpected EOF\n", stderr)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var data = append(encode(t, document), encode(t, `["x", h'ff']`)...)
+	code, stdout, _ := execute([]string{"tojson"}, data)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `{"u:kv":{"kv":{"u:k":"u:v"}},"u:name":"u:hello","u:tags":["u:a","b:AP8="]}`+"\n"+
+		`["u:x","b:/w=="]`+"\n", stdout)
+	code, stdout, _ = execute([]string{"fromjson"}, []byte(stdout))
+	assert.Equal(t, 0, code)
+	assert.SlicesEqual(t, data, []byte(stdout))
+}
+
+func TestLimits(t *testing.T) {
+	var nested = strings.Repeat("[", 65) + `"x"` + strings.Repeat("]", 65)
+	for _, command := range []string{"verify", "dump", "tojson", "stats"} {
+		t.Log("Command:", command)
+		code, _, _ := execute([]string{command}, encode(t, nested[1:len(nested)-1]))
+		assert.Equal(t, 0, code)
+		code, _, stderr := execute([]string{command}, encode(t, nested))
+		assert.Equal(t, 1, code)
+		assert.True(t, strings.Contains(stderr, prefixed.ErrLimitExceeded.Error()))
+	}
+}
+
+func TestFromJSONInvalid(t *testing.T) {
+	code, _, stderr := execute([]string{"fromjson"}, []byte(`["u:x"] {`))
+	assert.Equal(t, 1, code)
+	assert.True(t, strings.HasPrefix(stderr, "fromjson: invalid JSON"))
+	code, _, stderr = execute([]string{"fromjson"}, []byte(`[1]`))
+	assert.Equal(t, 1, code)
+	assert.True(t, strings.HasPrefix(stderr, "fromjson: "))
+}
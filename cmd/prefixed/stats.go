@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+)
+
+// statistics are gathered per composite type. The size is the number of bytes for bytes-values, the size
+// of the key for key-value-pairs and the number of entries for sequences and maps.
+type statistics struct {
+	values   int
+	maxDepth int
+	count    [4]int
+	total    [4]int
+	largest  [4]int
+	location [4]string
+}
+
+// stats prints statistics on the stream of values. The location of the largest values is indicated with
+// the index of the value in the stream, followed by the path in the notation of `prefixed.Select`.
+func stats(in io.Reader, out io.Writer) error {
+	var s statistics
+	for {
+		v, err := limits.ReadValue(in)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		s.value("#"+strconv.Itoa(s.values), 0, v)
+		s.values++
+	}
+	if _, err := fmt.Fprintf(out, "values:     %d\nmax depth:  %d\n", s.values, s.maxDepth); err != nil {
+		return err
+	}
+	var units = [4]string{"bytes", "bytes (key)", "entries", "entries"}
+	for t := prefixed.TYPE_BYTES; t <= prefixed.TYPE_MAP; t++ {
+		if _, err := fmt.Fprintf(out, "%-11s %d", t.String()+":", s.count[t]); err != nil {
+			return err
+		}
+		if s.count[t] > 0 {
+			if _, err := fmt.Fprintf(out, ", %d %s in total, largest %d at %s", s.total[t], units[t],
+				s.largest[t], s.location[t]); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(out, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value gathers statistics on the value, at `location` and nesting depth `depth`.
+func (s *statistics) value(location string, depth int, v prefixed.Value) {
+	var t prefixed.CompositeType
+	var size int
+	switch v := v.(type) {
+	case prefixed.Bytes:
+		t, size = prefixed.TYPE_BYTES, len(v)
+	case *prefixed.KeyValue:
+		t, size = prefixed.TYPE_KEYVALUE, len(v.K)
+		s.value(location+keySegment(v.K), depth+1, v.V)
+	case prefixed.SequenceValue:
+		t, size = prefixed.TYPE_SEQUENCE, len(v)
+		for i, e := range v {
+			s.value(location+"["+strconv.Itoa(i)+"]", depth+1, e)
+		}
+	case prefixed.MapValue:
+		t, size = prefixed.TYPE_MAP, len(v)
+		var keys = make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		// Visit entries in order of keys, such that the reported location is deterministic.
+		sort.Strings(keys)
+		for _, k := range keys {
+			s.value(location+keySegment(k), depth+1, v[k])
+		}
+	default:
+		panic("BUG: unsupported value-type")
+	}
+	s.maxDepth = max(s.maxDepth, depth)
+	s.count[t]++
+	s.total[t] += size
+	if s.count[t] == 1 || size > s.largest[t] {
+		s.largest[t], s.location[t] = size, location
+	}
+}
+
+func keySegment(key string) string {
+	return "[" + strconv.Quote(key) + "]"
+}
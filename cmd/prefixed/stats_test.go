@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package main
+
+import (
+	"testing"
+
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestStats(t *testing.T) {
+	var data = append(encode(t, document), encode(t, `["x", ["y", "zzzzzz"]]`)...)
+	code, stdout, stderr := execute([]string{"stats"}, data)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "", stderr)
+	assert.Equal(t, `values:     2
+max depth:  2
+bytes:      7, 17 bytes in total, largest 6 at #1[1][1]
+key-value:  1, 1 bytes (key) in total, largest 1 at #0["kv"]
+sequence:   3, 6 entries in total, largest 2 at #0["tags"]
+map:        1, 3 entries in total, largest 3 at #0
+`, stdout)
+}
+
+func TestStatsEmpty(t *testing.T) {
+	code, stdout, _ := execute([]string{"stats"}, nil)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "values:     0\nmax depth:  0\nbytes:      0\nkey-value:  0\nsequence:   0\nmap:        0\n",
+		stdout)
+}
+
+func TestStatsMalformed(t *testing.T) {
+	var data = encode(t, document)
+	code, _, stderr := execute([]string{"stats"}, data[:len(data)-1])
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "stats: unexpected EOF\n", stderr)
+}
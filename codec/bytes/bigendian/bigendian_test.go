@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bigendian
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestSigned(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, math.MinInt8, math.MaxInt8, math.MinInt16, math.MaxInt16, math.MinInt32,
+		math.MaxInt32, math.MinInt64, math.MaxInt64} {
+		var b bytes.Buffer
+		assert.Nil(t, WriteInt8(&b, int8(v)))
+		assert.Nil(t, WriteInt16(&b, int16(v)))
+		assert.Nil(t, WriteInt32(&b, int32(v)))
+		assert.Nil(t, WriteInt64(&b, v))
+		var expected = binary.BigEndian.AppendUint16([]byte{byte(v)}, uint16(v))
+		expected = binary.BigEndian.AppendUint32(expected, uint32(v))
+		expected = binary.BigEndian.AppendUint64(expected, uint64(v))
+		assert.SlicesEqual(t, expected, b.Bytes())
+		assert.Equal(t, int8(v), builtin.Expect(ReadInt8(&b)))
+		assert.Equal(t, int16(v), builtin.Expect(ReadInt16(&b)))
+		assert.Equal(t, int32(v), builtin.Expect(ReadInt32(&b)))
+		assert.Equal(t, v, builtin.Expect(ReadInt64(&b)))
+		var in = bytes.NewReader(expected)
+		assert.Equal(t, int8(v), MustReadInt8(in))
+		assert.Equal(t, int16(v), MustReadInt16(in))
+		assert.Equal(t, int32(v), MustReadInt32(in))
+		assert.Equal(t, v, MustReadInt64(in))
+		var b16, b32, b64 = FromInt16(int16(v)), FromInt32(int32(v)), FromInt64(v)
+		assert.SlicesEqual(t, expected[1:3], b16[:])
+		assert.SlicesEqual(t, expected[3:7], b32[:])
+		assert.SlicesEqual(t, expected[7:], b64[:])
+		assert.Equal(t, int16(v), ToInt16(b16[0], b16[1]))
+		assert.Equal(t, int32(v), ToInt32(b32[0], b32[1], b32[2], b32[3]))
+		assert.Equal(t, v, ToInt64(b64[0], b64[1], b64[2], b64[3], b64[4], b64[5], b64[6], b64[7]))
+	}
+}
+
+func TestFloat(t *testing.T) {
+	for _, v := range []float64{0, math.Copysign(0, -1), 1, -1.5, math.Pi, math.MaxFloat32,
+		math.SmallestNonzeroFloat64, math.Inf(1), math.Inf(-1)} {
+		var b bytes.Buffer
+		assert.Nil(t, WriteFloat32(&b, float32(v)))
+		assert.Nil(t, WriteFloat64(&b, v))
+		var expected = binary.BigEndian.AppendUint32(nil, math.Float32bits(float32(v)))
+		expected = binary.BigEndian.AppendUint64(expected, math.Float64bits(v))
+		assert.SlicesEqual(t, expected, b.Bytes())
+		assert.Equal(t, math.Float32bits(float32(v)), math.Float32bits(builtin.Expect(ReadFloat32(&b))))
+		assert.Equal(t, math.Float64bits(v), math.Float64bits(builtin.Expect(ReadFloat64(&b))))
+		var in = bytes.NewReader(expected)
+		assert.Equal(t, float32(v), MustReadFloat32(in))
+		assert.Equal(t, v, MustReadFloat64(in))
+		var b32, b64 = FromFloat32(float32(v)), FromFloat64(v)
+		assert.SlicesEqual(t, expected[:4], b32[:])
+		assert.SlicesEqual(t, expected[4:], b64[:])
+		assert.Equal(t, float32(v), ToFloat32(b32[0], b32[1], b32[2], b32[3]))
+		assert.Equal(t, v, ToFloat64(b64[0], b64[1], b64[2], b64[3], b64[4], b64[5], b64[6], b64[7]))
+	}
+	var nan = FromFloat64(math.NaN())
+	assert.True(t, math.IsNaN(ToFloat64(nan[0], nan[1], nan[2], nan[3], nan[4], nan[5], nan[6], nan[7])))
+}
+
+func TestUint24(t *testing.T) {
+	for _, v := range []uint32{0, 1, 0xff, 0x123456, MaxUint24} {
+		var b bytes.Buffer
+		assert.Nil(t, WriteUint24(&b, v))
+		var expected = binary.BigEndian.AppendUint32(nil, v)
+		expected = expected[1:]
+		assert.SlicesEqual(t, expected, b.Bytes())
+		assert.Equal(t, v, builtin.Expect(ReadUint24(bytes.NewReader(expected))))
+		assert.Equal(t, v, MustReadUint24(bytes.NewReader(expected)))
+		var encoded = FromUint24(v)
+		assert.SlicesEqual(t, expected, encoded[:])
+		assert.Equal(t, v, ToUint24(encoded[0], encoded[1], encoded[2]))
+	}
+	assert.IsError(t, errors.ErrOverflow, WriteUint24(io.Discard, MaxUint24+1))
+	_, err := ReadUint24(bytes.NewReader([]byte{1, 2}))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestUint48(t *testing.T) {
+	for _, v := range []uint64{0, 1, 0xff, 0x123456789abc, MaxUint48} {
+		var b bytes.Buffer
+		assert.Nil(t, WriteUint48(&b, v))
+		var expected = binary.BigEndian.AppendUint64(nil, v)
+		expected = expected[2:]
+		assert.SlicesEqual(t, expected, b.Bytes())
+		assert.Equal(t, v, builtin.Expect(ReadUint48(bytes.NewReader(expected))))
+		assert.Equal(t, v, MustReadUint48(bytes.NewReader(expected)))
+		var encoded = FromUint48(v)
+		assert.SlicesEqual(t, expected, encoded[:])
+		assert.Equal(t, v, ToUint48(encoded[0], encoded[1], encoded[2], encoded[3], encoded[4], encoded[5]))
+	}
+	assert.IsError(t, errors.ErrOverflow, WriteUint48(io.Discard, MaxUint48+1))
+	_, err := ReadUint48(bytes.NewReader([]byte{1, 2, 3, 4, 5}))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestFromUint24Overflow(t *testing.T) {
+	defer assert.RequirePanic(t)
+	FromUint24(MaxUint24 + 1)
+	t.FailNow()
+}
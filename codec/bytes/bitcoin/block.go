@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// BlockHeader is the 80-byte block header.
+type BlockHeader struct {
+	Version    int32
+	PrevBlock  Hash
+	MerkleRoot Hash
+	Timestamp  uint32
+	Bits       uint32
+	Nonce      uint32
+}
+
+// Decode reads the block header.
+func (h *BlockHeader) Decode(r *Reader) {
+	var start = r.n
+	defer r.unexpectedEOF(start)
+	h.Version = r.Int32()
+	h.PrevBlock = r.Hash()
+	h.MerkleRoot = r.Hash()
+	h.Timestamp = r.Uint32()
+	h.Bits = r.Uint32()
+	h.Nonce = r.Uint32()
+}
+
+// Encode writes the block header.
+func (h *BlockHeader) Encode(w *Writer) {
+	w.Int32(h.Version)
+	w.Hash(h.PrevBlock)
+	w.Hash(h.MerkleRoot)
+	w.Uint32(h.Timestamp)
+	w.Uint32(h.Bits)
+	w.Uint32(h.Nonce)
+}
+
+// Hash computes the block-hash.
+func (h *BlockHeader) Hash() Hash {
+	var b bytes.Buffer
+	h.Encode(NewWriter(&b))
+	return DoubleSHA256(b.Bytes())
+}
+
+// Block is a block header followed by its transactions.
+type Block struct {
+	Header       BlockHeader
+	Transactions []*Tx
+}
+
+// ParseBlock parses a block that spans all of `data`.
+func ParseBlock(data []byte) (*Block, error) {
+	var in = bytes.NewReader(data)
+	var r = NewReader(in)
+	var block Block
+	block.Decode(r)
+	if err := r.Err(); err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	if in.Len() > 0 {
+		return nil, errors.Context(errors.ErrIllegal, "trailing data after block")
+	}
+	return &block, nil
+}
+
+// Decode reads the block.
+func (b *Block) Decode(r *Reader) {
+	var start = r.n
+	defer r.unexpectedEOF(start)
+	b.Header.Decode(r)
+	b.Transactions = ReadArray(r, func(r *Reader) *Tx {
+		var tx Tx
+		tx.Decode(r)
+		return &tx
+	})
+}
+
+// Encode writes the block.
+func (b *Block) Encode(w *Writer) {
+	b.Header.Encode(w)
+	WriteArray(w, b.Transactions, func(w *Writer, tx *Tx) { tx.Encode(w) })
+}
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+const genesisHeader = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3" +
+	"edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+
+func TestParseBlock(t *testing.T) {
+	var data = builtin.Expect(hex.DecodeString(genesisHeader + "01" + genesisCoinbase))
+	block, err := ParseBlock(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+		block.Header.Hash().String())
+	assert.Equal(t, uint32(1231006505), block.Header.Timestamp)
+	assert.Equal(t, uint32(0x1d00ffff), block.Header.Bits)
+	assert.Equal(t, 1, len(block.Transactions))
+	// the merkle-root of a single transaction is its transaction-id
+	assert.Equal(t, block.Transactions[0].TxID(), block.Header.MerkleRoot)
+	var b bytes.Buffer
+	var w = NewWriter(&b)
+	block.Encode(w)
+	assert.Nil(t, w.Err())
+	assert.SlicesEqual(t, data, b.Bytes())
+	_, err = ParseBlock(data[:100])
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+}
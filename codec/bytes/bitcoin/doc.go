@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Bitcoin serialization of (raw) transactions, blocks and their fields, as used on the wire and in storage.
+//
+// Fields are little-endian fixed-width integers, compactsize-encoded lengths and counts, var-length byte
+// vectors (compactsize length, followed by bytes), var-length arrays (compactsize count, followed by
+// elements) and 32-byte hashes. `Reader` and `Writer` keep the first error that occurs (sticky error), such
+// that a full structure can be processed and errors checked once afterwards.
+//
+// Decoding is strict, as in Bitcoin Core: compactsize-encodings must be canonical and lengths must not
+// exceed `MaxSize`. Consequently, decoded structures re-serialize byte-for-byte.
+//
+// ref: <https://developer.bitcoin.org/reference/transactions.html>
+// ref: <https://github.com/bitcoin/bips/blob/master/bip-0144.mediawiki>
+package bitcoin
+
+// MaxSize is the maximum length of byte vectors and maximum count of array-elements, as enforced by Bitcoin
+// Core. (`MAX_SIZE`)
+const MaxSize = 0x02000000
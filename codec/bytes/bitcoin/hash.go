@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// Hash is a 32-byte hash, such as a transaction-id or block-hash, in internal byte order, i.e. as
+// serialized. Hashes are conventionally displayed in reversed byte order.
+type Hash [32]byte
+
+// DoubleSHA256 computes `SHA256(SHA256(data))`.
+func DoubleSHA256(data []byte) Hash {
+	var first = sha256.Sum256(data)
+	return sha256.Sum256(first[:])
+}
+
+// ParseHash parses a hash in display notation, i.e. hexadecimal in reversed byte order.
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	if hex.DecodedLen(len(s)) != len(h) {
+		return Hash{}, errors.Context(errors.ErrIllegal, "expected 64 hexadecimal characters")
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return Hash{}, errors.Context(errors.ErrIllegal, "invalid hexadecimal notation: "+err.Error())
+	}
+	h.reverse()
+	return h, nil
+}
+
+// String returns the display notation, i.e. hexadecimal in reversed byte order.
+func (h Hash) String() string {
+	h.reverse()
+	return hex.EncodeToString(h[:])
+}
+
+func (h *Hash) reverse() {
+	for i, j := 0, len(h)-1; i < j; i, j = i+1, j-1 {
+		h[i], h[j] = h[j], h[i]
+	}
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestHash(t *testing.T) {
+	const display = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	h, err := ParseHash(display)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(0x6f), h[0])
+	assert.Equal(t, byte(0x00), h[31])
+	assert.Equal(t, display, h.String())
+	_, err = ParseHash(display[2:])
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = ParseHash("zz" + display[2:])
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestDoubleSHA256(t *testing.T) {
+	assert.Equal(t, "56944c5d3f98413ef45cf54545538103cc9f298e0575820ad3591376e2e0f65d",
+		DoubleSHA256(nil).String())
+}
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"io"
+
+	"github.com/cobratbq/goutils/codec/bytes/compactsize"
+	"github.com/cobratbq/goutils/codec/bytes/littleendian"
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+// Reader reads Bitcoin-serialized fields. The first error is retained: once an error occurred, subsequent
+// reads return zero-values without reading. Check `Err` after reading a full structure.
+type Reader struct {
+	in  io.Reader
+	err error
+	// n is the number of bytes read.
+	n int64
+}
+
+// NewReader creates a reader that reads from `in`.
+func NewReader(in io.Reader) *Reader {
+	return &Reader{in: in}
+}
+
+// Err returns the first error that occurred.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Read reads into p, such that Reader can be used as `io.Reader`, e.g. to read nested structures.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.in.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// fail retains the error, if it is the first error.
+func (r *Reader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// unexpectedEOF converts `io.EOF` into `io.ErrUnexpectedEOF` if bytes were read since `start`, i.e. the
+// end of input was reached in the middle of a structure.
+func (r *Reader) unexpectedEOF(start int64) {
+	if r.err == io.EOF && r.n > start {
+		r.err = io.ErrUnexpectedEOF
+	}
+}
+
+func (r *Reader) Uint8() uint8 {
+	var b [1]byte
+	r.ReadFull(b[:])
+	return b[0]
+}
+
+func (r *Reader) Uint16() uint16 {
+	if r.err != nil {
+		return 0
+	}
+	value, err := littleendian.ReadUint16(r)
+	r.fail(err)
+	return value
+}
+
+func (r *Reader) Uint32() uint32 {
+	if r.err != nil {
+		return 0
+	}
+	value, err := littleendian.ReadUint32(r)
+	r.fail(err)
+	return value
+}
+
+func (r *Reader) Int32() int32 {
+	return int32(r.Uint32())
+}
+
+func (r *Reader) Uint64() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	value, err := littleendian.ReadUint64(r)
+	r.fail(err)
+	return value
+}
+
+func (r *Reader) Int64() int64 {
+	return int64(r.Uint64())
+}
+
+// CompactSize reads a compactsize-encoded value. The encoding must be canonical.
+func (r *Reader) CompactSize() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	value, err := compactsize.ReadStrictUint64(r)
+	r.fail(err)
+	return value
+}
+
+// Length reads a compactsize-encoded length or count, that must not exceed `MaxSize`.
+func (r *Reader) Length() int {
+	var length = r.CompactSize()
+	if length > MaxSize {
+		r.fail(errors.Context(errors.ErrOverflow, "length "+strconv.FormatUintDecimal(length)+" exceeds maximum"))
+		return 0
+	}
+	return int(length)
+}
+
+// ReadFull reads exactly `len(dst)` bytes into `dst`.
+func (r *Reader) ReadFull(dst []byte) {
+	if r.err != nil {
+		return
+	}
+	_, err := io.ReadFull(r, dst)
+	r.fail(err)
+}
+
+// Bytes reads a fixed number of bytes.
+func (r *Reader) Bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	// Memory is allocated as data is read, such that a bogus length cannot cause excessive allocation.
+	data, err := io.ReadAll(io.LimitReader(r, int64(n)))
+	if err != nil {
+		r.fail(err)
+		return nil
+	}
+	if len(data) < n {
+		r.fail(io.ErrUnexpectedEOF)
+		return nil
+	}
+	return data
+}
+
+// VarBytes reads a var-length byte vector.
+func (r *Reader) VarBytes() []byte {
+	var n = r.Length()
+	if r.err != nil {
+		return nil
+	}
+	if n == 0 {
+		return []byte{}
+	}
+	return r.Bytes(n)
+}
+
+// Hash reads a hash, in internal byte order.
+func (r *Reader) Hash() Hash {
+	var h Hash
+	r.ReadFull(h[:])
+	return h
+}
+
+// ReadArray reads a var-length array, using `read` to read each element.
+func ReadArray[T any](r *Reader, read func(r *Reader) T) []T {
+	var n = r.Length()
+	if r.err != nil {
+		return nil
+	}
+	// Capacity is bounded, such that a bogus count cannot cause excessive allocation.
+	var elements = make([]T, 0, min(n, 1024))
+	for i := 0; i < n && r.err == nil; i++ {
+		elements = append(elements, read(r))
+	}
+	if r.err != nil {
+		return nil
+	}
+	return elements
+}
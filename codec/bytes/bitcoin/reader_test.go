@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/cobratbq/goutils/codec/bytes/compactsize"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestReader(t *testing.T) {
+	var r = NewReader(bytes.NewReader([]byte{0x01, 0x02, 0x01, 0xff, 0xff, 0xff, 0xff, 0xfe, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0x03, 'a', 'b', 'c', 0x00, 0x02, 0x01, 0x00, 0x02, 0x00, 0xfd, 0xfd, 0x00}))
+	assert.Equal(t, uint8(1), r.Uint8())
+	assert.Equal(t, uint16(0x0102), r.Uint16())
+	assert.Equal(t, int32(-1), r.Int32())
+	assert.Equal(t, int64(-2), r.Int64())
+	assert.SlicesEqual(t, []byte("abc"), r.VarBytes())
+	assert.SlicesEqual(t, []byte{}, r.VarBytes())
+	assert.SlicesEqual(t, []uint16{1, 2}, ReadArray(r, (*Reader).Uint16))
+	assert.Equal(t, uint64(0xfd), r.CompactSize())
+	assert.Nil(t, r.Err())
+	r.Uint8()
+	assert.IsError(t, io.EOF, r.Err())
+}
+
+func TestReaderStickyError(t *testing.T) {
+	var r = NewReader(bytes.NewReader([]byte{0xfd, 0x01, 0x00, 0x01, 0x02}))
+	// non-canonical encoding of length
+	assert.Equal(t, 0, r.Length())
+	assert.IsError(t, compactsize.ErrNonCanonical, r.Err())
+	// subsequent reads do not consume input
+	assert.Equal(t, uint8(0), r.Uint8())
+	assert.True(t, r.VarBytes() == nil)
+	assert.IsError(t, compactsize.ErrNonCanonical, r.Err())
+}
+
+func TestReaderLimits(t *testing.T) {
+	var r = NewReader(bytes.NewReader(compactsize.EncodeUint64(MaxSize + 1)))
+	assert.True(t, r.VarBytes() == nil)
+	assert.IsError(t, errors.ErrOverflow, r.Err())
+	// a large length without data does not allocate the full length upfront
+	r = NewReader(bytes.NewReader(compactsize.EncodeUint64(MaxSize)))
+	assert.True(t, r.VarBytes() == nil)
+	assert.IsError(t, io.ErrUnexpectedEOF, r.Err())
+	r = NewReader(bytes.NewReader(compactsize.EncodeUint64(MaxSize)))
+	assert.True(t, ReadArray(r, (*Reader).Hash) == nil)
+	assert.IsError(t, io.EOF, r.Err())
+}
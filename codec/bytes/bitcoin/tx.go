@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// OutPoint references an output of a (previous) transaction.
+type OutPoint struct {
+	Hash  Hash
+	Index uint32
+}
+
+// TxIn is a transaction input.
+type TxIn struct {
+	PreviousOutput  OutPoint
+	SignatureScript []byte
+	Sequence        uint32
+	// Witness is the segregated witness (BIP-141) of the input, consisting of stack items.
+	Witness [][]byte
+}
+
+// TxOut is a transaction output.
+type TxOut struct {
+	// Value is the amount in satoshis.
+	Value    int64
+	PkScript []byte
+}
+
+// Tx is a transaction.
+type Tx struct {
+	Version  int32
+	Inputs   []TxIn
+	Outputs  []TxOut
+	LockTime uint32
+}
+
+// ParseTx parses a transaction that spans all of `data`.
+func ParseTx(data []byte) (*Tx, error) {
+	var in = bytes.NewReader(data)
+	var r = NewReader(in)
+	var tx Tx
+	tx.Decode(r)
+	if err := r.Err(); err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	if in.Len() > 0 {
+		return nil, errors.Context(errors.ErrIllegal, "trailing data after transaction")
+	}
+	return &tx, nil
+}
+
+// Decode reads the transaction, in either legacy or extended (segwit) serialization. As in Bitcoin Core, a
+// witness-flag without any witness data is illegal. Returns `io.EOF` through `r.Err()` only if the input
+// ends before the transaction.
+func (tx *Tx) Decode(r *Reader) {
+	var start = r.n
+	defer r.unexpectedEOF(start)
+	tx.Version = r.Int32()
+	tx.Inputs = ReadArray(r, readTxIn)
+	var flags uint8
+	if len(tx.Inputs) == 0 && r.err == nil {
+		// Extended serialization: marker (empty inputs) followed by flags.
+		flags = r.Uint8()
+		if flags != 0 {
+			tx.Inputs = ReadArray(r, readTxIn)
+			tx.Outputs = ReadArray(r, readTxOut)
+		}
+	} else {
+		tx.Outputs = ReadArray(r, readTxOut)
+	}
+	if flags&1 != 0 {
+		flags ^= 1
+		for i := range tx.Inputs {
+			tx.Inputs[i].Witness = ReadArray(r, (*Reader).VarBytes)
+		}
+		if r.err == nil && !tx.HasWitness() {
+			r.fail(errors.Context(errors.ErrIllegal, "superfluous witness record"))
+		}
+	}
+	if flags != 0 && r.err == nil {
+		r.fail(errors.Context(errors.ErrIllegal, "unknown transaction optional data"))
+	}
+	tx.LockTime = r.Uint32()
+}
+
+func readTxIn(r *Reader) TxIn {
+	return TxIn{
+		PreviousOutput:  OutPoint{Hash: r.Hash(), Index: r.Uint32()},
+		SignatureScript: r.VarBytes(),
+		Sequence:        r.Uint32(),
+	}
+}
+
+func readTxOut(r *Reader) TxOut {
+	return TxOut{Value: r.Int64(), PkScript: r.VarBytes()}
+}
+
+// Encode writes the transaction, in extended (segwit) serialization if any input has witness data, or in
+// legacy serialization otherwise.
+func (tx *Tx) Encode(w *Writer) {
+	tx.encode(w, tx.HasWitness())
+}
+
+func (tx *Tx) encode(w *Writer, witness bool) {
+	w.Int32(tx.Version)
+	if witness {
+		w.Uint8(0)
+		w.Uint8(1)
+	}
+	WriteArray(w, tx.Inputs, writeTxIn)
+	WriteArray(w, tx.Outputs, writeTxOut)
+	if witness {
+		for _, in := range tx.Inputs {
+			WriteArray(w, in.Witness, (*Writer).VarBytes)
+		}
+	}
+	w.Uint32(tx.LockTime)
+}
+
+func writeTxIn(w *Writer, in TxIn) {
+	w.Hash(in.PreviousOutput.Hash)
+	w.Uint32(in.PreviousOutput.Index)
+	w.VarBytes(in.SignatureScript)
+	w.Uint32(in.Sequence)
+}
+
+func writeTxOut(w *Writer, out TxOut) {
+	w.Int64(out.Value)
+	w.VarBytes(out.PkScript)
+}
+
+// Bytes returns the serialized transaction. See `Encode`.
+func (tx *Tx) Bytes() []byte {
+	var b bytes.Buffer
+	tx.Encode(NewWriter(&b))
+	return b.Bytes()
+}
+
+// HasWitness checks whether any input has witness data.
+func (tx *Tx) HasWitness() bool {
+	for _, in := range tx.Inputs {
+		if len(in.Witness) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// TxID computes the transaction-id, i.e. the hash of the legacy serialization.
+func (tx *Tx) TxID() Hash {
+	var b bytes.Buffer
+	tx.encode(NewWriter(&b), false)
+	return DoubleSHA256(b.Bytes())
+}
+
+// WTxID computes the witness transaction-id, i.e. the hash of the extended serialization. For transactions
+// without witness data, the witness transaction-id equals the transaction-id.
+func (tx *Tx) WTxID() Hash {
+	return DoubleSHA256(tx.Bytes())
+}
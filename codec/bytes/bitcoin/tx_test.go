@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+// genesisCoinbase is the coinbase transaction of the genesis block.
+const genesisCoinbase = "01000000010000000000000000000000000000000000000000000000000000000000000000" +
+	"ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e2062" +
+	"72696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a0100000043410467" +
+	"8afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c38" +
+	"4df7ba0b8d578a4c702b6bf11d5fac00000000"
+
+func TestParseTxLegacy(t *testing.T) {
+	var data = builtin.Expect(hex.DecodeString(genesisCoinbase))
+	tx, err := ParseTx(data)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), tx.Version)
+	assert.Equal(t, 1, len(tx.Inputs))
+	assert.Equal(t, Hash{}, tx.Inputs[0].PreviousOutput.Hash)
+	assert.Equal(t, uint32(0xffffffff), tx.Inputs[0].PreviousOutput.Index)
+	assert.Equal(t, 77, len(tx.Inputs[0].SignatureScript))
+	assert.Equal(t, 1, len(tx.Outputs))
+	assert.Equal(t, int64(5000000000), tx.Outputs[0].Value)
+	assert.Equal(t, uint32(0), tx.LockTime)
+	assert.False(t, tx.HasWitness())
+	assert.Equal(t, "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b", tx.TxID().String())
+	assert.Equal(t, tx.TxID(), tx.WTxID())
+	assert.SlicesEqual(t, data, tx.Bytes())
+}
+
+func segwitTx() *Tx {
+	return &Tx{
+		Version: 2,
+		Inputs: []TxIn{
+			{PreviousOutput: OutPoint{Hash: Hash{1, 2, 3}, Index: 1}, Sequence: 0xfffffffd,
+				Witness: [][]byte{bytes.Repeat([]byte{0x30}, 71), bytes.Repeat([]byte{0x02}, 33)}},
+			{PreviousOutput: OutPoint{Hash: Hash{4}, Index: 0}, SignatureScript: []byte{0x51},
+				Sequence: 0xffffffff, Witness: [][]byte{}},
+		},
+		Outputs: []TxOut{
+			{Value: 1000, PkScript: append([]byte{0x00, 0x14}, bytes.Repeat([]byte{0xaa}, 20)...)},
+			{Value: 0, PkScript: []byte{0x6a}},
+		},
+		LockTime: 800000,
+	}
+}
+
+func TestSegwitRoundTrip(t *testing.T) {
+	var tx = segwitTx()
+	assert.True(t, tx.HasWitness())
+	var data = tx.Bytes()
+	// marker and flag follow the version
+	assert.SlicesEqual(t, []byte{2, 0, 0, 0, 0, 1, 2}, data[:7])
+	parsed, err := ParseTx(data)
+	assert.Nil(t, err)
+	assert.SlicesEqual(t, data, parsed.Bytes())
+	assert.Equal(t, tx.TxID(), parsed.TxID())
+	assert.Equal(t, tx.WTxID(), parsed.WTxID())
+	assert.Unequal(t, tx.TxID(), tx.WTxID())
+	assert.Equal(t, 2, len(parsed.Inputs[0].Witness))
+	assert.SlicesEqual(t, tx.Inputs[0].Witness[1], parsed.Inputs[0].Witness[1])
+	assert.Equal(t, 0, len(parsed.Inputs[1].Witness))
+	// the transaction-id does not cover witness data
+	var stripped = segwitTx()
+	stripped.Inputs[0].Witness = nil
+	assert.Equal(t, tx.TxID(), stripped.TxID())
+	assert.Equal(t, stripped.TxID(), stripped.WTxID())
+}
+
+func TestParseTxErrors(t *testing.T) {
+	var data = segwitTx().Bytes()
+	for i := 0; i < len(data); i++ {
+		_, err := ParseTx(data[:i])
+		assert.IsError(t, io.ErrUnexpectedEOF, err)
+	}
+	_, err := ParseTx(append(data, 0))
+	assert.IsError(t, errors.ErrIllegal, err)
+	// witness-flag without witness data
+	var tx = segwitTx()
+	for i := range tx.Inputs {
+		tx.Inputs[i].Witness = nil
+	}
+	var legacy = tx.Bytes()
+	var superfluous = append(append(append([]byte{}, legacy[:4]...), 0, 1), legacy[4:len(legacy)-4]...)
+	superfluous = append(append(superfluous, 0, 0), legacy[len(legacy)-4:]...)
+	_, err = ParseTx(superfluous)
+	assert.IsError(t, errors.ErrIllegal, err)
+	// unknown flags
+	data[5] = 3
+	_, err = ParseTx(data)
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestDecodeTxStream(t *testing.T) {
+	var data = append(builtin.Expect(hex.DecodeString(genesisCoinbase)), segwitTx().Bytes()...)
+	var r = NewReader(bytes.NewReader(data))
+	var first, second, third Tx
+	first.Decode(r)
+	second.Decode(r)
+	assert.Nil(t, r.Err())
+	assert.Equal(t, segwitTx().WTxID(), second.WTxID())
+	third.Decode(r)
+	assert.IsError(t, io.EOF, r.Err())
+}
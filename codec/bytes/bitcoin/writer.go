@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"io"
+
+	"github.com/cobratbq/goutils/codec/bytes/compactsize"
+	"github.com/cobratbq/goutils/codec/bytes/littleendian"
+)
+
+// Writer writes Bitcoin-serialized fields. The first error is retained: once an error occurred, subsequent
+// writes are ignored. Check `Err` after writing a full structure.
+type Writer struct {
+	out io.Writer
+	err error
+}
+
+// NewWriter creates a writer that writes to `out`.
+func NewWriter(out io.Writer) *Writer {
+	return &Writer{out: out}
+}
+
+// Err returns the first error that occurred.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// Write writes p, such that Writer can be used as `io.Writer`.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.out.Write(p)
+	w.err = err
+	return n, err
+}
+
+func (w *Writer) Uint8(value uint8) {
+	w.Bytes([]byte{value})
+}
+
+func (w *Writer) Uint16(value uint16) {
+	var b = littleendian.FromUint16(value)
+	w.Bytes(b[:])
+}
+
+func (w *Writer) Uint32(value uint32) {
+	var b = littleendian.FromUint32(value)
+	w.Bytes(b[:])
+}
+
+func (w *Writer) Int32(value int32) {
+	w.Uint32(uint32(value))
+}
+
+func (w *Writer) Uint64(value uint64) {
+	var b = littleendian.FromUint64(value)
+	w.Bytes(b[:])
+}
+
+func (w *Writer) Int64(value int64) {
+	w.Uint64(uint64(value))
+}
+
+// CompactSize writes a compactsize-encoded value.
+func (w *Writer) CompactSize(value uint64) {
+	w.Bytes(compactsize.EncodeUint64(value))
+}
+
+// Bytes writes the bytes as-is.
+func (w *Writer) Bytes(data []byte) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.out.Write(data)
+}
+
+// VarBytes writes a var-length byte vector.
+func (w *Writer) VarBytes(data []byte) {
+	w.CompactSize(uint64(len(data)))
+	w.Bytes(data)
+}
+
+// Hash writes a hash, in internal byte order.
+func (w *Writer) Hash(h Hash) {
+	w.Bytes(h[:])
+}
+
+// WriteArray writes a var-length array, using `write` to write each element.
+func WriteArray[T any](w *Writer, elements []T, write func(w *Writer, e T)) {
+	w.CompactSize(uint64(len(elements)))
+	for _, e := range elements {
+		write(w, e)
+	}
+}
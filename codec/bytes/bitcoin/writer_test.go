@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bitcoin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestWriter(t *testing.T) {
+	var b bytes.Buffer
+	var w = NewWriter(&b)
+	w.Uint8(1)
+	w.Uint16(0x0102)
+	w.Int32(-1)
+	w.Int64(-2)
+	w.VarBytes([]byte("abc"))
+	w.VarBytes(nil)
+	WriteArray(w, []uint16{1, 2}, (*Writer).Uint16)
+	w.CompactSize(0xfd)
+	assert.Nil(t, w.Err())
+	assert.SlicesEqual(t, []byte{0x01, 0x02, 0x01, 0xff, 0xff, 0xff, 0xff, 0xfe, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0x03, 'a', 'b', 'c', 0x00, 0x02, 0x01, 0x00, 0x02, 0x00, 0xfd, 0xfd, 0x00}, b.Bytes())
+}
+
+type failingWriter struct {
+	calls int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	f.calls++
+	return 0, errors.ErrInternalState
+}
+
+func TestWriterStickyError(t *testing.T) {
+	var out failingWriter
+	var w = NewWriter(&out)
+	w.Uint32(1)
+	w.VarBytes([]byte("abc"))
+	w.Hash(Hash{})
+	assert.IsError(t, errors.ErrInternalState, w.Err())
+	assert.Equal(t, 1, out.calls)
+}
@@ -1,15 +1,20 @@
 // SPDX-License-Identifier: LGPL-3.0-only
 
 // TODO return uint8 as we are guaranteed to read at most 9 bytes for a decode. (previously: 'int' for read number of bytes instead of `uint`? Although, now we guarantee by data-type that the value is always non-negative.)
-// FIXME needs testing
 package compactsize
 
 import (
+	"io"
 	"math"
 
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
 	"github.com/cobratbq/goutils/types"
 )
 
+// ErrNonCanonical indicates that the value is encoded with a larger prefix than necessary.
+var ErrNonCanonical = errors.NewStringError("non-canonical encoding")
+
 // Decode next value in buffer into `uint8`.
 //
 // Returns value as uint8, and number of bytes read.
@@ -97,3 +102,78 @@ func DecodeUint(buffer []byte) (uint, uint) {
 	value, n := DecodeUint64(buffer)
 	return uint(value), n
 }
+
+// encodedSize returns the size of the encoding, as indicated by the first byte.
+func encodedSize(prefix byte) uint {
+	switch prefix {
+	case 0xfd:
+		return 3
+	case 0xfe:
+		return 5
+	case 0xff:
+		return 9
+	default:
+		return 1
+	}
+}
+
+// canonicalSize returns the size of the canonical encoding of value.
+func canonicalSize(value uint64) uint {
+	switch {
+	case value < 0xfd:
+		return 1
+	case value <= math.MaxUint16:
+		return 3
+	case value <= math.MaxUint32:
+		return 5
+	default:
+		return 9
+	}
+}
+
+// decodeStrict decodes the next value, rejecting non-canonical encodings and values larger than `max`.
+func decodeStrict(buffer []byte, max uint64) (uint64, uint, error) {
+	if len(buffer) < 1 || uint(len(buffer)) < encodedSize(buffer[0]) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	value, n := DecodeUint64(buffer)
+	if n != canonicalSize(value) {
+		return 0, 0, errors.Context(ErrNonCanonical, "value encoded with "+strconv.FormatUintDecimal(n)+
+			" bytes")
+	}
+	if value > max {
+		return 0, 0, errors.Context(errors.ErrOverflow, "value too large for data-type")
+	}
+	return value, n, nil
+}
+
+// DecodeStrictUint8 decodes the next value in buffer into `uint8`, as Bitcoin Core does. Returns the value
+// and the number of bytes read. Returns `io.ErrUnexpectedEOF` if the buffer is too short, `ErrNonCanonical`
+// if the value is not encoded in the smallest possible form, and `errors.ErrOverflow` if the value is too
+// big to fit in the data-type.
+func DecodeStrictUint8(buffer []byte) (uint8, uint, error) {
+	value, n, err := decodeStrict(buffer, math.MaxUint8)
+	return uint8(value), n, err
+}
+
+// DecodeStrictUint16 decodes the next value in buffer into `uint16`. See `DecodeStrictUint8`.
+func DecodeStrictUint16(buffer []byte) (uint16, uint, error) {
+	value, n, err := decodeStrict(buffer, math.MaxUint16)
+	return uint16(value), n, err
+}
+
+// DecodeStrictUint32 decodes the next value in buffer into `uint32`. See `DecodeStrictUint8`.
+func DecodeStrictUint32(buffer []byte) (uint32, uint, error) {
+	value, n, err := decodeStrict(buffer, math.MaxUint32)
+	return uint32(value), n, err
+}
+
+// DecodeStrictUint64 decodes the next value in buffer into `uint64`. See `DecodeStrictUint8`.
+func DecodeStrictUint64(buffer []byte) (uint64, uint, error) {
+	return decodeStrict(buffer, math.MaxUint64)
+}
+
+func DecodeStrictUint(buffer []byte) (uint, uint, error) {
+	value, n, err := decodeStrict(buffer, uint64(types.MaxUint))
+	return uint(value), n, err
+}
@@ -3,8 +3,10 @@
 package compactsize
 
 import (
+	"io"
 	"testing"
 
+	"github.com/cobratbq/goutils/std/errors"
 	assert "github.com/cobratbq/goutils/std/testing"
 )
 
@@ -173,3 +175,71 @@ func TestDecodeUint64(t *testing.T) {
 		assert.Equal(t, test.value, value)
 	}
 }
+
+func TestDecodeStrictUint64(t *testing.T) {
+	testdata := []struct {
+		input []byte
+		value uint64
+		n     uint
+		err   error
+	}{
+		{[]byte{}, 0, 0, io.ErrUnexpectedEOF},
+		{[]byte{0}, 0, 1, nil},
+		{[]byte{0xfc}, 0xfc, 1, nil},
+		{[]byte{0xfd}, 0, 0, io.ErrUnexpectedEOF},
+		{[]byte{0xfd, 0xfd}, 0, 0, io.ErrUnexpectedEOF},
+		{[]byte{0xfd, 0xfc, 0}, 0, 0, ErrNonCanonical},
+		{[]byte{0xfd, 0xfd, 0}, 0xfd, 3, nil},
+		{[]byte{0xfd, 0xff, 0xff}, 0xffff, 3, nil},
+		{[]byte{0xfe, 0xff, 0xff, 0, 0}, 0, 0, ErrNonCanonical},
+		{[]byte{0xfe, 0, 0, 1, 0}, 0x10000, 5, nil},
+		{[]byte{0xfe, 0, 0, 1}, 0, 0, io.ErrUnexpectedEOF},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}, 0, 0, ErrNonCanonical},
+		{[]byte{0xff, 0, 0, 0, 0, 1, 0, 0, 0}, 0x100000000, 9, nil},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xaa}, 0xffffffffffffffff, 9, nil},
+		{[]byte{0xff, 0, 0, 0, 0, 0, 0, 0, 0}, 0, 0, ErrNonCanonical},
+	}
+	for _, test := range testdata {
+		value, n, err := DecodeStrictUint64(test.input)
+		assert.IsError(t, test.err, err)
+		assert.Equal(t, test.n, n)
+		assert.Equal(t, test.value, value)
+	}
+}
+
+func TestDecodeStrictOverflow(t *testing.T) {
+	_, _, err := DecodeStrictUint8([]byte{0xfd, 0, 1})
+	assert.IsError(t, errors.ErrOverflow, err)
+	value8, n, err := DecodeStrictUint8([]byte{0xfd, 0xff, 0})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 0xff, value8)
+	_, _, err = DecodeStrictUint16([]byte{0xfe, 0, 0, 1, 0})
+	assert.IsError(t, errors.ErrOverflow, err)
+	value16, n, err := DecodeStrictUint16([]byte{0xfd, 0xff, 0xff})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 0xffff, value16)
+	_, _, err = DecodeStrictUint32([]byte{0xff, 0, 0, 0, 0, 1, 0, 0, 0})
+	assert.IsError(t, errors.ErrOverflow, err)
+	value32, n, err := DecodeStrictUint32([]byte{0xfe, 0xff, 0xff, 0xff, 0xff})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 0xffffffff, value32)
+	// non-canonical encoding takes precedence over overflow
+	_, _, err = DecodeStrictUint8([]byte{0xfe, 0, 1, 0, 0})
+	assert.IsError(t, ErrNonCanonical, err)
+}
+
+func TestDecodeStrictRoundTrip(t *testing.T) {
+	for _, v := range []uint64{0, 0xfc, 0xfd, 0xffff, 0x10000, 0xffffffff, 0x100000000, 0xffffffffffffffff} {
+		value, n, err := DecodeStrictUint64(EncodeUint64(v))
+		assert.Nil(t, err)
+		assert.Equal(t, uint(len(EncodeUint64(v))), n)
+		assert.Equal(t, v, value)
+	}
+	value, n, err := DecodeStrictUint(EncodeUint(0xfd))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 0xfd, value)
+}
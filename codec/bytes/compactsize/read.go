@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package compactsize
+
+import (
+	"io"
+	"math"
+
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/types"
+)
+
+// readEncoded reads the full encoding of the next value. Returns `io.EOF` if no bytes are available, and
+// `io.ErrUnexpectedEOF` if the encoding is incomplete.
+func readEncoded(in io.Reader, buffer *[9]byte) ([]byte, error) {
+	if _, err := io.ReadFull(in, buffer[:1]); err != nil {
+		return nil, err
+	}
+	var n = encodedSize(buffer[0])
+	if _, err := io.ReadFull(in, buffer[1:n]); err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	return buffer[:n], nil
+}
+
+// readUint reads the next value, which may be encoded non-canonically, and checks that it does not exceed
+// `max`.
+func readUint(in io.Reader, max uint64) (uint64, error) {
+	var buffer [9]byte
+	encoded, err := readEncoded(in, &buffer)
+	if err != nil {
+		return 0, err
+	}
+	value, _ := DecodeUint64(encoded)
+	if value > max {
+		return 0, errors.Context(errors.ErrOverflow, "value too large for data-type")
+	}
+	return value, nil
+}
+
+// ReadUint8 reads the next value into `uint8`. Returns `io.EOF` if no bytes are available,
+// `io.ErrUnexpectedEOF` if the encoding is incomplete, and `errors.ErrOverflow` if the value cannot be
+// represented in the data-type. The encoding is not required to be canonical, e.g. `fe 05 00 00 00` reads
+// as 5. See `ReadStrictUint8` to reject non-canonical encodings.
+func ReadUint8(in io.Reader) (uint8, error) {
+	value, err := readUint(in, math.MaxUint8)
+	return uint8(value), err
+}
+
+// ReadUint16 reads the next value into `uint16`. See `ReadUint8`.
+func ReadUint16(in io.Reader) (uint16, error) {
+	value, err := readUint(in, math.MaxUint16)
+	return uint16(value), err
+}
+
+// ReadUint32 reads the next value into `uint32`. See `ReadUint8`.
+func ReadUint32(in io.Reader) (uint32, error) {
+	value, err := readUint(in, math.MaxUint32)
+	return uint32(value), err
+}
+
+// ReadUint64 reads the next value into `uint64`. See `ReadUint8`.
+func ReadUint64(in io.Reader) (uint64, error) {
+	return readUint(in, math.MaxUint64)
+}
+
+func ReadUint(in io.Reader) (uint, error) {
+	if types.MaxUint == math.MaxUint32 {
+		value, err := ReadUint32(in)
+		return uint(value), err
+	}
+	value, err := ReadUint64(in)
+	return uint(value), err
+}
+
+// ReadStrictUint8 reads the next value into `uint8`, rejecting non-canonical encodings. Returns `io.EOF` if
+// no bytes are available. See `DecodeStrictUint8`.
+func ReadStrictUint8(in io.Reader) (uint8, error) {
+	var buffer [9]byte
+	encoded, err := readEncoded(in, &buffer)
+	if err != nil {
+		return 0, err
+	}
+	value, _, err := DecodeStrictUint8(encoded)
+	return value, err
+}
+
+// ReadStrictUint16 reads the next value into `uint16`. See `ReadStrictUint8`.
+func ReadStrictUint16(in io.Reader) (uint16, error) {
+	var buffer [9]byte
+	encoded, err := readEncoded(in, &buffer)
+	if err != nil {
+		return 0, err
+	}
+	value, _, err := DecodeStrictUint16(encoded)
+	return value, err
+}
+
+// ReadStrictUint32 reads the next value into `uint32`. See `ReadStrictUint8`.
+func ReadStrictUint32(in io.Reader) (uint32, error) {
+	var buffer [9]byte
+	encoded, err := readEncoded(in, &buffer)
+	if err != nil {
+		return 0, err
+	}
+	value, _, err := DecodeStrictUint32(encoded)
+	return value, err
+}
+
+// ReadStrictUint64 reads the next value into `uint64`. See `ReadStrictUint8`.
+func ReadStrictUint64(in io.Reader) (uint64, error) {
+	var buffer [9]byte
+	encoded, err := readEncoded(in, &buffer)
+	if err != nil {
+		return 0, err
+	}
+	value, _, err := DecodeStrictUint64(encoded)
+	return value, err
+}
+
+func ReadStrictUint(in io.Reader) (uint, error) {
+	var buffer [9]byte
+	encoded, err := readEncoded(in, &buffer)
+	if err != nil {
+		return 0, err
+	}
+	value, _, err := DecodeStrictUint(encoded)
+	return value, err
+}
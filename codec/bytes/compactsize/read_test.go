@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package compactsize
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestReadUint(t *testing.T) {
+	var in = bytes.NewReader([]byte{0xfc, 0xfd, 0xff, 0, 0xfd, 0xff, 0xff, 0xfe, 0xff, 0xff, 0xff, 0xff,
+		0xff, 1, 0, 0, 0, 0, 0, 0, 0, 0xfd, 1, 0})
+	assert.Equal(t, uint8(0xfc), builtin.Expect(ReadUint8(in)))
+	assert.Equal(t, uint8(0xff), builtin.Expect(ReadUint8(in)))
+	assert.Equal(t, uint16(0xffff), builtin.Expect(ReadUint16(in)))
+	assert.Equal(t, uint32(0xffffffff), builtin.Expect(ReadUint32(in)))
+	assert.Equal(t, uint64(1), builtin.Expect(ReadUint64(in)))
+	// lenient: non-canonical encoding is accepted
+	assert.Equal(t, uint(1), builtin.Expect(ReadUint(in)))
+	_, err := ReadUint64(in)
+	assert.IsError(t, io.EOF, err)
+}
+
+func TestReadUintErrors(t *testing.T) {
+	_, err := ReadUint64(bytes.NewReader([]byte{0xfe, 0xff, 0xff}))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+	_, err = ReadUint8(bytes.NewReader([]byte{0xfd, 0xff, 0x01}))
+	assert.IsError(t, errors.ErrOverflow, err)
+	_, err = ReadUint16(bytes.NewReader([]byte{0xfe, 0, 0, 1, 0}))
+	assert.IsError(t, errors.ErrOverflow, err)
+	_, err = ReadUint32(bytes.NewReader([]byte{0xff, 0, 0, 0, 0, 1, 0, 0, 0}))
+	assert.IsError(t, errors.ErrOverflow, err)
+}
+
+func TestReadUintNonCanonical(t *testing.T) {
+	assert.Equal(t, uint8(5), builtin.Expect(ReadUint8(bytes.NewReader([]byte{0xfe, 5, 0, 0, 0}))))
+	assert.Equal(t, uint8(0xff),
+		builtin.Expect(ReadUint8(bytes.NewReader([]byte{0xff, 0xff, 0, 0, 0, 0, 0, 0, 0}))))
+	assert.Equal(t, uint16(0), builtin.Expect(ReadUint16(bytes.NewReader([]byte{0xfe, 0, 0, 0, 0}))))
+	assert.Equal(t, uint32(0xffff),
+		builtin.Expect(ReadUint32(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0}))))
+	_, err := ReadUint8(bytes.NewReader([]byte{0xfe, 5, 0, 0}))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestReadStrictUint(t *testing.T) {
+	var in = bytes.NewReader([]byte{0xfc, 0xfd, 0xff, 0, 0xfd, 0xff, 0xff, 0xfe, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0, 0, 0, 0, 1, 0, 0, 0, 0x07})
+	assert.Equal(t, uint8(0xfc), builtin.Expect(ReadStrictUint8(in)))
+	assert.Equal(t, uint8(0xff), builtin.Expect(ReadStrictUint8(in)))
+	assert.Equal(t, uint16(0xffff), builtin.Expect(ReadStrictUint16(in)))
+	assert.Equal(t, uint32(0xffffffff), builtin.Expect(ReadStrictUint32(in)))
+	assert.Equal(t, uint64(0x100000000), builtin.Expect(ReadStrictUint64(in)))
+	assert.Equal(t, uint(7), builtin.Expect(ReadStrictUint(in)))
+	_, err := ReadStrictUint64(in)
+	assert.IsError(t, io.EOF, err)
+}
+
+func TestReadStrictUintErrors(t *testing.T) {
+	_, err := ReadStrictUint64(bytes.NewReader([]byte{0xfd, 0xfc}))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+	_, err = ReadStrictUint64(bytes.NewReader([]byte{0xfd, 0xfc, 0}))
+	assert.IsError(t, ErrNonCanonical, err)
+	_, err = ReadStrictUint8(bytes.NewReader([]byte{0xfd, 0, 1}))
+	assert.IsError(t, errors.ErrOverflow, err)
+}
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package compactsize
+
+import "io"
+
+func WriteUint8(out io.Writer, value uint8) error {
+	_, err := out.Write(EncodeUint8(value))
+	return err
+}
+
+func WriteUint16(out io.Writer, value uint16) error {
+	_, err := out.Write(EncodeUint16(value))
+	return err
+}
+
+func WriteUint32(out io.Writer, value uint32) error {
+	_, err := out.Write(EncodeUint32(value))
+	return err
+}
+
+func WriteUint64(out io.Writer, value uint64) error {
+	_, err := out.Write(EncodeUint64(value))
+	return err
+}
+
+func WriteUint(out io.Writer, value uint) error {
+	_, err := out.Write(EncodeUint(value))
+	return err
+}
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package compactsize
+
+import (
+	"bytes"
+	"testing"
+
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestWriteUint(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, WriteUint8(&b, 0xfd))
+	assert.Nil(t, WriteUint16(&b, 7))
+	assert.Nil(t, WriteUint32(&b, 0x10000))
+	assert.Nil(t, WriteUint64(&b, 0x100000000))
+	assert.Nil(t, WriteUint(&b, 0xffff))
+	assert.SlicesEqual(t, []byte{0xfd, 0xfd, 0, 0x07, 0xfe, 0, 0, 1, 0, 0xff, 0, 0, 0, 0, 1, 0, 0, 0,
+		0xfd, 0xff, 0xff}, b.Bytes())
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package hex
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// DumpConfig configures the output of `Dumper`. The zero-value for any of the fields selects the default.
+type DumpConfig struct {
+	// Width is the number of bytes per line. (default: 16)
+	Width uint
+	// Group is the number of bytes of which the digits are written together, without separating space.
+	// (default: 1)
+	Group uint
+	// Block is the number of bytes after which an additional space is written, or 0 for none.
+	Block uint
+	// Offsets enables the offset at the start of each line, and the total size after the last line.
+	Offsets bool
+	// ASCII enables the column with printable ASCII characters, with `.` for other bytes.
+	ASCII bool
+	// Upper selects upper-case hexadecimal digits.
+	Upper bool
+}
+
+// HexdumpConfig is the configuration that corresponds to the output of `hexdump -C`, except that repeated
+// lines are not collapsed.
+var HexdumpConfig = DumpConfig{Width: 16, Group: 1, Block: 8, Offsets: true, ASCII: true}
+
+// XxdConfig is the configuration that corresponds to the grouping of `xxd`. The offset and ASCII column are
+// formatted as for `hexdump -C`.
+var XxdConfig = DumpConfig{Width: 16, Group: 2, Offsets: true, ASCII: true}
+
+// Dumper is an io.WriteCloser that writes a hexdump of all data written to it. Lines are written as soon as
+// they are complete. `Close` writes the final, incomplete line, and the total size if offsets are enabled.
+// Closing the dumper does not close the underlying writer.
+type Dumper struct {
+	out    io.Writer
+	config DumpConfig
+	index  string
+	line   []byte
+	offset uint64
+	closed bool
+}
+
+// NewDumper creates a dumper that writes to `out`.
+func NewDumper(out io.Writer, config DumpConfig) *Dumper {
+	if config.Width == 0 {
+		config.Width = 16
+	}
+	if config.Group == 0 {
+		config.Group = 1
+	}
+	var digits = index
+	if config.Upper {
+		digits = indexUpper
+	}
+	return &Dumper{out: out, config: config, index: digits, line: make([]byte, 0, config.Width)}
+}
+
+// Dump returns the hexdump of data.
+func Dump(data []byte, config DumpConfig) string {
+	var b bytes.Buffer
+	var d = NewDumper(&b, config)
+	// Writing to bytes.Buffer does not fail.
+	d.Write(data)
+	d.Close()
+	return b.String()
+}
+
+// Write writes the hexdump of `p`.
+func (d *Dumper) Write(p []byte) (int, error) {
+	if d.closed {
+		return 0, errors.Context(errors.ErrInternalState, "dumper is closed")
+	}
+	var n int
+	for n < len(p) {
+		var count = min(len(p)-n, int(d.config.Width)-len(d.line))
+		d.line = append(d.line, p[n:n+count]...)
+		n += count
+		if len(d.line) == int(d.config.Width) {
+			if err := d.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close writes the final line and the total size, if offsets are enabled.
+func (d *Dumper) Close() error {
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	if len(d.line) > 0 {
+		if err := d.flush(); err != nil {
+			return err
+		}
+	}
+	if d.config.Offsets && d.offset > 0 {
+		var buffer [16]byte
+		_, err := d.out.Write(append(d.appendOffset(buffer[:0]), '\n'))
+		return err
+	}
+	return nil
+}
+
+// flush writes the pending line.
+func (d *Dumper) flush() error {
+	var buffer = make([]byte, 0, 20+4*d.config.Width)
+	if d.config.Offsets {
+		buffer = append(d.appendOffset(buffer), ' ', ' ')
+	}
+	for i := uint(0); i < d.config.Width; i++ {
+		if i > 0 && d.config.Block > 0 && i%d.config.Block == 0 {
+			buffer = append(buffer, ' ')
+		}
+		if i > 0 && i%d.config.Group == 0 {
+			buffer = append(buffer, ' ')
+		}
+		if i < uint(len(d.line)) {
+			buffer = append(buffer, d.index[d.line[i]>>4], d.index[d.line[i]&0x0f])
+		} else {
+			buffer = append(buffer, ' ', ' ')
+		}
+	}
+	if d.config.ASCII {
+		buffer = append(buffer, ' ', ' ', '|')
+		for _, b := range d.line {
+			if b < 0x20 || b > 0x7e {
+				b = '.'
+			}
+			buffer = append(buffer, b)
+		}
+		buffer = append(buffer, '|')
+	} else {
+		buffer = bytes.TrimRight(buffer, " ")
+	}
+	d.offset += uint64(len(d.line))
+	d.line = d.line[:0]
+	_, err := d.out.Write(append(buffer, '\n'))
+	return err
+}
+
+// appendOffset appends the offset as (at least) 8 hexadecimal digits.
+func (d *Dumper) appendOffset(dst []byte) []byte {
+	var digits = 8
+	for d.offset>>(4*digits) > 0 {
+		digits++
+	}
+	for i := digits - 1; i >= 0; i-- {
+		dst = append(dst, d.index[d.offset>>(4*i)&0x0f])
+	}
+	return dst
+}
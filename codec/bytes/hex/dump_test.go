@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package hex
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestDumpHexdump(t *testing.T) {
+	assert.Equal(t, "00000000  68 65 6c 6c 6f 20 77 6f  72 6c 64 0a              |hello world.|\n0000000c\n",
+		Dump([]byte("hello world\n"), HexdumpConfig))
+	assert.Equal(t, "", Dump(nil, HexdumpConfig))
+	var data []byte
+	for i := 0; i < 100; i++ {
+		// The standard library's dump has the same format, without the total size.
+		var expected = hex.Dump(data)
+		if len(data) > 0 {
+			expected += string(AppendEncode(nil, []byte{0, 0, 0, byte(len(data))})) + "\n"
+		}
+		assert.Equal(t, expected, Dump(data, HexdumpConfig))
+		data = append(data, byte(i*7))
+	}
+}
+
+func TestDumpConfigurations(t *testing.T) {
+	var data = []byte("hello world\n\x00\xff")
+	assert.Equal(t, "00000000  6865 6c6c 6f20 776f 726c 640a 00ff       |hello world...|\n0000000e\n",
+		Dump(data, XxdConfig))
+	assert.Equal(t, "68656C6C 6F20776F\n726C640A 00FF\n",
+		Dump(data, DumpConfig{Width: 8, Group: 4, Upper: true}))
+	assert.Equal(t, "68 65 6c 6c 6f  |hello|\n20 77 6f 72 6c  | worl|\n64 0a 00 ff     |d...|\n",
+		Dump(data, DumpConfig{Width: 5, ASCII: true}))
+	assert.Equal(t, "00000000  68 65  6c 6c\n00000004  6f 20  77 6f\n00000008  72 6c  64 0a\n"+
+		"0000000c  00 ff\n0000000e\n", Dump(data, DumpConfig{Width: 4, Block: 2, Offsets: true}))
+}
+
+func TestDumperStreaming(t *testing.T) {
+	var data = bytes.Repeat([]byte("0123456789"), 50)
+	var b bytes.Buffer
+	var d = NewDumper(&b, HexdumpConfig)
+	for _, chunk := range [][]byte{data[:1], data[1:17], data[17:40], data[40:]} {
+		assert.Equal(t, len(chunk), builtin.Expect(d.Write(chunk)))
+	}
+	assert.Nil(t, d.Close())
+	assert.Nil(t, d.Close())
+	assert.Equal(t, Dump(data, HexdumpConfig), b.String())
+	_, err := d.Write(data)
+	assert.IsError(t, errors.ErrInternalState, err)
+}
+
+func TestDumpLargeOffset(t *testing.T) {
+	var d = NewDumper(io.Discard, HexdumpConfig)
+	d.offset = 0x123456789
+	assert.Equal(t, "123456789", string(d.appendOffset(nil)))
+}
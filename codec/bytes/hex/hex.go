@@ -12,6 +12,37 @@ import (
 
 const index string = `0123456789abcdef`
 
+const indexUpper string = `0123456789ABCDEF`
+
+// Encode encodes data into (lower-case) hexadecimal representation.
+func Encode(data []byte) []byte {
+	return AppendEncode(make([]byte, 0, 2*len(data)), data)
+}
+
+// EncodeUpper encodes data into upper-case hexadecimal representation.
+func EncodeUpper(data []byte) []byte {
+	return AppendEncodeUpper(make([]byte, 0, 2*len(data)), data)
+}
+
+// AppendEncode appends the (lower-case) hexadecimal representation of `src` to `dst` and returns the
+// extended buffer.
+func AppendEncode(dst, src []byte) []byte {
+	return appendEncode(dst, src, index)
+}
+
+// AppendEncodeUpper appends the upper-case hexadecimal representation of `src` to `dst` and returns the
+// extended buffer.
+func AppendEncodeUpper(dst, src []byte) []byte {
+	return appendEncode(dst, src, indexUpper)
+}
+
+func appendEncode(dst, src []byte, index string) []byte {
+	for _, b := range src {
+		dst = append(dst, index[b>>4], index[b&0x0f])
+	}
+	return dst
+}
+
 // MustDecodeString decodes an arbitrary-length hex-encoded string.
 //
 // `encoded` must be a valid hexadecimal-encoded string, i.e. even-length and containing only hexadecimal
@@ -48,6 +79,16 @@ func HexEncodeChar(value uint8) byte {
 	return index[value]
 }
 
+// HexEncodeCharsUpper encodes a uint8 value into its two-symbol upper-case hexadecimal representation.
+func HexEncodeCharsUpper(value uint8) (byte, byte) {
+	return HexEncodeCharUpper((value & 0xf0) >> 4), HexEncodeCharUpper(value & 0x0f)
+}
+
+// HexEncodeCharUpper encodes uint value into its upper-case hexadecimal representation [0-9A-F].
+func HexEncodeCharUpper(value uint8) byte {
+	return indexUpper[value]
+}
+
 // HexDecodeChars decodes two chars from hexadecimal representation into a uint8 value.
 func HexDecodeChars(c0 byte, c1 byte) uint8 {
 	return HexDecodeChar(c0)<<4 | HexDecodeChar(c1)
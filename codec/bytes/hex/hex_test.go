@@ -73,3 +73,14 @@ func TestHexDecodeInvalid(t *testing.T) {
 	HexDecodeChar('z')
 	t.FailNow()
 }
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, "", string(Encode(nil)))
+	assert.Equal(t, "00ff7fa5", string(Encode([]byte{0x00, 0xff, 0x7f, 0xa5})))
+	assert.Equal(t, "00FF7FA5", string(EncodeUpper([]byte{0x00, 0xff, 0x7f, 0xa5})))
+	assert.Equal(t, "prefix:0a", string(AppendEncode([]byte("prefix:"), []byte{0x0a})))
+	assert.Equal(t, "prefix:0A", string(AppendEncodeUpper([]byte("prefix:"), []byte{0x0a})))
+	c0, c1 := HexEncodeCharsUpper(0xab)
+	assert.Equal(t, 'A', c0)
+	assert.Equal(t, 'B', c1)
+}
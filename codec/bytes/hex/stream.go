@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package hex
+
+import (
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+// bufferSize is the size of the buffer for encoded data of the streaming encoder and decoder.
+const bufferSize = 1024
+
+// Encoder is an io.Writer that writes the hexadecimal representation of all data written to it.
+type Encoder struct {
+	out   io.Writer
+	index string
+	buf   [bufferSize]byte
+}
+
+// NewEncoder creates an encoder that writes lower-case hexadecimal representation to `out`.
+func NewEncoder(out io.Writer) *Encoder {
+	return &Encoder{out: out, index: index}
+}
+
+// NewUpperEncoder creates an encoder that writes upper-case hexadecimal representation to `out`.
+func NewUpperEncoder(out io.Writer) *Encoder {
+	return &Encoder{out: out, index: indexUpper}
+}
+
+// Write encodes `p` and writes the representation to the underlying writer. The returned count is the
+// number of bytes of `p` of which the representation is written in full.
+func (e *Encoder) Write(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		var chunk = p[n:min(len(p), n+bufferSize/2)]
+		var encoded = appendEncode(e.buf[:0], chunk, e.index)
+		written, err := e.out.Write(encoded)
+		n += written / 2
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Decoder is an io.Reader that decodes hexadecimal representation read from the underlying reader.
+//
+// A strict decoder accepts only hexadecimal digits, of either case. A lenient decoder additionally accepts
+// separators, i.e. whitespace, `:`, `-` and `,`, and `0x`-prefixes before groups of digits, such that
+// fingerprints, MAC-addresses and byte-array literals can be decoded directly. Both digits of a byte must be
+// adjacent, i.e. a group must consist of an even number of digits.
+type Decoder struct {
+	in   io.Reader
+	scan scanner
+	buf  [bufferSize]byte
+	err  error
+}
+
+// NewDecoder creates a strict decoder that reads from `in`.
+func NewDecoder(in io.Reader) *Decoder {
+	return &Decoder{in: in}
+}
+
+// NewLenientDecoder creates a lenient decoder that reads from `in`.
+func NewLenientDecoder(in io.Reader) *Decoder {
+	return &Decoder{in: in, scan: scanner{lenient: true}}
+}
+
+// Read reads decoded data into `p`. Returns `io.ErrUnexpectedEOF` if the representation ends in the middle
+// of a byte, and `errors.ErrIllegal` (with context) for unexpected characters. Errors are persistent.
+func (d *Decoder) Read(p []byte) (int, error) {
+	var n int
+	for n < len(p) && d.err == nil {
+		// Every decoded byte requires at least two characters, therefore decoded bytes fit in `p`.
+		var count int
+		count, d.err = d.in.Read(d.buf[:min(len(d.buf), 2*(len(p)-n))])
+		for _, c := range d.buf[:count] {
+			b, ok, err := d.scan.next(c)
+			if err != nil {
+				d.err = err
+				return n, err
+			}
+			if ok {
+				p[n] = b
+				n++
+			}
+		}
+		if d.err == io.EOF {
+			if err := d.scan.end(); err != nil {
+				d.err = err
+			}
+		}
+		if n > 0 {
+			break
+		}
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return n, d.err
+}
+
+// DecodeLenient decodes hexadecimal representation, accepting separators and `0x`-prefixes. See `Decoder`
+// for the accepted representations.
+func DecodeLenient(encoded []byte) ([]byte, error) {
+	var s = scanner{lenient: true}
+	var decoded = make([]byte, 0, len(encoded)/2)
+	for _, c := range encoded {
+		b, ok, err := s.next(c)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			decoded = append(decoded, b)
+		}
+	}
+	if err := s.end(); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// scanner decodes hexadecimal representation one character at a time.
+type scanner struct {
+	lenient bool
+	// pos is the position of the next character.
+	pos int64
+	// high is the pending high nibble, if `pending`.
+	high    byte
+	pending bool
+	// digits is the number of digits in the current group.
+	digits int
+	// prefixed indicates that the current group starts with `0x`.
+	prefixed bool
+}
+
+// next processes the next character, and returns a decoded byte if `ok`.
+func (s *scanner) next(c byte) (byte, bool, error) {
+	var pos = s.pos
+	s.pos++
+	if AllHexadecimal([]byte{c}) {
+		s.digits++
+		if !s.pending {
+			s.high, s.pending = HexDecodeChar(c), true
+			return 0, false, nil
+		}
+		s.pending = false
+		return s.high<<4 | HexDecodeChar(c), true, nil
+	}
+	if s.lenient {
+		switch c {
+		case 'x', 'X':
+			if s.pending && s.high == 0 && s.digits == 1 && !s.prefixed {
+				s.pending, s.digits, s.prefixed = false, 0, true
+				return 0, false, nil
+			}
+		case ' ', '\t', '\r', '\n', ':', '-', ',':
+			if err := s.separate(pos); err != nil {
+				return 0, false, err
+			}
+			return 0, false, nil
+		}
+	}
+	return 0, false, errors.Context(errors.ErrIllegal, "unexpected character "+strconv.FormatUint(c, 16)+
+		" at position "+strconv.FormatIntDecimal(pos))
+}
+
+// separate completes the current group at the separator at position `pos`.
+func (s *scanner) separate(pos int64) error {
+	if s.pending {
+		return errors.Context(errors.ErrIllegal, "odd number of digits before position "+
+			strconv.FormatIntDecimal(pos))
+	}
+	if s.prefixed && s.digits == 0 {
+		return errors.Context(errors.ErrIllegal, "prefix without digits before position "+
+			strconv.FormatIntDecimal(pos))
+	}
+	s.digits, s.prefixed = 0, false
+	return nil
+}
+
+// end completes the representation.
+func (s *scanner) end() error {
+	if s.pending {
+		return io.ErrUnexpectedEOF
+	}
+	return s.separate(s.pos)
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package hex
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestEncoder(t *testing.T) {
+	var data = bytes.Repeat([]byte{0x01, 0xab, 0xef}, 1000)
+	var b bytes.Buffer
+	var n = builtin.Expect(NewEncoder(&b).Write(data))
+	assert.Equal(t, len(data), n)
+	assert.Equal(t, string(Encode(data)), b.String())
+	b.Reset()
+	builtin.Expect(NewUpperEncoder(&b).Write(data[:3]))
+	assert.Equal(t, "01ABEF", b.String())
+}
+
+func TestDecoder(t *testing.T) {
+	var data = bytes.Repeat([]byte{0x01, 0xab, 0xef}, 1000)
+	var decoded = builtin.Expect(io.ReadAll(NewDecoder(bytes.NewReader(EncodeUpper(data)))))
+	assert.SlicesEqual(t, data, decoded)
+	decoded = builtin.Expect(io.ReadAll(NewDecoder(iotest.OneByteReader(bytes.NewReader(Encode(data))))))
+	assert.SlicesEqual(t, data, decoded)
+	assert.Nil(t, iotest.TestReader(NewDecoder(bytes.NewReader(Encode(data))), data))
+}
+
+func TestDecoderErrors(t *testing.T) {
+	_, err := io.ReadAll(NewDecoder(strings.NewReader("abc")))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+	var d = NewDecoder(strings.NewReader("abcd:ef"))
+	var buffer [10]byte
+	n, err := d.Read(buffer[:])
+	assert.Equal(t, 2, n)
+	assert.IsError(t, errors.ErrIllegal, err)
+	assert.SlicesEqual(t, []byte{0xab, 0xcd}, buffer[:n])
+	// errors are persistent
+	_, err = d.Read(buffer[:])
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestDecodeLenient(t *testing.T) {
+	testdata := []struct {
+		encoded string
+		decoded []byte
+	}{
+		{"", []byte{}},
+		{"00ff", []byte{0x00, 0xff}},
+		{"aa:bb:cc:dd:ee:FF", []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}},
+		{"aa-bb-cc-dd-ee-ff", []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}},
+		{"0xdeadbeef", []byte{0xde, 0xad, 0xbe, 0xef}},
+		{"0x01, 0x02,0X03", []byte{0x01, 0x02, 0x03}},
+		{"0001 0203\n\t0405  ", []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05}},
+		{"0x00", []byte{0x00}},
+		{"0000", []byte{0x00, 0x00}},
+	}
+	for _, test := range testdata {
+		assert.SlicesEqual(t, test.decoded, builtin.Expect(DecodeLenient([]byte(test.encoded))))
+		var decoded = builtin.Expect(io.ReadAll(NewLenientDecoder(iotest.HalfReader(
+			strings.NewReader(test.encoded)))))
+		assert.SlicesEqual(t, test.decoded, decoded)
+	}
+}
+
+func TestDecodeLenientInvalid(t *testing.T) {
+	testdata := []struct {
+		encoded string
+		err     error
+	}{
+		{"abc", io.ErrUnexpectedEOF},
+		{"a:bc", errors.ErrIllegal},
+		{"abc:d", errors.ErrIllegal},
+		{"0x", errors.ErrIllegal},
+		{"0x 00", errors.ErrIllegal},
+		{"00x1", errors.ErrIllegal},
+		{"0x0x00", errors.ErrIllegal},
+		{"1x00", errors.ErrIllegal},
+		{"x00", errors.ErrIllegal},
+		{"00;01", errors.ErrIllegal},
+		{"zz", errors.ErrIllegal},
+	}
+	for _, test := range testdata {
+		_, err := DecodeLenient([]byte(test.encoded))
+		assert.IsError(t, test.err, err)
+		_, err = io.ReadAll(NewLenientDecoder(strings.NewReader(test.encoded)))
+		assert.IsError(t, test.err, err)
+	}
+	_, err := io.ReadAll(NewDecoder(strings.NewReader("00:01")))
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = io.ReadAll(NewDecoder(strings.NewReader("0x01")))
+	assert.IsError(t, errors.ErrIllegal, err)
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// LEB128 (Little-Endian Base-128) variable-length encoding of integers.
+//
+// Unsigned LEB128 is the varint-encoding used by protobuf and by Go's `encoding/binary.Uvarint`. Signed
+// LEB128 encodes two's complement integers, with sign-extension of the final byte, as used by DWARF and
+// WebAssembly. Zigzag-mapping maps signed integers to unsigned integers such that values of small magnitude
+// have small encodings, as is done for protobuf's `sint32` and `sint64` and Go's `encoding/binary.Varint`.
+//
+// Decoding is strict: truncated input results in `io.ErrUnexpectedEOF`, overlong (non-minimal) encodings in
+// `ErrOverlong`, and values that exceed the range of the data-type in `errors.ErrOverflow`. Consequently,
+// every value has exactly one accepted encoding.
+//
+// ref: <https://en.wikipedia.org/wiki/LEB128>
+// ref: <https://protobuf.dev/programming-guides/encoding/#varints>
+// ref: <https://webassembly.github.io/spec/core/binary/values.html#integers>
+package leb128
+
+import "github.com/cobratbq/goutils/std/errors"
+
+// ErrOverlong indicates an encoding that uses more bytes than necessary.
+var ErrOverlong = errors.NewStringError("overlong encoding")
+
+// maxLen is the maximum length of an encoded 64-bit integer.
+const maxLen = 10
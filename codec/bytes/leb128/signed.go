@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package leb128
+
+import (
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/types"
+)
+
+func encodeSigned(dest *[maxLen]byte, value int64) uint {
+	var n uint
+	for {
+		var b = byte(value & 0x7f)
+		value >>= 7
+		// Terminate once the remaining bits are fully represented by sign-extension of bit 6.
+		if (value == 0 && b&0x40 == 0) || (value == -1 && b&0x40 != 0) {
+			dest[n] = b
+			return n + 1
+		}
+		dest[n] = b | 0x80
+		n++
+	}
+}
+
+// EncodeIntoSigned encodes `value` into `dest`. Returns the number of bytes written, or 0 if `dest` is too
+// small.
+func EncodeIntoSigned[T types.SignedInteger](dest []byte, value T) uint {
+	var buffer [maxLen]byte
+	var n = encodeSigned(&buffer, int64(value))
+	if uint(len(dest)) < n {
+		return 0
+	}
+	return uint(copy(dest, buffer[:n]))
+}
+
+// EncodeSigned encodes `value`.
+func EncodeSigned[T types.SignedInteger](value T) []byte {
+	var buffer [maxLen]byte
+	var n = encodeSigned(&buffer, int64(value))
+	return append([]byte(nil), buffer[:n]...)
+}
+
+// WriteSigned writes the encoded `value` to `out`.
+func WriteSigned[T types.SignedInteger](out io.Writer, value T) error {
+	var buffer [maxLen]byte
+	var n = encodeSigned(&buffer, int64(value))
+	_, err := out.Write(buffer[:n])
+	return err
+}
+
+// DecodeSigned decodes the next value in `buffer`. Returns the value and the number of bytes read. Returns
+// `io.ErrUnexpectedEOF` if the buffer ends before the end of the encoding, `ErrOverlong` if the encoding is
+// not minimal, and `errors.ErrOverflow` if the value does not fit in the data-type.
+func DecodeSigned[T types.SignedInteger](buffer []byte) (T, uint, error) {
+	var value int64
+	for i, b := range buffer {
+		// The final byte of a 64-bit value carries only the most-significant bit, i.e. the sign.
+		if i == maxLen-1 && b != 0x00 && b != 0x7f {
+			return 0, 0, errors.Context(errors.ErrOverflow, "encoding exceeds 64 bits")
+		}
+		value |= int64(b&0x7f) << (7 * i)
+		if b&0x80 != 0 {
+			continue
+		}
+		// The final byte is redundant if it only repeats the sign of the preceding byte.
+		if i > 0 && ((b == 0x00 && buffer[i-1]&0x40 == 0) || (b == 0x7f && buffer[i-1]&0x40 != 0)) {
+			return 0, 0, errors.Context(ErrOverlong, "redundant sign-extension byte")
+		}
+		if shift := 7 * (i + 1); shift < 64 && b&0x40 != 0 {
+			value |= -1 << shift
+		}
+		if int64(T(value)) != value {
+			return 0, 0, errors.Context(errors.ErrOverflow, "value exceeds range of data-type")
+		}
+		return T(value), uint(i + 1), nil
+	}
+	return 0, 0, io.ErrUnexpectedEOF
+}
+
+// ReadSigned reads the next value from `in`. Returns `io.EOF` if no bytes are available. See
+// `DecodeSigned`.
+func ReadSigned[T types.SignedInteger](in io.Reader) (T, error) {
+	var buffer [maxLen]byte
+	n, err := read(in, &buffer)
+	if err != nil {
+		return 0, err
+	}
+	value, _, err := DecodeSigned[T](buffer[:n])
+	return value, err
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package leb128
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+var signedVectors = []struct {
+	value   int64
+	encoded []byte
+}{
+	{0, []byte{0x00}},
+	{1, []byte{0x01}},
+	{-1, []byte{0x7f}},
+	{63, []byte{0x3f}},
+	{-64, []byte{0x40}},
+	{64, []byte{0xc0, 0x00}},
+	{-65, []byte{0xbf, 0x7f}},
+	{127, []byte{0xff, 0x00}},
+	{-128, []byte{0x80, 0x7f}},
+	{-123456, []byte{0xc0, 0xbb, 0x78}},
+	{math.MaxInt64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}},
+	{math.MinInt64, []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x7f}},
+}
+
+func TestEncodeSigned(t *testing.T) {
+	for _, test := range signedVectors {
+		assert.SlicesEqual(t, test.encoded, EncodeSigned(test.value))
+	}
+	assert.SlicesEqual(t, []byte{0x80, 0x7f}, EncodeSigned(int8(-128)))
+}
+
+func TestEncodeIntoSigned(t *testing.T) {
+	var buffer [3]byte
+	assert.Equal(t, 0, EncodeIntoSigned(buffer[:2], int32(-123456)))
+	assert.Equal(t, [3]byte{}, buffer)
+	assert.Equal(t, 3, EncodeIntoSigned(buffer[:], int32(-123456)))
+	assert.Equal(t, [3]byte{0xc0, 0xbb, 0x78}, buffer)
+}
+
+func TestDecodeSigned(t *testing.T) {
+	for _, test := range signedVectors {
+		value, n, err := DecodeSigned[int64](append(test.encoded, 0x01))
+		assert.Nil(t, err)
+		assert.Equal(t, uint(len(test.encoded)), n)
+		assert.Equal(t, test.value, value)
+	}
+}
+
+func TestDecodeSignedErrors(t *testing.T) {
+	testdata := []struct {
+		input []byte
+		err   error
+	}{
+		{[]byte{}, io.ErrUnexpectedEOF},
+		{[]byte{0xc0}, io.ErrUnexpectedEOF},
+		{[]byte{0x80, 0x00}, ErrOverlong},
+		{[]byte{0xff, 0x7f}, ErrOverlong},
+		{[]byte{0xc0, 0x80, 0x00}, ErrOverlong},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}, errors.ErrOverflow},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, errors.ErrOverflow},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, ErrOverlong},
+	}
+	for _, test := range testdata {
+		_, n, err := DecodeSigned[int64](test.input)
+		assert.IsError(t, test.err, err)
+		assert.Equal(t, 0, n)
+	}
+}
+
+func TestDecodeSignedRange(t *testing.T) {
+	value, _, err := DecodeSigned[int8]([]byte{0x80, 0x7f})
+	assert.Nil(t, err)
+	assert.Equal(t, int8(-128), value)
+	value, _, err = DecodeSigned[int8]([]byte{0xff, 0x00})
+	assert.Nil(t, err)
+	assert.Equal(t, int8(127), value)
+	_, _, err = DecodeSigned[int8](EncodeSigned(128))
+	assert.IsError(t, errors.ErrOverflow, err)
+	_, _, err = DecodeSigned[int8](EncodeSigned(-129))
+	assert.IsError(t, errors.ErrOverflow, err)
+	_, _, err = DecodeSigned[int32](EncodeSigned(int64(math.MinInt32) - 1))
+	assert.IsError(t, errors.ErrOverflow, err)
+}
+
+func TestReadWriteSigned(t *testing.T) {
+	var b bytes.Buffer
+	for _, test := range signedVectors {
+		assert.Nil(t, WriteSigned(&b, test.value))
+	}
+	for _, test := range signedVectors {
+		assert.Equal(t, test.value, builtin.Expect(ReadSigned[int64](&b)))
+	}
+	_, err := ReadSigned[int64](&b)
+	assert.IsError(t, io.EOF, err)
+	_, err = ReadSigned[int16](bytes.NewReader([]byte{0xc0}))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package leb128
+
+import (
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/types"
+)
+
+func encodeUnsigned(dest *[maxLen]byte, value uint64) uint {
+	var n uint
+	for ; value > 0x7f; n++ {
+		dest[n] = byte(value&0x7f) | 0x80
+		value >>= 7
+	}
+	dest[n] = byte(value)
+	return n + 1
+}
+
+// EncodeIntoUnsigned encodes `value` into `dest`. Returns the number of bytes written, or 0 if `dest` is too
+// small.
+func EncodeIntoUnsigned[T types.UnsignedInteger](dest []byte, value T) uint {
+	var buffer [maxLen]byte
+	var n = encodeUnsigned(&buffer, uint64(value))
+	if uint(len(dest)) < n {
+		return 0
+	}
+	return uint(copy(dest, buffer[:n]))
+}
+
+// EncodeUnsigned encodes `value`.
+func EncodeUnsigned[T types.UnsignedInteger](value T) []byte {
+	var buffer [maxLen]byte
+	var n = encodeUnsigned(&buffer, uint64(value))
+	return append([]byte(nil), buffer[:n]...)
+}
+
+// WriteUnsigned writes the encoded `value` to `out`.
+func WriteUnsigned[T types.UnsignedInteger](out io.Writer, value T) error {
+	var buffer [maxLen]byte
+	var n = encodeUnsigned(&buffer, uint64(value))
+	_, err := out.Write(buffer[:n])
+	return err
+}
+
+// DecodeUnsigned decodes the next value in `buffer`. Returns the value and the number of bytes read.
+// Returns `io.ErrUnexpectedEOF` if the buffer ends before the end of the encoding, `ErrOverlong` if the
+// encoding is not minimal, and `errors.ErrOverflow` if the value does not fit in the data-type.
+func DecodeUnsigned[T types.UnsignedInteger](buffer []byte) (T, uint, error) {
+	var value uint64
+	for i, b := range buffer {
+		// The final byte of a 64-bit value carries only the most-significant bit.
+		if i == maxLen-1 && b > 1 {
+			return 0, 0, errors.Context(errors.ErrOverflow, "encoding exceeds 64 bits")
+		}
+		value |= uint64(b&0x7f) << (7 * i)
+		if b&0x80 != 0 {
+			continue
+		}
+		if i > 0 && b == 0 {
+			return 0, 0, errors.Context(ErrOverlong, "trailing zero-byte")
+		}
+		if value > uint64(^T(0)) {
+			return 0, 0, errors.Context(errors.ErrOverflow, "value exceeds range of data-type")
+		}
+		return T(value), uint(i + 1), nil
+	}
+	return 0, 0, io.ErrUnexpectedEOF
+}
+
+// ReadUnsigned reads the next value from `in`. Returns `io.EOF` if no bytes are available. See
+// `DecodeUnsigned`.
+func ReadUnsigned[T types.UnsignedInteger](in io.Reader) (T, error) {
+	var buffer [maxLen]byte
+	n, err := read(in, &buffer)
+	if err != nil {
+		return 0, err
+	}
+	value, _, err := DecodeUnsigned[T](buffer[:n])
+	return value, err
+}
+
+// read reads the bytes of the next encoded value, up to and including the first byte without continuation,
+// or until the buffer is full.
+func read(in io.Reader, buffer *[maxLen]byte) (int, error) {
+	for n := 0; n < maxLen; n++ {
+		if _, err := io.ReadFull(in, buffer[n:n+1]); err == io.EOF && n > 0 {
+			return 0, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return 0, err
+		}
+		if buffer[n]&0x80 == 0 {
+			return n + 1, nil
+		}
+	}
+	return maxLen, nil
+}
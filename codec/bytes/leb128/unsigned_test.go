@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package leb128
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+var unsignedValues = []uint64{0, 1, 0x7f, 0x80, 0x3fff, 0x4000, 624485, math.MaxUint32, 1 << 63,
+	math.MaxUint64}
+
+func TestEncodeUnsigned(t *testing.T) {
+	assert.SlicesEqual(t, []byte{0xe5, 0x8e, 0x26}, EncodeUnsigned(uint32(624485)))
+	for _, v := range unsignedValues {
+		// compatible with `encoding/binary`
+		assert.SlicesEqual(t, binary.AppendUvarint(nil, v), EncodeUnsigned(v))
+	}
+}
+
+func TestEncodeIntoUnsigned(t *testing.T) {
+	var buffer [3]byte
+	assert.Equal(t, 0, EncodeIntoUnsigned(buffer[:2], uint(624485)))
+	assert.Equal(t, [3]byte{}, buffer)
+	assert.Equal(t, 3, EncodeIntoUnsigned(buffer[:], uint(624485)))
+	assert.Equal(t, [3]byte{0xe5, 0x8e, 0x26}, buffer)
+}
+
+func TestDecodeUnsigned(t *testing.T) {
+	for _, v := range unsignedValues {
+		var encoded = binary.AppendUvarint(nil, v)
+		value, n, err := DecodeUnsigned[uint64](append(encoded, 0x01))
+		assert.Nil(t, err)
+		assert.Equal(t, uint(len(encoded)), n)
+		assert.Equal(t, v, value)
+	}
+}
+
+func TestDecodeUnsignedErrors(t *testing.T) {
+	testdata := []struct {
+		input []byte
+		err   error
+	}{
+		{[]byte{}, io.ErrUnexpectedEOF},
+		{[]byte{0x80}, io.ErrUnexpectedEOF},
+		{[]byte{0xff, 0xff}, io.ErrUnexpectedEOF},
+		{[]byte{0x80, 0x00}, ErrOverlong},
+		{[]byte{0xff, 0x80, 0x00}, ErrOverlong},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, ErrOverlong},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x02}, errors.ErrOverflow},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x81, 0x00}, errors.ErrOverflow},
+	}
+	for _, test := range testdata {
+		_, n, err := DecodeUnsigned[uint64](test.input)
+		assert.IsError(t, test.err, err)
+		assert.Equal(t, 0, n)
+	}
+}
+
+func TestDecodeUnsignedRange(t *testing.T) {
+	value, _, err := DecodeUnsigned[uint8]([]byte{0xff, 0x01})
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(0xff), value)
+	_, _, err = DecodeUnsigned[uint8]([]byte{0x80, 0x02})
+	assert.IsError(t, errors.ErrOverflow, err)
+	value16, _, err := DecodeUnsigned[uint16]([]byte{0xff, 0xff, 0x03})
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(0xffff), value16)
+	_, _, err = DecodeUnsigned[uint16]([]byte{0x80, 0x80, 0x04})
+	assert.IsError(t, errors.ErrOverflow, err)
+	_, _, err = DecodeUnsigned[uint32](EncodeUnsigned(uint64(math.MaxUint32) + 1))
+	assert.IsError(t, errors.ErrOverflow, err)
+}
+
+func TestReadWriteUnsigned(t *testing.T) {
+	var b bytes.Buffer
+	for _, v := range unsignedValues {
+		assert.Nil(t, WriteUnsigned(&b, v))
+	}
+	for _, v := range unsignedValues {
+		assert.Equal(t, v, builtin.Expect(ReadUnsigned[uint64](&b)))
+	}
+	_, err := ReadUnsigned[uint64](&b)
+	assert.IsError(t, io.EOF, err)
+	_, err = ReadUnsigned[uint64](bytes.NewReader([]byte{0x80}))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+	_, err = ReadUnsigned[uint64](bytes.NewReader([]byte{0x80, 0x00}))
+	assert.IsError(t, ErrOverlong, err)
+	_, err = ReadUnsigned[uint64](bytes.NewReader(bytes.Repeat([]byte{0xff}, 12)))
+	assert.IsError(t, errors.ErrOverflow, err)
+}
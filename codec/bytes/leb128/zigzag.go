@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package leb128
+
+import (
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/types"
+)
+
+// ZigZag maps a signed integer onto an unsigned integer, alternating between non-negative and negative
+// values: 0 → 0, -1 → 1, 1 → 2, -2 → 3, ... The result fits the unsigned counterpart of `T`.
+func ZigZag[T types.SignedInteger](value T) uint64 {
+	var v = int64(value)
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// UnZigZag maps an unsigned integer back onto the signed integer. Returns `errors.ErrOverflow` if the
+// result does not fit in the data-type.
+func UnZigZag[T types.SignedInteger](value uint64) (T, error) {
+	var v = int64(value>>1) ^ -int64(value&1)
+	if int64(T(v)) != v {
+		return 0, errors.Context(errors.ErrOverflow, "value exceeds range of data-type")
+	}
+	return T(v), nil
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package leb128
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestZigZag(t *testing.T) {
+	assert.Equal(t, 0, ZigZag(0))
+	assert.Equal(t, 1, ZigZag(-1))
+	assert.Equal(t, 2, ZigZag(1))
+	assert.Equal(t, 3, ZigZag(-2))
+	assert.Equal(t, math.MaxUint8, ZigZag(int8(math.MinInt8)))
+	assert.Equal(t, math.MaxUint8-1, ZigZag(int8(math.MaxInt8)))
+	assert.Equal(t, math.MaxUint32, ZigZag(int32(math.MinInt32)))
+	assert.Equal(t, uint64(math.MaxUint64), ZigZag(int64(math.MinInt64)))
+	for _, v := range []int64{0, -1, 1, -64, 64, math.MinInt32, math.MaxInt64, math.MinInt64} {
+		// compatible with `encoding/binary`
+		assert.SlicesEqual(t, binary.AppendVarint(nil, v), EncodeUnsigned(ZigZag(v)))
+	}
+}
+
+func TestUnZigZag(t *testing.T) {
+	for _, v := range []int64{0, -1, 1, -2, math.MinInt64, math.MaxInt64} {
+		assert.Equal(t, v, builtin.Expect(UnZigZag[int64](ZigZag(v))))
+	}
+	assert.Equal(t, int8(math.MinInt8), builtin.Expect(UnZigZag[int8](math.MaxUint8)))
+	_, err := UnZigZag[int8](math.MaxUint8 + 1)
+	assert.IsError(t, errors.ErrOverflow, err)
+	_, err = UnZigZag[int32](math.MaxUint32 + 1)
+	assert.IsError(t, errors.ErrOverflow, err)
+}
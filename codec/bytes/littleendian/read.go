@@ -4,6 +4,7 @@ package littleendian
 
 import (
 	"io"
+	"math"
 
 	io_ "github.com/cobratbq/goutils/std/io"
 )
@@ -70,3 +71,113 @@ func Uint64(b0, b1, b2, b3, b4, b5, b6, b7 byte) uint64 {
 	return uint64(b7)<<56 + uint64(b6)<<48 + uint64(b5)<<40 + uint64(b4)<<32 +
 		uint64(b3)<<24 + uint64(b2)<<16 + uint64(b1)<<8 + uint64(b0)
 }
+
+func ReadInt8(in io.Reader) (int8, error) {
+	value, err := ReadUint8(in)
+	return int8(value), err
+}
+
+func MustReadInt8(in io.Reader) int8 {
+	return int8(MustReadUint8(in))
+}
+
+func ReadInt16(in io.Reader) (int16, error) {
+	value, err := ReadUint16(in)
+	return int16(value), err
+}
+
+func MustReadInt16(in io.Reader) int16 {
+	return int16(MustReadUint16(in))
+}
+
+func Int16(b0, b1 byte) int16 {
+	return int16(Uint16(b0, b1))
+}
+
+func ReadInt32(in io.Reader) (int32, error) {
+	value, err := ReadUint32(in)
+	return int32(value), err
+}
+
+func MustReadInt32(in io.Reader) int32 {
+	return int32(MustReadUint32(in))
+}
+
+func Int32(b0, b1, b2, b3 byte) int32 {
+	return int32(Uint32(b0, b1, b2, b3))
+}
+
+func ReadInt64(in io.Reader) (int64, error) {
+	value, err := ReadUint64(in)
+	return int64(value), err
+}
+
+func MustReadInt64(in io.Reader) int64 {
+	return int64(MustReadUint64(in))
+}
+
+func Int64(b0, b1, b2, b3, b4, b5, b6, b7 byte) int64 {
+	return int64(Uint64(b0, b1, b2, b3, b4, b5, b6, b7))
+}
+
+func ReadUint24(in io.Reader) (uint32, error) {
+	var b [3]byte
+	if _, err := io.ReadFull(in, b[:]); err != nil {
+		return 0, err
+	}
+	return Uint24(b[0], b[1], b[2]), nil
+}
+
+func MustReadUint24(in io.Reader) uint32 {
+	var b [3]byte
+	io_.MustReadBytes(in, b[:])
+	return Uint24(b[0], b[1], b[2])
+}
+
+func Uint24(b0, b1, b2 byte) uint32 {
+	return uint32(b2)<<16 + uint32(b1)<<8 + uint32(b0)
+}
+
+func ReadUint48(in io.Reader) (uint64, error) {
+	var b [6]byte
+	if _, err := io.ReadFull(in, b[:]); err != nil {
+		return 0, err
+	}
+	return Uint48(b[0], b[1], b[2], b[3], b[4], b[5]), nil
+}
+
+func MustReadUint48(in io.Reader) uint64 {
+	var b [6]byte
+	io_.MustReadBytes(in, b[:])
+	return Uint48(b[0], b[1], b[2], b[3], b[4], b[5])
+}
+
+func Uint48(b0, b1, b2, b3, b4, b5 byte) uint64 {
+	return uint64(b5)<<40 + uint64(b4)<<32 + uint64(b3)<<24 + uint64(b2)<<16 + uint64(b1)<<8 + uint64(b0)
+}
+
+func ReadFloat32(in io.Reader) (float32, error) {
+	value, err := ReadUint32(in)
+	return math.Float32frombits(value), err
+}
+
+func MustReadFloat32(in io.Reader) float32 {
+	return math.Float32frombits(MustReadUint32(in))
+}
+
+func Float32(b0, b1, b2, b3 byte) float32 {
+	return math.Float32frombits(Uint32(b0, b1, b2, b3))
+}
+
+func ReadFloat64(in io.Reader) (float64, error) {
+	value, err := ReadUint64(in)
+	return math.Float64frombits(value), err
+}
+
+func MustReadFloat64(in io.Reader) float64 {
+	return math.Float64frombits(MustReadUint64(in))
+}
+
+func Float64(b0, b1, b2, b3, b4, b5, b6, b7 byte) float64 {
+	return math.Float64frombits(Uint64(b0, b1, b2, b3, b4, b5, b6, b7))
+}
@@ -3,7 +3,13 @@
 // FIXME consider using '|' instead of '+' to  add parts of uint values.
 package littleendian
 
-import "io"
+import (
+	"io"
+	"math"
+
+	"github.com/cobratbq/goutils/assert"
+	"github.com/cobratbq/goutils/std/errors"
+)
 
 func WriteUint8(out io.Writer, value uint8) error {
 	_, err := out.Write([]byte{value})
@@ -53,3 +59,88 @@ func FromUint64(value uint64) [8]byte {
 	b7 := uint8((0xff00000000000000 & value) >> 56)
 	return [...]byte{b0, b1, b2, b3, b4, b5, b6, b7}
 }
+
+// MaxUint24 is the maximum value of a 24-bit unsigned integer.
+const MaxUint24 = 1<<24 - 1
+
+// MaxUint48 is the maximum value of a 48-bit unsigned integer.
+const MaxUint48 = 1<<48 - 1
+
+func WriteInt8(out io.Writer, value int8) error {
+	return WriteUint8(out, uint8(value))
+}
+
+func WriteInt16(out io.Writer, value int16) error {
+	return WriteUint16(out, uint16(value))
+}
+
+func FromInt16(value int16) [2]byte {
+	return FromUint16(uint16(value))
+}
+
+func WriteInt32(out io.Writer, value int32) error {
+	return WriteUint32(out, uint32(value))
+}
+
+func FromInt32(value int32) [4]byte {
+	return FromUint32(uint32(value))
+}
+
+func WriteInt64(out io.Writer, value int64) error {
+	return WriteUint64(out, uint64(value))
+}
+
+func FromInt64(value int64) [8]byte {
+	return FromUint64(uint64(value))
+}
+
+// WriteUint24 writes the value as 24-bit unsigned integer. Returns `errors.ErrOverflow` if the value
+// does not fit.
+func WriteUint24(out io.Writer, value uint32) error {
+	if value > MaxUint24 {
+		return errors.Context(errors.ErrOverflow, "value exceeds 24 bits")
+	}
+	encoded := FromUint24(value)
+	_, err := out.Write(encoded[:])
+	return err
+}
+
+// FromUint24 encodes the value as 24-bit unsigned integer. The value must not exceed `MaxUint24`.
+func FromUint24(value uint32) [3]byte {
+	assert.True(value <= MaxUint24)
+	return [...]byte{uint8(value), uint8(value >> 8), uint8(value >> 16)}
+}
+
+// WriteUint48 writes the value as 48-bit unsigned integer. Returns `errors.ErrOverflow` if the value
+// does not fit.
+func WriteUint48(out io.Writer, value uint64) error {
+	if value > MaxUint48 {
+		return errors.Context(errors.ErrOverflow, "value exceeds 48 bits")
+	}
+	encoded := FromUint48(value)
+	_, err := out.Write(encoded[:])
+	return err
+}
+
+// FromUint48 encodes the value as 48-bit unsigned integer. The value must not exceed `MaxUint48`.
+func FromUint48(value uint64) [6]byte {
+	assert.True(value <= MaxUint48)
+	return [...]byte{uint8(value), uint8(value >> 8), uint8(value >> 16),
+		uint8(value >> 24), uint8(value >> 32), uint8(value >> 40)}
+}
+
+func WriteFloat32(out io.Writer, value float32) error {
+	return WriteUint32(out, math.Float32bits(value))
+}
+
+func FromFloat32(value float32) [4]byte {
+	return FromUint32(math.Float32bits(value))
+}
+
+func WriteFloat64(out io.Writer, value float64) error {
+	return WriteUint64(out, math.Float64bits(value))
+}
+
+func FromFloat64(value float64) [8]byte {
+	return FromUint64(math.Float64bits(value))
+}
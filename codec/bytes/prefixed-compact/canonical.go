@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// ErrNonCanonical indicates that the encoding is valid, but not in canonical form.
+var ErrNonCanonical = errors.NewStringError("non-canonical encoding")
+
+// VerifyCanonical verifies that all values until end of input are in canonical form. The canonical form is
+// the unique encoding that `WriteTo` produces for any value:
+//   - headers are minimal, i.e. a 2-byte header is only used for sizes that do not fit in a 1-byte header,
+//   - values are split only if necessary, i.e. non-terminated records are of maximum size
+//     (`SIZE_2BYTE_MAX`) and continuation records are never empty,
+//   - map-entries are ordered by key (byte-wise), and keys are unique.
+//
+// Returns `ErrNonCanonical` (with context) for valid encodings that are not in canonical form, and other
+// errors as would `Verify`.
+func VerifyCanonical(in io.Reader) error {
+	return Limits{}.VerifyCanonical(in)
+}
+
+// Canonicalize reads all values until end of input, and writes each value in canonical form. For
+// duplicate keys in a map-value, the last entry wins, as is the case for `ReadMap`.
+func Canonicalize(in io.Reader, out io.Writer) error {
+	return Limits{}.Canonicalize(in, out)
+}
+
+// header reads the next header, verifying that the header is minimal in case of canonical decoding.
+func (d *decoding) header() (Header, error) {
+	h, n, err := readHeader(d)
+	if err != nil {
+		return Header{}, err
+	}
+	if d.canonical && n == 2 && uint(h.Size) <= SIZE_1BYTE_MAX {
+		return Header{}, errors.Context(ErrNonCanonical, "2-byte header used for size that fits 1-byte header")
+	}
+	return h, nil
+}
+
+// record verifies, in case of canonical decoding, that the record (header) does not split a value
+// unnecessarily. `continued` indicates whether the record is a continuation record.
+func (d *decoding) record(h Header, continued bool) error {
+	if !d.canonical {
+		return nil
+	}
+	if !h.Terminated && uint(h.Size) != SIZE_2BYTE_MAX {
+		return errors.Context(ErrNonCanonical, "non-terminated record is not of maximum size")
+	}
+	if continued && h.Size == 0 {
+		return errors.Context(ErrNonCanonical, "empty continuation record")
+	}
+	return nil
+}
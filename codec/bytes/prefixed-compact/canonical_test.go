@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestMapWriteToDeterministic(t *testing.T) {
+	var m = MapValue{"b": Bytes("2"), "a": Bytes("1"), "c": Bytes("3"), "": Bytes{}}
+	var b1, b2 bytes.Buffer
+	_, err := m.WriteTo(&b1)
+	assert.Nil(t, err)
+	_, err = m.WriteTo(&b2)
+	assert.Nil(t, err)
+	assert.SlicesEqual(t, b1.Bytes(), b2.Bytes())
+	assert.SlicesEqual(t, []byte{4 | FLAG_TERMINATION | FLAG_MULTIPLICITY | FLAG_KEYVALUE,
+		0 | FLAG_TERMINATION | FLAG_KEYVALUE, 0 | FLAG_TERMINATION,
+		1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'a', 1 | FLAG_TERMINATION, '1',
+		1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'b', 1 | FLAG_TERMINATION, '2',
+		1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'c', 1 | FLAG_TERMINATION, '3'}, b1.Bytes())
+}
+
+func TestVerifyCanonicalWritten(t *testing.T) {
+	var large = make(MapValue, 5000)
+	for i := 0; i < 5000; i++ {
+		large[strconv.Itoa(i)] = Bytes{}
+	}
+	var seq = make(SequenceValue, 4096)
+	for i := range seq {
+		seq[i] = Bytes{}
+	}
+	var testdata = []Value{
+		Bytes{},
+		Bytes(make([]byte, 4096)),
+		Bytes(make([]byte, 8192)),
+		Bytes(make([]byte, 10000)),
+		&KeyValue{K: "key", V: SequenceValue{Bytes("a"), MapValue{"z": Bytes{}, "y": Bytes{}}}},
+		seq,
+		large,
+	}
+	for i, v := range testdata {
+		t.Log("Iteration:", i)
+		var b bytes.Buffer
+		_, err := v.WriteTo(&b)
+		assert.Nil(t, err)
+		assert.Nil(t, VerifyCanonical(&b))
+	}
+}
+
+func TestVerifyCanonicalRejects(t *testing.T) {
+	var testdata = [][]byte{
+		// 2-byte header for size that fits 1-byte header
+		{FLAG_TERMINATION | FLAG_HEADERSIZE, 0, 'a'},
+		// unnecessary split of bytes-value
+		{1, 'a', 1 | FLAG_TERMINATION, 'b'},
+		// empty non-terminated record
+		{0, 0 | FLAG_TERMINATION},
+		// map-keys out of order
+		{2 | FLAG_TERMINATION | FLAG_MULTIPLICITY | FLAG_KEYVALUE,
+			1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'b', 0 | FLAG_TERMINATION,
+			1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'a', 0 | FLAG_TERMINATION},
+		// duplicate map-keys
+		{2 | FLAG_TERMINATION | FLAG_MULTIPLICITY | FLAG_KEYVALUE,
+			1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'a', 0 | FLAG_TERMINATION,
+			1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'a', 0 | FLAG_TERMINATION},
+		// unnecessary split of sequence
+		{1 | FLAG_MULTIPLICITY, 0 | FLAG_TERMINATION, 0 | FLAG_TERMINATION | FLAG_MULTIPLICITY},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		assert.Nil(t, Verify(bytes.NewReader(d)))
+		assert.IsError(t, ErrNonCanonical, VerifyCanonical(bytes.NewReader(d)))
+	}
+}
+
+func TestVerifyCanonicalInvalid(t *testing.T) {
+	err := VerifyCanonical(bytes.NewReader([]byte{2 | FLAG_TERMINATION, 'a'}))
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestCanonicalize(t *testing.T) {
+	var ghost = []byte{0, 0, 0, 0, 1, 'g', 1, 'h', 1, 'o', 1, 's', 1, 't', 0, 0, 0 | FLAG_TERMINATION,
+		2 | FLAG_TERMINATION | FLAG_MULTIPLICITY | FLAG_KEYVALUE,
+		1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'b', 0 | FLAG_TERMINATION,
+		FLAG_TERMINATION | FLAG_KEYVALUE | FLAG_HEADERSIZE, 0, 'a', 0 | FLAG_TERMINATION}
+	assert.Nil(t, Verify(bytes.NewReader(ghost)))
+	var b bytes.Buffer
+	assert.Nil(t, Canonicalize(bytes.NewReader(ghost), &b))
+	assert.SlicesEqual(t, []byte{5 | FLAG_TERMINATION, 'g', 'h', 'o', 's', 't',
+		2 | FLAG_TERMINATION | FLAG_MULTIPLICITY | FLAG_KEYVALUE,
+		1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'a', 0 | FLAG_TERMINATION,
+		1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'b', 0 | FLAG_TERMINATION}, b.Bytes())
+	assert.Nil(t, VerifyCanonical(&b))
+}
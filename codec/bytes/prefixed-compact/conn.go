@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cobratbq/goutils/codec/bytes/bigendian"
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// Conn transfers whole values as messages over a connection, such as `net.Conn`, a unix socket or a pair of
+// pipes. Each message is framed by a 4-byte big-endian length prefix, such that a message that is rejected
+// can be skipped without decoding it. Conn is safe for concurrent use: concurrent sends and concurrent
+// receives are serialized.
+type Conn struct {
+	rw     io.ReadWriter
+	in     *bufio.Reader
+	limits Limits
+	rmu    sync.Mutex
+	wmu    sync.Mutex
+	closed atomic.Bool
+}
+
+// frameSizeMax is the maximum size of a message, as determined by the length prefix.
+const frameSizeMax = math.MaxUint32
+
+// NewConn creates a connection that transfers messages over `rw`. The limits apply to each received message
+// individually, i.e. `MaxInput` is the maximum message size. A message exceeding the limits results in
+// `ErrLimitExceeded`. The remainder of the message is skipped, such that the connection remains usable.
+// Messages sent are subject to `MaxInput` too.
+func NewConn(rw io.ReadWriter, limits Limits) *Conn {
+	return &Conn{rw: rw, in: bufio.NewReader(rw), limits: limits}
+}
+
+// Send sends the value as a single message. The message is encoded in full before writing, such that
+// encoding errors do not result in partial messages.
+func (c *Conn) Send(v Value) error {
+	var b bytes.Buffer
+	b.Write(make([]byte, 4))
+	if _, err := v.WriteTo(&b); err != nil {
+		return err
+	}
+	var size = uint(b.Len() - 4)
+	if c.limits.MaxInput > 0 && size > c.limits.MaxInput {
+		return errors.Context(ErrLimitExceeded, "message exceeds maximum message size")
+	}
+	if size > frameSizeMax {
+		return errors.Context(errors.ErrOverflow, "message exceeds maximum frame size")
+	}
+	var prefix = bigendian.FromUint32(uint32(size))
+	copy(b.Bytes(), prefix[:])
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed.Load() {
+		return errors.Context(errors.ErrInternalState, "connection is closed")
+	}
+	_, err := c.rw.Write(b.Bytes())
+	return err
+}
+
+// Receive receives the next message. Returns `io.EOF` if the connection is closed in-between messages, and
+// `io.ErrUnexpectedEOF` if the connection is closed in the middle of a message. A message that exceeds the
+// limits, or that is not a single valid value, is skipped and results in an error, after which the next
+// message can be received.
+func (c *Conn) Receive() (Value, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	var prefix [4]byte
+	if _, err := io.ReadFull(c.in, prefix[:]); err != nil {
+		return nil, err
+	}
+	var size = bigendian.ToUint32(prefix[0], prefix[1], prefix[2], prefix[3])
+	var frame = io.LimitedReader{R: c.in, N: int64(size)}
+	if c.limits.MaxInput > 0 && uint(size) > c.limits.MaxInput {
+		return nil, skip(&frame, errors.Context(ErrLimitExceeded, "message exceeds maximum message size"))
+	}
+	v, err := c.limits.decoding(&frame).readValue(nil)
+	if err != nil {
+		return nil, skip(&frame, err)
+	}
+	if frame.N > 0 {
+		return nil, skip(&frame, errors.Context(errors.ErrIllegal, "message contains data after value"))
+	}
+	return v, nil
+}
+
+// skip discards the remainder of the frame, then returns `err`. Returns `io.ErrUnexpectedEOF` if the frame
+// is incomplete, or `errors.ErrIllegal` if `err` is caused by the message ending prematurely.
+func skip(frame *io.LimitedReader, err error) error {
+	var remaining = frame.N
+	if n, skipErr := io.Copy(io.Discard, frame); skipErr != nil {
+		return skipErr
+	} else if n < remaining {
+		return io.ErrUnexpectedEOF
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.Context(errors.ErrIllegal, "message ends before end of value")
+	}
+	return err
+}
+
+// SetDeadline sets the read and write deadlines. Returns `errors.ErrUnsupported` if the underlying
+// connection does not support deadlines.
+func (c *Conn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for receiving. Returns `errors.ErrUnsupported` if the underlying
+// connection does not support read deadlines.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	if conn, ok := c.rw.(interface{ SetReadDeadline(time.Time) error }); ok {
+		return conn.SetReadDeadline(t)
+	}
+	return errors.Context(errors.ErrUnsupported, "connection does not support read deadlines")
+}
+
+// SetWriteDeadline sets the deadline for sending. Returns `errors.ErrUnsupported` if the underlying
+// connection does not support write deadlines.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	if conn, ok := c.rw.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return conn.SetWriteDeadline(t)
+	}
+	return errors.Context(errors.ErrUnsupported, "connection does not support write deadlines")
+}
+
+// Close closes the connection. Subsequent sends fail. The underlying connection is closed if it implements
+// `io.Closer`, which unblocks pending sends and receives. Close waits for a pending send to finish.
+func (c *Conn) Close() error {
+	if c.closed.Swap(true) {
+		return errors.Context(errors.ErrInternalState, "connection is closed")
+	}
+	var err error
+	if closer, ok := c.rw.(io.Closer); ok {
+		err = closer.Close()
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return err
+}
+
+// Correlator correlates requests with responses over a connection, for use with protocols that allow
+// multiple outstanding requests. Requests and responses are map-values that carry an identifier at a
+// designated key. The correlator assigns identifiers to requests, and dispatches received messages to the
+// request with the corresponding identifier. Identifiers are unsigned 64-bit integers, encoded as 8-byte
+// big-endian bytes-value.
+//
+// The correlator receives all messages on the connection, in a separate goroutine. Messages that do not
+// correspond to an outstanding request are passed to the `unmatched`-function, if provided. Messages that
+// are rejected, e.g. for exceeding the limits, are skipped.
+type Correlator struct {
+	conn      *Conn
+	key       string
+	unmatched func(Value)
+	mu        sync.Mutex
+	next      uint64
+	pending   map[uint64]chan response
+	err       error
+	done      chan struct{}
+}
+
+type response struct {
+	msg MapValue
+	err error
+}
+
+// NewCorrelator creates a correlator that uses `key` to carry the identifier. The correlator immediately
+// starts receiving messages from `conn`. `unmatched` may be nil.
+func NewCorrelator(conn *Conn, key string, unmatched func(Value)) *Correlator {
+	var c = Correlator{conn: conn, key: key, unmatched: unmatched, pending: make(map[uint64]chan response),
+		done: make(chan struct{})}
+	go c.receive()
+	return &c
+}
+
+// Request sends the request, after setting its identifier, and waits for the response or cancellation of
+// the context. `msg` itself is not modified. If receiving fails, all outstanding requests fail with the
+// receive error.
+func (c *Correlator) Request(ctx context.Context, msg MapValue) (MapValue, error) {
+	var ch = make(chan response, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	var id = c.next
+	c.next++
+	c.pending[id] = ch
+	c.mu.Unlock()
+	var request = make(MapValue, len(msg)+1)
+	for k, v := range msg {
+		request[k] = v
+	}
+	var encoded = bigendian.FromUint64(id)
+	request[c.key] = Bytes(encoded[:])
+	if err := c.conn.Send(request); err != nil {
+		c.cancel(id)
+		return nil, err
+	}
+	select {
+	case r := <-ch:
+		return r.msg, r.err
+	case <-ctx.Done():
+		c.cancel(id)
+		return nil, ctx.Err()
+	}
+}
+
+// Close closes the connection. Outstanding requests fail. If the underlying connection implements
+// `io.Closer`, Close waits for the receiving goroutine to finish. Otherwise, the receiving goroutine finishes
+// only once the underlying connection ends.
+func (c *Correlator) Close() error {
+	err := c.conn.Close()
+	c.fail(errors.Context(errors.ErrInternalState, "correlator is closed"))
+	if _, ok := c.conn.rw.(io.Closer); ok {
+		<-c.done
+	}
+	return err
+}
+
+func (c *Correlator) cancel(id uint64) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// fail fails all outstanding requests and subsequent requests with `err`, unless failed already.
+func (c *Correlator) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	for id, ch := range c.pending {
+		ch <- response{err: err}
+		delete(c.pending, id)
+	}
+}
+
+func (c *Correlator) receive() {
+	defer close(c.done)
+	for {
+		v, err := c.conn.Receive()
+		if errors.Is(err, ErrLimitExceeded) || errors.Is(err, errors.ErrIllegal) {
+			// The message is skipped, therefore receiving can continue.
+			continue
+		} else if err != nil {
+			c.fail(err)
+			return
+		}
+		if ch := c.match(v); ch != nil {
+			ch <- response{msg: v.(MapValue)}
+		} else if c.unmatched != nil {
+			c.unmatched(v)
+		}
+	}
+}
+
+// match returns the channel of the outstanding request that corresponds to the message, if any.
+func (c *Correlator) match(v Value) chan response {
+	msg, ok := v.(MapValue)
+	if !ok {
+		return nil
+	}
+	id, ok := msg[c.key].(Bytes)
+	if !ok || len(id) != 8 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var key = bigendian.ToUint64(id[0], id[1], id[2], id[3], id[4], id[5], id[6], id[7])
+	ch := c.pending[key]
+	delete(c.pending, key)
+	return ch
+}
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestConnSendReceive(t *testing.T) {
+	a, b := net.Pipe()
+	client, server := NewConn(a, Limits{}), NewConn(b, Limits{})
+	var messages = []Value{Bytes("hello"), selectDocument, SequenceValue{}}
+	go func() {
+		for _, m := range messages {
+			assert.Nil(t, client.Send(m))
+		}
+		assert.Nil(t, client.Close())
+	}()
+	for _, m := range messages {
+		v, err := server.Receive()
+		assert.Nil(t, err)
+		assert.True(t, m.Equal(v))
+	}
+	_, err := server.Receive()
+	assert.IsError(t, io.EOF, err)
+	assert.IsError(t, errors.ErrInternalState, client.Send(Bytes{}))
+	assert.Nil(t, server.Close())
+}
+
+func TestConnMaxMessageSize(t *testing.T) {
+	a, b := net.Pipe()
+	client, server := NewConn(a, Limits{}), NewConn(b, Limits{MaxInput: 16})
+	defer client.Close()
+	defer server.Close()
+	assert.IsError(t, ErrLimitExceeded, server.Send(Bytes(make([]byte, 16))))
+	go func() {
+		_ = client.Send(Bytes(make([]byte, 15)))
+		_ = client.Send(Bytes(make([]byte, 16)))
+		_ = client.Send(Bytes("next"))
+	}()
+	v, err := server.Receive()
+	assert.Nil(t, err)
+	assert.Equal(t, 15, v.Len())
+	_, err = server.Receive()
+	assert.IsError(t, ErrLimitExceeded, err)
+	// the oversized message is skipped, such that the connection remains usable
+	v, err = server.Receive()
+	assert.Nil(t, err)
+	assert.True(t, Bytes("next").Equal(v))
+}
+
+func TestConnReceiveIllegalMessage(t *testing.T) {
+	var b bytes.Buffer
+	// trailing data after the value
+	b.Write([]byte{0, 0, 0, 3, 1 | FLAG_TERMINATION, 'a', 'b'})
+	// message ends before end of value
+	b.Write([]byte{0, 0, 0, 2, 2 | FLAG_TERMINATION, 'a'})
+	// empty message
+	b.Write([]byte{0, 0, 0, 0})
+	b.Write([]byte{0, 0, 0, 2, 1 | FLAG_TERMINATION, 'c'})
+	// message ends before the end of its frame
+	b.Write([]byte{0, 0, 0, 2, 1 | FLAG_TERMINATION})
+	var c = NewConn(&b, Limits{})
+	for i := 0; i < 3; i++ {
+		_, err := c.Receive()
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+	v, err := c.Receive()
+	assert.Nil(t, err)
+	assert.True(t, Bytes("c").Equal(v))
+	_, err = c.Receive()
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+	_, err = c.Receive()
+	assert.IsError(t, io.EOF, err)
+}
+
+func TestConnCloseUnblocksSend(t *testing.T) {
+	a, b := net.Pipe()
+	defer b.Close()
+	client := NewConn(a, Limits{})
+	var result = make(chan error, 1)
+	go func() {
+		// nobody receives, therefore sending blocks
+		result <- client.Send(Bytes("blocked"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, client.Close())
+	assert.NotNil(t, <-result)
+}
+
+func TestConnDeadline(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	server := NewConn(b, Limits{})
+	defer server.Close()
+	assert.Nil(t, server.SetDeadline(time.Now().Add(10*time.Millisecond)))
+	_, err := server.Receive()
+	var timeout net.Error
+	assert.True(t, errors.As(err, &timeout) && timeout.Timeout())
+}
+
+func TestConnDeadlineUnsupported(t *testing.T) {
+	var c = NewConn(&bytes.Buffer{}, Limits{})
+	assert.IsError(t, errors.ErrUnsupported, c.SetDeadline(time.Now()))
+	assert.IsError(t, errors.ErrUnsupported, c.SetWriteDeadline(time.Now()))
+	assert.Nil(t, c.Close())
+}
+
+func TestCorrelator(t *testing.T) {
+	a, b := net.Pipe()
+	var unmatched = make(chan Value, 1)
+	client := NewCorrelator(NewConn(a, Limits{}), "id", func(v Value) { unmatched <- v })
+	server := NewConn(b, Limits{})
+	go func() {
+		// respond to requests in reverse order, preceded by an unsolicited message
+		var requests []MapValue
+		for i := 0; i < 3; i++ {
+			v, err := server.Receive()
+			if err != nil {
+				return
+			}
+			requests = append(requests, v.(MapValue))
+		}
+		_ = server.Send(Bytes("unsolicited"))
+		for i := len(requests) - 1; i >= 0; i-- {
+			_ = server.Send(MapValue{"id": requests[i]["id"], "echo": requests[i]["msg"]})
+		}
+	}()
+	var results = make(chan error, 3)
+	for _, msg := range []string{"a", "b", "c"} {
+		go func(msg string) {
+			response, err := client.Request(context.Background(), MapValue{"msg": Bytes(msg)})
+			if err == nil && !Bytes(msg).Equal(response["echo"]) {
+				err = errors.ErrIllegal
+			}
+			results <- err
+		}(msg)
+	}
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, <-results)
+	}
+	assert.True(t, Bytes("unsolicited").Equal(<-unmatched))
+	assert.Nil(t, server.Close())
+	assert.Nil(t, client.Close())
+	_, err := client.Request(context.Background(), MapValue{})
+	assert.NotNil(t, err)
+}
+
+func TestCorrelatorCloseNonCloser(t *testing.T) {
+	// the connection does not implement io.Closer, therefore closing does not end receiving
+	in, out := io.Pipe()
+	client := NewCorrelator(NewConn(struct {
+		io.Reader
+		io.Writer
+	}{in, io.Discard}, Limits{}), "id", nil)
+	var result = make(chan error, 1)
+	go func() {
+		_, err := client.Request(context.Background(), MapValue{})
+		result <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, client.Close())
+	assert.IsError(t, errors.ErrInternalState, <-result)
+	_, err := client.Request(context.Background(), MapValue{})
+	assert.IsError(t, errors.ErrInternalState, err)
+	assert.Nil(t, out.Close())
+	<-client.done
+}
+
+func TestCorrelatorCancel(t *testing.T) {
+	a, b := net.Pipe()
+	client := NewCorrelator(NewConn(a, Limits{}), "id", nil)
+	server := NewConn(b, Limits{})
+	go func() {
+		_, _ = server.Receive()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.Request(ctx, MapValue{})
+	assert.IsError(t, context.DeadlineExceeded, err)
+	assert.Nil(t, client.Close())
+	assert.Nil(t, server.Close())
+}
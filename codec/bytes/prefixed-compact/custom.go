@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// PrefixedEncoder is implemented by types that provide their own prefixed-compact representation. The
+// representation is picked up by `WriteSequenceOf`, `WriteMapOf` and `Marshal`.
+type PrefixedEncoder interface {
+	// PrefixedEncode produces the `Value` that represents the type.
+	PrefixedEncode() (Value, error)
+}
+
+// PrefixedDecoder is implemented by types that reconstruct themselves from their prefixed-compact
+// representation. The representation is picked up by `ReadInto`, `ParseInto` and `Unmarshal`.
+type PrefixedDecoder interface {
+	// PrefixedType returns the composite type that the implementation expects to decode.
+	PrefixedType() CompositeType
+	// PrefixedDecode decodes the provided value. The value is guaranteed to be of the expected type.
+	PrefixedDecode(v Value) error
+}
+
+// ReadInto reads a value and decodes it into `dst`. The header of the value is verified against the type
+// that `dst` expects, before the value itself is read.
+func ReadInto(in io.Reader, dst PrefixedDecoder) error {
+	var h Header
+	var err error
+	if h, err = ReadHeader(in); err != nil {
+		return err
+	}
+	if h.Vtype != dst.PrefixedType() {
+		return errors.Context(errors.ErrIllegal, "expected "+dst.PrefixedType().String()+"-value, got header for "+
+			h.Vtype.String()+"-value")
+	}
+	var v Value
+	if v, err = (Limits{}).decoding(in).readValue(&h); err != nil {
+		return err
+	}
+	return dst.PrefixedDecode(v)
+}
+
+// ParseInto reads a value from input-data and decodes it into `dst`. See `ReadInto`.
+// Returns the number of bytes read from `data`, which is only meaningful in the absence of errors.
+func ParseInto(data []byte, dst PrefixedDecoder) (uint, error) {
+	var in = bytes.NewReader(data)
+	if err := ReadInto(in, dst); err != nil {
+		return 0, err
+	}
+	return uint(in.Size() - int64(in.Len())), nil
+}
+
+// decodeInto decodes an already read value into `dst`, verifying that its type matches expectations.
+func decodeInto(v Value, dst PrefixedDecoder) error {
+	if typeOf(v) != dst.PrefixedType() {
+		return errors.Context(errors.ErrIllegal, "expected "+dst.PrefixedType().String()+"-value, got "+
+			typeOf(v).String()+"-value")
+	}
+	return dst.PrefixedDecode(v)
+}
+
+// valueOf acquires the prefixed-compact representation of `e`, either as `Value` or by encoding a
+// `PrefixedEncoder`. Both nil and typed nil-pointers are rejected, before any method is called.
+func valueOf(e any) (Value, error) {
+	if e == nil {
+		return nil, errors.Context(errors.ErrIllegal, "nil cannot be represented")
+	}
+	if rv := reflect.ValueOf(e); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, errors.Context(errors.ErrIllegal, "nil-pointer cannot be represented")
+	}
+	switch v := e.(type) {
+	case PrefixedEncoder:
+		return v.PrefixedEncode()
+	case Value:
+		return v, nil
+	default:
+		return nil, errors.Context(errors.ErrUnsupported, "value is neither Value nor PrefixedEncoder")
+	}
+}
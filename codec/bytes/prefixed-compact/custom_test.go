@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+type testPoint struct {
+	X, Y uint8
+}
+
+func (p testPoint) PrefixedEncode() (Value, error) {
+	return Bytes{p.X, p.Y}, nil
+}
+
+func (p *testPoint) PrefixedType() CompositeType {
+	return TYPE_BYTES
+}
+
+func (p *testPoint) PrefixedDecode(v Value) error {
+	b := v.(Bytes)
+	if len(b) != 2 {
+		return errors.Context(errors.ErrIllegal, "expected 2 bytes")
+	}
+	p.X, p.Y = b[0], b[1]
+	return nil
+}
+
+func TestWriteSequencePrefixedEncoder(t *testing.T) {
+	var b bytes.Buffer
+	_, err := WriteSequenceOf(&b, []testPoint{{1, 2}, {3, 4}})
+	assert.Nil(t, err)
+	v, err := ReadValue(&b)
+	assert.Nil(t, err)
+	assert.EqualT[Value](t, SequenceValue{Bytes{1, 2}, Bytes{3, 4}}, v)
+}
+
+func TestWriteMapPrefixedEncoder(t *testing.T) {
+	var b bytes.Buffer
+	_, err := WriteMapOf(&b, map[string]any{"p": testPoint{5, 6}, "raw": Bytes("x")})
+	assert.Nil(t, err)
+	v, err := ReadValue(&b)
+	assert.Nil(t, err)
+	assert.EqualT[Value](t, MapValue{"p": Bytes{5, 6}, "raw": Bytes("x")}, v)
+}
+
+func TestWriteMapUnsupported(t *testing.T) {
+	var b bytes.Buffer
+	_, err := WriteMapOf(&b, map[string]any{"x": 3})
+	assert.IsError(t, errors.ErrUnsupported, err)
+	assert.Equal(t, 0, b.Len())
+}
+
+func TestWriteSequenceNilPointer(t *testing.T) {
+	var b bytes.Buffer
+	_, err := WriteSequenceOf(&b, []*testPoint{{1, 2}, nil})
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = WriteMapOf(&b, map[string]any{"x": nil})
+	assert.IsError(t, errors.ErrIllegal, err)
+	assert.Equal(t, 0, b.Len())
+}
+
+func TestDecodeIntoCustomValue(t *testing.T) {
+	var p testPoint
+	assert.IsError(t, errors.ErrIllegal, decodeInto(customValue{Bytes{1, 2}}, &p))
+}
+
+func TestReadIntoPrefixedDecoder(t *testing.T) {
+	var p testPoint
+	n, err := ParseInto([]byte{2 | FLAG_TERMINATION, 7, 8}, &p)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, testPoint{7, 8}, p)
+}
+
+func TestReadIntoPrefixedDecoderWrongType(t *testing.T) {
+	var p testPoint
+	var b bytes.Buffer
+	_, err := WriteSequence(&b, []Value{Bytes{1}, Bytes{2}})
+	assert.Nil(t, err)
+	err = ReadInto(&b, &p)
+	assert.IsError(t, errors.ErrIllegal, err)
+	// only the header is consumed before rejecting
+	assert.Equal(t, 4, b.Len())
+}
+
+func TestMarshalPrefixedEncoderDecoder(t *testing.T) {
+	type shape struct {
+		Origin testPoint
+		Corner *testPoint
+		Points []testPoint
+	}
+	data, err := Marshal(shape{Origin: testPoint{1, 1}, Corner: &testPoint{2, 2}, Points: []testPoint{{3, 3}}})
+	assert.Nil(t, err)
+	_, v := ParseValue(data)
+	assert.EqualT[Value](t, MapValue{"Origin": Bytes{1, 1}, "Corner": Bytes{2, 2},
+		"Points": SequenceValue{Bytes{3, 3}}}, v)
+	var result shape
+	assert.Nil(t, Unmarshal(data, &result))
+	assert.Equal(t, testPoint{1, 1}, result.Origin)
+	assert.Equal(t, testPoint{2, 2}, *result.Corner)
+	assert.Equal(t, 1, len(result.Points))
+	assert.Equal(t, testPoint{3, 3}, result.Points[0])
+}
+
+func TestUnmarshalPrefixedDecoderWrongType(t *testing.T) {
+	data, err := Marshal(map[string]string{"Origin": "abc"})
+	assert.Nil(t, err)
+	var result struct{ Origin testPoint }
+	assert.IsError(t, errors.ErrIllegal, Unmarshal(data, &result))
+	data, err = Marshal(map[string][]string{"Origin": {"a", "b"}})
+	assert.Nil(t, err)
+	assert.IsError(t, errors.ErrIllegal, Unmarshal(data, &result))
+}
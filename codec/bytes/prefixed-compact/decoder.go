@@ -0,0 +1,338 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// TokenKind indicates the kind of token produced by the streaming decoder.
+type TokenKind uint8
+
+const (
+	// TOKEN_START_SEQUENCE starts a sequence-value. Its entries follow as values.
+	TOKEN_START_SEQUENCE TokenKind = iota
+	// TOKEN_START_MAP starts a map-value. Its entries follow as key-tokens, each followed by a value.
+	TOKEN_START_MAP
+	// TOKEN_KEY is a chunk of a key, either for a key-value-pair or a map-entry. The value follows after the
+	// terminated chunk.
+	TOKEN_KEY
+	// TOKEN_BYTES is a chunk of a bytes-value.
+	TOKEN_BYTES
+	// TOKEN_END ends the most recently started sequence-value or map-value.
+	TOKEN_END
+)
+
+// String returns a human-readable name for the token kind.
+func (k TokenKind) String() string {
+	switch k {
+	case TOKEN_START_SEQUENCE:
+		return "start-sequence"
+	case TOKEN_START_MAP:
+		return "start-map"
+	case TOKEN_KEY:
+		return "key"
+	case TOKEN_BYTES:
+		return "bytes"
+	case TOKEN_END:
+		return "end"
+	default:
+		return "unknown"
+	}
+}
+
+// Token is a single element in the stream of prefixed-compact encoded values.
+type Token struct {
+	Kind TokenKind
+	// Count is the number of entries, for sequences and maps. Count reflects only the first record. If the
+	// record is not terminated, continuation records with additional entries follow.
+	Count int
+	// Data is the chunk of bytes, for keys and bytes-values. Data is only valid until the next call to the
+	// decoder, as the underlying buffer is reused.
+	Data []byte
+	// Terminated indicates whether this is the final chunk of a key or bytes-value, or the final record of a
+	// sequence or map.
+	Terminated bool
+}
+
+// frame is the decoding-state for an (unfinished) composite value.
+type frame struct {
+	// vtype is one of TYPE_SEQUENCE, TYPE_MAP, or TYPE_KEYVALUE (awaiting value).
+	vtype      CompositeType
+	remaining  uint16
+	terminated bool
+	// entry indicates that the key-value-pair is a map-entry, therefore does not add a level of nesting.
+	entry bool
+}
+
+// Decoder reads prefixed-compact encoded values from the underlying reader. Values can be read whole, into Go
+// values using `Decode`, or streamed as tokens using `Next`. Streaming tokens allows processing arbitrarily
+// large values in constant memory. Both may be mixed: `Decode` and `Skip` process the next value in the
+// stream, e.g. the value following a key-token.
+//
+// The decoder honors `Limits` if created with `Limits.NewDecoder`.
+type Decoder struct {
+	in      *decoding
+	stack   []frame
+	pending *Header
+	total   uint
+	buffer  [SIZE_2BYTE_MAX]byte
+}
+
+// NewDecoder creates a new decoder that reads from `in`.
+func NewDecoder(in io.Reader) *Decoder {
+	return Limits{}.NewDecoder(in)
+}
+
+// Decode reads the next encoded value and unmarshals it into the value pointed to by `v`. See `Unmarshal`.
+func (d *Decoder) Decode(v any) error {
+	if err := d.prepare(); err != nil {
+		return err
+	}
+	var val Value
+	var err error
+	if len(d.stack) > 0 && d.stack[len(d.stack)-1].vtype == TYPE_MAP {
+		var h Header
+		if h, err = d.entryHeader(); err == nil {
+			val, err = d.in.readEntry(h)
+		}
+	} else {
+		val, err = d.in.readValue(nil)
+	}
+	if err != nil {
+		return d.unexpectedEOF(err)
+	}
+	d.complete()
+	return UnmarshalValue(val, v)
+}
+
+// Skip discards the next value without decoding it, as if all its tokens were read. Skip cannot be used
+// while a key or bytes-value is partially read.
+func (d *Decoder) Skip() error {
+	if err := d.prepare(); err != nil {
+		return err
+	}
+	var err error
+	if len(d.stack) > 0 && d.stack[len(d.stack)-1].vtype == TYPE_MAP {
+		var h Header
+		if h, err = d.entryHeader(); err == nil {
+			err = d.in.verifyEntry(h)
+		}
+	} else {
+		err = d.in.verifyValue()
+	}
+	if err != nil {
+		return d.unexpectedEOF(err)
+	}
+	d.complete()
+	return nil
+}
+
+// More reports whether another entry follows in the current sequence or map, reading continuation headers
+// as needed. If More reports false, the next token is TOKEN_END. Inside a key-value-pair, and in-between
+// top-level values, More reports true, as a value is expected or may follow.
+func (d *Decoder) More() (bool, error) {
+	if d.pending != nil {
+		return false, errors.Context(errors.ErrInternalState, "key or bytes-value is partially read")
+	}
+	if err := d.continuation(); err != nil {
+		return false, err
+	}
+	if len(d.stack) == 0 {
+		return true, nil
+	}
+	var top = d.stack[len(d.stack)-1]
+	return top.vtype == TYPE_KEYVALUE || top.remaining > 0, nil
+}
+
+// Depth returns the number of unfinished sequences, maps and key-value-pairs.
+func (d *Decoder) Depth() int {
+	return len(d.stack)
+}
+
+// Next reads the next token. At the end of input, in-between top-level values, `io.EOF` is returned.
+// Continuation records are processed transparently: keys and bytes-values are produced in chunks, one per
+// record, while for sequences and maps the continuation headers are consumed silently.
+func (d *Decoder) Next() (Token, error) {
+	if d.pending != nil {
+		return d.nextChunk()
+	}
+	var err error
+	if err = d.continuation(); err != nil {
+		return Token{}, err
+	}
+	if len(d.stack) > 0 {
+		if top := d.stack[len(d.stack)-1]; top.vtype != TYPE_KEYVALUE && top.remaining == 0 {
+			d.stack = d.stack[:len(d.stack)-1]
+			d.in.leave()
+			d.complete()
+			return Token{Kind: TOKEN_END, Terminated: true}, nil
+		}
+	}
+	var h Header
+	if len(d.stack) > 0 && d.stack[len(d.stack)-1].vtype == TYPE_MAP {
+		h, err = d.entryHeader()
+	} else {
+		h, err = d.valueHeader()
+	}
+	if err != nil {
+		return Token{}, d.unexpectedEOF(err)
+	}
+	switch h.Vtype {
+	case TYPE_BYTES, TYPE_KEYVALUE:
+		d.pending, d.total = &h, 0
+		return d.nextChunk()
+	case TYPE_SEQUENCE:
+		if err = d.in.enter(); err != nil {
+			return Token{}, err
+		}
+		d.stack = append(d.stack, frame{vtype: TYPE_SEQUENCE, remaining: h.Size, terminated: h.Terminated})
+		return Token{Kind: TOKEN_START_SEQUENCE, Count: int(h.Size), Terminated: h.Terminated}, nil
+	case TYPE_MAP:
+		if err = d.in.enter(); err != nil {
+			return Token{}, err
+		}
+		d.stack = append(d.stack, frame{vtype: TYPE_MAP, remaining: h.Size, terminated: h.Terminated})
+		return Token{Kind: TOKEN_START_MAP, Count: int(h.Size), Terminated: h.Terminated}, nil
+	default:
+		panic("BUG: should not be reached")
+	}
+}
+
+// nextChunk reads the data of the pending key or bytes-value record. For non-terminated records, the
+// continuation header is read ahead, such that the type of the continuation is verified immediately.
+func (d *Decoder) nextChunk() (Token, error) {
+	var h = *d.pending
+	var err error
+	if err = d.in.size(d.total, h.Size); err != nil {
+		return Token{}, err
+	}
+	d.total += uint(h.Size)
+	var data = d.buffer[:h.Size]
+	if _, err = io.ReadFull(d.in, data); err != nil {
+		return Token{}, d.unexpectedEOF(err)
+	}
+	var kind = TOKEN_BYTES
+	if h.Vtype == TYPE_KEYVALUE {
+		kind = TOKEN_KEY
+	}
+	if !h.Terminated {
+		var next Header
+		if next, err = ReadHeader(d.in); err != nil {
+			return Token{}, d.unexpectedEOF(err)
+		}
+		if next.Vtype != h.Vtype {
+			return Token{}, errors.Context(errors.ErrIllegal, "continuation of "+h.Vtype.String()+
+				"-value has different type: "+next.Vtype.String())
+		}
+		*d.pending = next
+		return Token{Kind: kind, Data: data, Terminated: false}, nil
+	}
+	d.pending = nil
+	if h.Vtype == TYPE_KEYVALUE {
+		var entry = len(d.stack) > 0 && d.stack[len(d.stack)-1].vtype == TYPE_MAP
+		if !entry {
+			if err = d.in.enter(); err != nil {
+				return Token{}, err
+			}
+		}
+		d.stack = append(d.stack, frame{vtype: TYPE_KEYVALUE, entry: entry})
+	} else {
+		d.complete()
+	}
+	return Token{Kind: kind, Data: data, Terminated: true}, nil
+}
+
+// continuation reads continuation headers for the current sequence or map, if its current record is
+// exhausted but not terminated.
+func (d *Decoder) continuation() error {
+	for len(d.stack) > 0 {
+		var top = &d.stack[len(d.stack)-1]
+		if top.vtype == TYPE_KEYVALUE || top.remaining > 0 || top.terminated {
+			return nil
+		}
+		h, err := ReadHeader(d.in)
+		if err != nil {
+			return d.unexpectedEOF(err)
+		}
+		if h.Vtype != top.vtype {
+			return errors.Context(errors.ErrIllegal, "continuation of "+top.vtype.String()+
+				"-value has different type: "+h.Vtype.String())
+		}
+		top.remaining, top.terminated = h.Size, h.Terminated
+	}
+	return nil
+}
+
+// prepare prepares for processing a whole value, failing if no value is next. Sequence-entries are
+// registered, while map-entries are registered upon reading the entry-header.
+func (d *Decoder) prepare() error {
+	if d.pending != nil {
+		return errors.Context(errors.ErrInternalState, "key or bytes-value is partially read")
+	}
+	if err := d.continuation(); err != nil {
+		return err
+	}
+	if len(d.stack) > 0 {
+		var top = d.stack[len(d.stack)-1]
+		if top.vtype != TYPE_KEYVALUE && top.remaining == 0 {
+			return errors.Context(errors.ErrInternalState, "no value remaining: end of "+top.vtype.String())
+		}
+		if top.vtype == TYPE_SEQUENCE {
+			return d.in.entry()
+		}
+	}
+	return nil
+}
+
+// complete registers the completion of a value, thereby completing parent key-value-pairs, or counting
+// towards the entries of the parent sequence or map.
+func (d *Decoder) complete() {
+	for len(d.stack) > 0 {
+		var top = &d.stack[len(d.stack)-1]
+		if top.vtype != TYPE_KEYVALUE {
+			top.remaining--
+			return
+		}
+		if !top.entry {
+			d.in.leave()
+		}
+		d.stack = d.stack[:len(d.stack)-1]
+	}
+}
+
+// valueHeader reads the header of the next value, registering an entry if inside a sequence.
+func (d *Decoder) valueHeader() (Header, error) {
+	if len(d.stack) > 0 && d.stack[len(d.stack)-1].vtype == TYPE_SEQUENCE {
+		if err := d.in.entry(); err != nil {
+			return Header{}, err
+		}
+	}
+	return ReadHeader(d.in)
+}
+
+// entryHeader reads the header of the next map-entry, registering the entry.
+func (d *Decoder) entryHeader() (Header, error) {
+	if err := d.in.entry(); err != nil {
+		return Header{}, err
+	}
+	h, err := ReadHeader(d.in)
+	if err != nil {
+		return Header{}, err
+	}
+	if h.Vtype != TYPE_KEYVALUE {
+		return Header{}, errors.Context(errors.ErrIllegal, "expected key-value-pair as map-entry, got "+
+			h.Vtype.String())
+	}
+	return h, nil
+}
+
+// unexpectedEOF converts `io.EOF` to `io.ErrUnexpectedEOF` if the decoder is in the middle of a value.
+func (d *Decoder) unexpectedEOF(err error) error {
+	if err == io.EOF && (len(d.stack) > 0 || d.pending != nil) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
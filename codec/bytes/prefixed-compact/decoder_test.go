@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func collectTokens(t *testing.T, d *Decoder) []Token {
+	var tokens []Token
+	for {
+		tok, err := d.Next()
+		if err == io.EOF {
+			return tokens
+		}
+		assert.Nil(t, err)
+		if err != nil {
+			t.FailNow()
+		}
+		tok.Data = bytes.Clone(tok.Data)
+		tokens = append(tokens, tok)
+	}
+}
+
+func TestDecoderNextEmpty(t *testing.T) {
+	d := NewDecoder(bytes.NewReader(nil))
+	_, err := d.Next()
+	assert.IsError(t, io.EOF, err)
+}
+
+func TestDecoderNextTokens(t *testing.T) {
+	var b bytes.Buffer
+	_, err := SequenceValue{Bytes("a"), &KeyValue{K: "id", V: Bytes("b")}, MapValue{"k": SequenceValue{}}}.WriteTo(&b)
+	assert.Nil(t, err)
+	_, err = Bytes("next").WriteTo(&b)
+	assert.Nil(t, err)
+	tokens := collectTokens(t, NewDecoder(&b))
+	expected := []Token{
+		{Kind: TOKEN_START_SEQUENCE, Count: 3, Terminated: true},
+		{Kind: TOKEN_BYTES, Data: []byte("a"), Terminated: true},
+		{Kind: TOKEN_KEY, Data: []byte("id"), Terminated: true},
+		{Kind: TOKEN_BYTES, Data: []byte("b"), Terminated: true},
+		{Kind: TOKEN_START_MAP, Count: 1, Terminated: true},
+		{Kind: TOKEN_KEY, Data: []byte("k"), Terminated: true},
+		{Kind: TOKEN_START_SEQUENCE, Count: 0, Terminated: true},
+		{Kind: TOKEN_END, Terminated: true},
+		{Kind: TOKEN_END, Terminated: true},
+		{Kind: TOKEN_END, Terminated: true},
+		{Kind: TOKEN_BYTES, Data: []byte("next"), Terminated: true},
+	}
+	assert.Equal(t, len(expected), len(tokens))
+	for i := range expected {
+		assert.Equal(t, expected[i].Kind, tokens[i].Kind)
+		assert.Equal(t, expected[i].Count, tokens[i].Count)
+		assert.Equal(t, expected[i].Terminated, tokens[i].Terminated)
+		assert.SlicesEqual(t, expected[i].Data, tokens[i].Data)
+	}
+}
+
+func TestDecoderNextChunked(t *testing.T) {
+	var data [10000]byte
+	for i := range data {
+		data[i] = byte(i)
+	}
+	var seq = make(SequenceValue, 5000)
+	for i := range seq {
+		seq[i] = Bytes{}
+	}
+	var b bytes.Buffer
+	_, err := SequenceValue{Bytes(data[:]), seq}.WriteTo(&b)
+	assert.Nil(t, err)
+	tokens := collectTokens(t, NewDecoder(&b))
+	assert.Equal(t, 1+3+1+5000+2, len(tokens))
+	assert.Equal(t, TOKEN_BYTES, tokens[1].Kind)
+	assert.Equal(t, 4096, len(tokens[1].Data))
+	assert.False(t, tokens[1].Terminated)
+	assert.Equal(t, 4096, len(tokens[2].Data))
+	assert.False(t, tokens[2].Terminated)
+	assert.Equal(t, 1808, len(tokens[3].Data))
+	assert.True(t, tokens[3].Terminated)
+	assert.SlicesEqual(t, data[:], bytes.Join([][]byte{tokens[1].Data, tokens[2].Data, tokens[3].Data}, nil))
+	assert.Equal(t, TOKEN_START_SEQUENCE, tokens[4].Kind)
+	assert.Equal(t, 4096, tokens[4].Count)
+	assert.False(t, tokens[4].Terminated)
+	assert.Equal(t, TOKEN_END, tokens[len(tokens)-2].Kind)
+	assert.Equal(t, TOKEN_END, tokens[len(tokens)-1].Kind)
+}
+
+func TestDecoderSkipAndDecode(t *testing.T) {
+	var b bytes.Buffer
+	_, err := WriteMap(&b, map[string]Value{"large": Bytes(make([]byte, 9000)), "name": Bytes("hello")})
+	assert.Nil(t, err)
+	d := NewDecoder(&b)
+	tok, err := d.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, TOKEN_START_MAP, tok.Kind)
+	var name string
+	for i := 0; i < tok.Count; i++ {
+		key, err := d.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, TOKEN_KEY, key.Kind)
+		if string(key.Data) == "name" {
+			assert.Nil(t, d.Decode(&name))
+		} else {
+			assert.Nil(t, d.Skip())
+		}
+	}
+	assert.Equal(t, "hello", name)
+	assert.IsError(t, errors.ErrInternalState, d.Skip())
+	tok, err = d.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, TOKEN_END, tok.Kind)
+	assert.Equal(t, 0, d.Depth())
+	_, err = d.Next()
+	assert.IsError(t, io.EOF, err)
+}
+
+func TestDecoderIllegalContinuation(t *testing.T) {
+	d := NewDecoder(bytes.NewReader([]byte{1, 'a', 1 | FLAG_TERMINATION | FLAG_KEYVALUE, 'b'}))
+	_, err := d.Next()
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestDecoderIllegalMapEntry(t *testing.T) {
+	d := NewDecoder(bytes.NewReader([]byte{1 | FLAG_TERMINATION | FLAG_KEYVALUE | FLAG_MULTIPLICITY, 1 | FLAG_TERMINATION, 'a'}))
+	_, err := d.Next()
+	assert.Nil(t, err)
+	_, err = d.Next()
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestDecoderUnexpectedEOF(t *testing.T) {
+	d := NewDecoder(bytes.NewReader([]byte{2 | FLAG_TERMINATION | FLAG_MULTIPLICITY, 0 | FLAG_TERMINATION}))
+	tokens := 0
+	var err error
+	for err == nil {
+		_, err = d.Next()
+		tokens++
+	}
+	assert.Equal(t, 3, tokens)
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestDecoderMore(t *testing.T) {
+	var seq = make(SequenceValue, 4100)
+	for i := range seq {
+		seq[i] = Bytes{}
+	}
+	var b bytes.Buffer
+	_, err := seq.WriteTo(&b)
+	assert.Nil(t, err)
+	d := NewDecoder(&b)
+	more, err := d.More()
+	assert.Nil(t, err)
+	assert.True(t, more)
+	tok, err := d.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, TOKEN_START_SEQUENCE, tok.Kind)
+	var count int
+	for {
+		more, err = d.More()
+		assert.Nil(t, err)
+		if !more {
+			break
+		}
+		assert.Nil(t, d.Skip())
+		count++
+	}
+	assert.Equal(t, 4100, count)
+	tok, err = d.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, TOKEN_END, tok.Kind)
+}
@@ -0,0 +1,358 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"sort"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// Op is the operation of a change.
+type Op uint8
+
+const (
+	// OP_ADD adds a map-entry, or inserts a sequence-entry before the indicated index. (An index equal to the
+	// length of the sequence appends.)
+	OP_ADD Op = iota
+	// OP_REMOVE removes a map-entry or sequence-entry.
+	OP_REMOVE
+	// OP_REPLACE replaces the value at the path.
+	OP_REPLACE
+)
+
+// String returns the name of the operation, which is also its encoded form.
+func (o Op) String() string {
+	switch o {
+	case OP_ADD:
+		return "add"
+	case OP_REMOVE:
+		return "remove"
+	case OP_REPLACE:
+		return "replace"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a single change to a value.
+type Change struct {
+	Op Op
+	// Path is the path of the changed value, in the notation of `Select`.
+	Path string
+	// Value is the new value, for OP_ADD and OP_REPLACE.
+	Value Value
+}
+
+// Changes is the list of changes that transforms one value into another. Changes must be applied in order,
+// as paths (i.e. sequence-indexes) are relative to the result of preceding changes. Changes implements
+// `PrefixedEncoder` and `PrefixedDecoder`, such that changes can be transferred. Changes are represented as a
+// sequence of maps with keys `op`, `path` and, if applicable, `value`.
+type Changes []Change
+
+var _ PrefixedEncoder = (Changes)(nil)
+var _ PrefixedDecoder = (*Changes)(nil)
+
+// PrefixedEncode encodes the changes.
+func (c Changes) PrefixedEncode() (Value, error) {
+	var seq = make(SequenceValue, 0, len(c))
+	for _, change := range c {
+		if change.Op > OP_REPLACE {
+			return nil, errors.Context(errors.ErrIllegal, "unknown operation")
+		}
+		var m = MapValue{"op": Bytes(change.Op.String()), "path": Bytes(change.Path)}
+		if change.Op != OP_REMOVE {
+			if change.Value == nil {
+				return nil, errors.Context(errors.ErrIllegal, "value missing for "+change.Op.String()+" at "+
+					change.Path)
+			}
+			m["value"] = change.Value
+		}
+		seq = append(seq, m)
+	}
+	return seq, nil
+}
+
+// PrefixedType returns the composite type of encoded changes.
+func (c *Changes) PrefixedType() CompositeType {
+	return TYPE_SEQUENCE
+}
+
+// PrefixedDecode decodes the changes.
+func (c *Changes) PrefixedDecode(v Value) error {
+	var seq = v.(SequenceValue)
+	var changes = make(Changes, 0, len(seq))
+	for _, e := range seq {
+		m, ok := e.(MapValue)
+		if !ok {
+			return errors.Context(errors.ErrIllegal, "expected map-value for change")
+		}
+		op, ok := m["op"].(Bytes)
+		if !ok {
+			return errors.Context(errors.ErrIllegal, "expected bytes-value for operation")
+		}
+		path, ok := m["path"].(Bytes)
+		if !ok {
+			return errors.Context(errors.ErrIllegal, "expected bytes-value for path")
+		}
+		var change = Change{Path: string(path), Value: m["value"]}
+		switch string(op) {
+		case "add":
+			change.Op = OP_ADD
+		case "remove":
+			change.Op = OP_REMOVE
+		case "replace":
+			change.Op = OP_REPLACE
+		default:
+			return errors.Context(errors.ErrIllegal, "unknown operation: "+string(op))
+		}
+		if (change.Op == OP_REMOVE) != (change.Value == nil) {
+			return errors.Context(errors.ErrIllegal, "value must be present, except for remove")
+		}
+		changes = append(changes, change)
+	}
+	*c = changes
+	return nil
+}
+
+// Diff determines the changes that transform `a` into `b`. Map-entries are compared per key, in order of
+// sorted keys. Sequences are compared by longest common subsequence, such that insertions and deletions are
+// recognized. Differing entries that are both removed and inserted at the same position are compared
+// recursively instead. Values of different type, bytes-values, and key-value-pairs with different keys are
+// replaced as a whole. Sequences that, after skipping common leading and trailing entries, are too large
+// for comparison within `diffTableMax` are replaced as a whole too. Nil indicates the absence of a value: a
+// nil-value is replaced, and a value is removed if `b` is nil.
+func Diff(a, b Value) Changes {
+	var changes Changes
+	diff(&changes, "", a, b)
+	return changes
+}
+
+func diff(changes *Changes, path string, a, b Value) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case b == nil:
+		*changes = append(*changes, Change{Op: OP_REMOVE, Path: path})
+		return
+	case a == nil:
+		*changes = append(*changes, Change{Op: OP_REPLACE, Path: path, Value: b})
+		return
+	}
+	switch va := a.(type) {
+	case *KeyValue:
+		if vb, ok := b.(*KeyValue); ok && va.K == vb.K {
+			diff(changes, path+segment{key: va.K}.String(), va.V, vb.V)
+			return
+		}
+	case SequenceValue:
+		if vb, ok := b.(SequenceValue); ok {
+			diffSequence(changes, path, va, vb)
+			return
+		}
+	case MapValue:
+		if vb, ok := b.(MapValue); ok {
+			diffMap(changes, path, va, vb)
+			return
+		}
+	}
+	if !a.Equal(b) {
+		*changes = append(*changes, Change{Op: OP_REPLACE, Path: path, Value: b})
+	}
+}
+
+func diffMap(changes *Changes, path string, a, b MapValue) {
+	var keys = make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var p = path + segment{key: k}.String()
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case !inB:
+			*changes = append(*changes, Change{Op: OP_REMOVE, Path: p})
+		case !inA:
+			*changes = append(*changes, Change{Op: OP_ADD, Path: p, Value: vb})
+		default:
+			diff(changes, p, va, vb)
+		}
+	}
+}
+
+// diffTableMax is the maximum number of cells of the table for determining the longest common subsequence,
+// which bounds the memory and processing needed for comparing sequences.
+const diffTableMax = 1 << 20
+
+func diffSequence(changes *Changes, path string, a, b SequenceValue) {
+	// Skip common leading and trailing entries, which are part of any longest common subsequence.
+	var prefix int
+	for prefix < len(a) && prefix < len(b) && a[prefix].Equal(b[prefix]) {
+		prefix++
+	}
+	var suffix int
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix].Equal(b[len(b)-1-suffix]) {
+		suffix++
+	}
+	var ta, tb = a[prefix : len(a)-suffix], b[prefix : len(b)-suffix]
+	var width = len(tb) + 1
+	if width > diffTableMax/(len(ta)+1) {
+		*changes = append(*changes, Change{Op: OP_REPLACE, Path: path, Value: b})
+		return
+	}
+	// lcs[i*width+j] is the length of the longest common subsequence of ta[i:] and tb[j:].
+	var lcs = make([]int, (len(ta)+1)*width)
+	for i := len(ta) - 1; i >= 0; i-- {
+		for j := len(tb) - 1; j >= 0; j-- {
+			if ta[i].Equal(tb[j]) {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+	// k is the index in the intermediate result, i.e. with preceding changes applied.
+	var i, j, k = 0, 0, prefix
+	for i < len(ta) || j < len(tb) {
+		if i < len(ta) && j < len(tb) && ta[i].Equal(tb[j]) {
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+		var removed, inserted []Value
+		for (i < len(ta) || j < len(tb)) && !(i < len(ta) && j < len(tb) && ta[i].Equal(tb[j])) {
+			if j >= len(tb) || (i < len(ta) && lcs[(i+1)*width+j] >= lcs[i*width+j+1]) {
+				removed, i = append(removed, ta[i]), i+1
+			} else {
+				inserted, j = append(inserted, tb[j]), j+1
+			}
+		}
+		var paired = min(len(removed), len(inserted))
+		for n := 0; n < paired; n, k = n+1, k+1 {
+			diff(changes, path+segment{index: k, indexed: true}.String(), removed[n], inserted[n])
+		}
+		for range removed[paired:] {
+			var p = path + segment{index: k, indexed: true}.String()
+			*changes = append(*changes, Change{Op: OP_REMOVE, Path: p})
+		}
+		for _, v := range inserted[paired:] {
+			var p = path + segment{index: k, indexed: true}.String()
+			*changes = append(*changes, Change{Op: OP_ADD, Path: p, Value: v})
+			k++
+		}
+	}
+}
+
+// Patch applies the changes to `a`, in order, producing the result. `a` itself is not modified, although
+// the result shares unchanged parts with `a`. Returns `ErrNotFound` (with context) if a path does not exist,
+// and `errors.ErrIllegal` (with context) if a change cannot be applied, e.g. adding an existing map-entry.
+func Patch(a Value, changes Changes) (Value, error) {
+	var err error
+	for _, c := range changes {
+		var segments []segment
+		if segments, err = parsePath(c.Path); err != nil {
+			return nil, err
+		}
+		if c.Op != OP_REMOVE && c.Value == nil {
+			return nil, errors.Context(errors.ErrIllegal, "value missing for "+c.Op.String()+" at "+c.Path)
+		}
+		if len(segments) == 0 {
+			if c.Op == OP_ADD {
+				return nil, errors.Context(errors.ErrIllegal, "only replace or remove is possible for root-value")
+			}
+			// Removing the root-value results in nil.
+			a = c.Value
+			continue
+		}
+		if a, err = patch(a, segments, c); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// patch applies the change at `segments`, relative to `v`, copying the values along the path.
+func patch(v Value, segments []segment, c Change) (Value, error) {
+	var s = segments[0]
+	var last = len(segments) == 1
+	switch val := v.(type) {
+	case *KeyValue:
+		if s.indexed || val.K != s.key {
+			return nil, errors.Context(ErrNotFound, "no value at "+c.Path)
+		}
+		if !last {
+			inner, err := patch(val.V, segments[1:], c)
+			if err != nil {
+				return nil, err
+			}
+			return &KeyValue{K: val.K, V: inner}, nil
+		}
+		if c.Op != OP_REPLACE {
+			return nil, errors.Context(errors.ErrIllegal, "only replace is possible for value of key-value-pair")
+		}
+		return &KeyValue{K: val.K, V: c.Value}, nil
+	case SequenceValue:
+		if !s.indexed || s.index > len(val) || (s.index == len(val) && !(last && c.Op == OP_ADD)) {
+			return nil, errors.Context(ErrNotFound, "no value at "+c.Path)
+		}
+		var result = make(SequenceValue, 0, len(val)+1)
+		result = append(result, val[:s.index]...)
+		switch {
+		case !last:
+			inner, err := patch(val[s.index], segments[1:], c)
+			if err != nil {
+				return nil, err
+			}
+			result = append(append(result, inner), val[s.index+1:]...)
+		case c.Op == OP_ADD:
+			result = append(append(result, c.Value), val[s.index:]...)
+		case c.Op == OP_REMOVE:
+			result = append(result, val[s.index+1:]...)
+		case c.Op == OP_REPLACE:
+			result = append(append(result, c.Value), val[s.index+1:]...)
+		default:
+			return nil, errors.Context(errors.ErrIllegal, "unknown operation")
+		}
+		return result, nil
+	case MapValue:
+		if s.indexed {
+			return nil, errors.Context(ErrNotFound, "no value at "+c.Path)
+		}
+		entry, ok := val[s.key]
+		if !ok && !(last && c.Op == OP_ADD) {
+			return nil, errors.Context(ErrNotFound, "no value at "+c.Path)
+		}
+		var result = make(MapValue, len(val)+1)
+		for k, e := range val {
+			result[k] = e
+		}
+		switch {
+		case !last:
+			inner, err := patch(entry, segments[1:], c)
+			if err != nil {
+				return nil, err
+			}
+			result[s.key] = inner
+		case c.Op == OP_ADD:
+			if ok {
+				return nil, errors.Context(errors.ErrIllegal, "map-entry already exists: "+s.String())
+			}
+			result[s.key] = c.Value
+		case c.Op == OP_REMOVE:
+			delete(result, s.key)
+		case c.Op == OP_REPLACE:
+			result[s.key] = c.Value
+		default:
+			return nil, errors.Context(errors.ErrIllegal, "unknown operation")
+		}
+		return result, nil
+	default:
+		return nil, errors.Context(ErrNotFound, "no value at "+c.Path)
+	}
+}
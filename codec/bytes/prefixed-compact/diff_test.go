@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestDiffEqual(t *testing.T) {
+	assert.Equal(t, 0, len(Diff(selectDocument, selectDocument)))
+}
+
+func TestDiff(t *testing.T) {
+	var a = MapValue{
+		"name":    Bytes("alpha"),
+		"removed": Bytes{},
+		"servers": SequenceValue{Bytes("a"), Bytes("b"), Bytes("c"), Bytes("d")},
+		"id":      &KeyValue{K: "v1", V: Bytes{1}},
+	}
+	var b = MapValue{
+		"name":    Bytes("beta"),
+		"added":   SequenceValue{},
+		"servers": SequenceValue{Bytes("x"), Bytes("a"), Bytes("c"), Bytes("D"), Bytes("e")},
+		"id":      &KeyValue{K: "v1", V: Bytes{2}},
+	}
+	var changes = Diff(a, b)
+	var expected = Changes{
+		{Op: OP_ADD, Path: `["added"]`, Value: SequenceValue{}},
+		{Op: OP_REPLACE, Path: `["id"]["v1"]`, Value: Bytes{2}},
+		{Op: OP_REPLACE, Path: `["name"]`, Value: Bytes("beta")},
+		{Op: OP_REMOVE, Path: `["removed"]`},
+		{Op: OP_ADD, Path: `["servers"][0]`, Value: Bytes("x")},
+		{Op: OP_REMOVE, Path: `["servers"][2]`},
+		{Op: OP_REPLACE, Path: `["servers"][3]`, Value: Bytes("D")},
+		{Op: OP_ADD, Path: `["servers"][4]`, Value: Bytes("e")},
+	}
+	assert.Equal(t, len(expected), len(changes))
+	for i := range expected {
+		t.Log("Change:", i, changes[i].Op, changes[i].Path)
+		assert.Equal(t, expected[i].Op, changes[i].Op)
+		assert.Equal(t, expected[i].Path, changes[i].Path)
+		assert.True(t, (expected[i].Value == nil && changes[i].Value == nil) || expected[i].Value.Equal(changes[i].Value))
+	}
+	result, err := Patch(a, changes)
+	assert.Nil(t, err)
+	assert.True(t, b.Equal(result))
+	// original is not modified
+	assert.Equal(t, 4, len(a["servers"].(SequenceValue)))
+}
+
+func TestDiffPatchRoundtrip(t *testing.T) {
+	var testdata = []struct{ a, b Value }{
+		{a: Bytes("a"), b: Bytes("b")},
+		{a: Bytes("a"), b: SequenceValue{}},
+		{a: SequenceValue{}, b: SequenceValue{Bytes("1"), Bytes("2")}},
+		{a: SequenceValue{Bytes("1"), Bytes("2")}, b: SequenceValue{}},
+		{a: SequenceValue{Bytes("1"), Bytes("2"), Bytes("3")}, b: SequenceValue{Bytes("3"), Bytes("2"), Bytes("1")}},
+		{a: &KeyValue{K: "a", V: Bytes{}}, b: &KeyValue{K: "b", V: Bytes{}}},
+		{a: selectDocument, b: MapValue{"config": MapValue{"servers": SequenceValue{MapValue{"host": Bytes("beta"), "port": Bytes{}}}}}},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		changes := Diff(d.a, d.b)
+		// changes survive transfer
+		var buf bytes.Buffer
+		_, err := WriteSequenceOf(&buf, []Changes{changes})
+		assert.Nil(t, err)
+		var decoded Changes
+		seq, err := ReadSequence(&buf, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, decodeInto(seq[0], &decoded))
+		assert.Equal(t, len(changes), len(decoded))
+		result, err := Patch(d.a, decoded)
+		assert.Nil(t, err)
+		assert.True(t, d.b.Equal(result))
+	}
+}
+
+func TestDiffNil(t *testing.T) {
+	assert.Equal(t, 0, len(Diff(nil, nil)))
+	var testdata = []struct{ a, b Value }{
+		{a: nil, b: Bytes("a")},
+		{a: Bytes("a"), b: nil},
+		{a: nil, b: MapValue{"a": Bytes{}}},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		changes := Diff(d.a, d.b)
+		assert.Equal(t, 1, len(changes))
+		assert.Equal(t, "", changes[0].Path)
+		result, err := Patch(d.a, changes)
+		assert.Nil(t, err)
+		assert.True(t, (d.b == nil && result == nil) || d.b.Equal(result))
+	}
+	changes := Diff(MapValue{"a": nil, "b": Bytes{}}, MapValue{"a": Bytes{}, "b": nil})
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, OP_REPLACE, changes[0].Op)
+	assert.Equal(t, `["a"]`, changes[0].Path)
+	assert.Equal(t, OP_REMOVE, changes[1].Op)
+	assert.Equal(t, `["b"]`, changes[1].Path)
+}
+
+func TestDiffLargeSequence(t *testing.T) {
+	var a, b = make(SequenceValue, 2000), make(SequenceValue, 2000)
+	for i := range a {
+		a[i] = Bytes{byte(i >> 8), byte(i)}
+		b[len(b)-1-i] = a[i]
+	}
+	// reordered entries exceed the table, therefore the sequence is replaced as a whole
+	changes := Diff(MapValue{"seq": a}, MapValue{"seq": b})
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, OP_REPLACE, changes[0].Op)
+	assert.Equal(t, `["seq"]`, changes[0].Path)
+	// common leading and trailing entries are skipped
+	b = append(append(append(SequenceValue{}, a[:1000]...), Bytes("x")), a[1001:]...)
+	changes = Diff(a, b)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, OP_REPLACE, changes[0].Op)
+	assert.Equal(t, "[1000]", changes[0].Path)
+	result, err := Patch(a, changes)
+	assert.Nil(t, err)
+	assert.True(t, b.Equal(result))
+}
+
+func TestPatchIllegal(t *testing.T) {
+	var base = MapValue{"a": SequenceValue{Bytes("x")}, "kv": &KeyValue{K: "k", V: Bytes{}}}
+	var testdata = []struct {
+		change Change
+		err    error
+	}{
+		{change: Change{Op: OP_REMOVE, Path: "missing"}, err: ErrNotFound},
+		{change: Change{Op: OP_REPLACE, Path: "missing", Value: Bytes{}}, err: ErrNotFound},
+		{change: Change{Op: OP_ADD, Path: "a", Value: Bytes{}}, err: errors.ErrIllegal},
+		{change: Change{Op: OP_ADD, Path: "a[2]", Value: Bytes{}}, err: ErrNotFound},
+		{change: Change{Op: OP_REMOVE, Path: "a[1]"}, err: ErrNotFound},
+		{change: Change{Op: OP_REMOVE, Path: "kv.k"}, err: errors.ErrIllegal},
+		{change: Change{Op: OP_REPLACE, Path: "kv.x", Value: Bytes{}}, err: ErrNotFound},
+		{change: Change{Op: OP_ADD, Path: "", Value: Bytes{}}, err: errors.ErrIllegal},
+		{change: Change{Op: OP_ADD, Path: "b"}, err: errors.ErrIllegal},
+		{change: Change{Op: OP_ADD, Path: "a[", Value: Bytes{}}, err: errors.ErrIllegal},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		_, err := Patch(base, Changes{d.change})
+		assert.IsError(t, d.err, err)
+	}
+}
+
+func TestChangesDecodeIllegal(t *testing.T) {
+	var testdata = []SequenceValue{
+		{Bytes{}},
+		{MapValue{"op": Bytes("move"), "path": Bytes("")}},
+		{MapValue{"op": Bytes("remove")}},
+		{MapValue{"op": Bytes("remove"), "path": Bytes("a"), "value": Bytes{}}},
+		{MapValue{"op": Bytes("add"), "path": Bytes("a")}},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		var c Changes
+		assert.IsError(t, errors.ErrIllegal, c.PrefixedDecode(d))
+	}
+}
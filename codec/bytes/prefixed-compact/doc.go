@@ -78,7 +78,36 @@
 // developers from having to include logic/restrictions for properly processing data in both the code-base
 // and encoded format. (In virtually all cases, data-types, structures and classes already require such logic
 // in order to preserve their invariants during use.)
-// TODO consider adding PrefixedEncode and PrefixedDecode interfaces for encoding arbitrary types depending on user implementations. (Possibly with some verification during encoding.)
+//
+// Values written by this package are in canonical form: minimal headers, values split only when exceeding the
+// maximum record-size, and map-entries ordered by key. This makes the encoding suitable for hashing and
+// signing. `VerifyCanonical` verifies canonical form, and `Canonicalize` re-encodes into canonical form.
+//
+// ## Text notation
+//
+// Values can be expressed in a textual, diagnostic notation, similar to CBOR's diagnostic notation, using
+// `Format` and `Parse`:
+//
+//   - bytes: a quoted string with Go escape-sequences, e.g. `"hello"`, or hexadecimal, e.g. `h'00ff'`,
+//   - key-value-pair: key (as bytes), colon, value, e.g. `"id": h'0102'`,
+//   - sequence: values, separated by commas, between brackets, e.g. `["a", "b"]`,
+//   - map: key-value-pairs, separated by commas, between braces, e.g. `{"a": "1", "b": "2"}`.
+//
+// Whitespace is insignificant, except inside quoted strings. Hexadecimal bytes may contain whitespace for
+// readability, e.g. `h'0011 2233'`.
+//
+// ## JSON mapping
+//
+// Values map losslessly to JSON, using `ToJSON` and `FromJSON`, such that values round-trip exactly:
+//
+//   - bytes: string with marker-prefix, `"u:<text>"` for valid UTF-8, or `"b:<base64>"` otherwise,
+//   - map: object, with each key marked as for bytes,
+//   - sequence: array,
+//   - key-value-pair: object tagged `kv`, containing a single-entry object of marked key and value, e.g.
+//     `{"kv": {"u:id": "b:AQI="}}`.
+//
+// Types may provide their own representation by implementing `PrefixedEncoder` and `PrefixedDecoder`. The
+// representation is then picked up by the write-functions, `ReadInto` and (un)marshalling.
 package prefixed
 
 import "io"
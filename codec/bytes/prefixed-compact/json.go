@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// Markers and tag for the JSON mapping. See package documentation.
+const (
+	jsonMarkerUTF8   = "u:"
+	jsonMarkerBase64 = "b:"
+	jsonTagKeyValue  = "kv"
+)
+
+// ToJSON converts the value to its JSON representation. Returns `errors.ErrIllegal` (with context) if the
+// value is, or contains, `nil` or a value-type other than the ones provided by this package.
+func ToJSON(v Value) ([]byte, error) {
+	obj, err := toJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+func toJSON(v Value) (any, error) {
+	switch v := v.(type) {
+	case Bytes:
+		return markJSON(v), nil
+	case *KeyValue:
+		if v == nil {
+			return nil, errors.Context(errors.ErrIllegal, "nil key-value")
+		}
+		e, err := toJSON(v.V)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{jsonTagKeyValue: map[string]any{markJSON([]byte(v.K)): e}}, nil
+	case SequenceValue:
+		var seq = make([]any, len(v))
+		for i, e := range v {
+			var err error
+			if seq[i], err = toJSON(e); err != nil {
+				return nil, err
+			}
+		}
+		return seq, nil
+	case MapValue:
+		var obj = make(map[string]any, len(v))
+		for k, e := range v {
+			var err error
+			if obj[markJSON([]byte(k))], err = toJSON(e); err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	case nil:
+		return nil, errors.Context(errors.ErrIllegal, "nil value")
+	default:
+		return nil, errors.Context(errors.ErrIllegal, "unsupported value-type")
+	}
+}
+
+func markJSON(data []byte) string {
+	if utf8.Valid(data) {
+		return jsonMarkerUTF8 + string(data)
+	}
+	return jsonMarkerBase64 + base64.StdEncoding.EncodeToString(data)
+}
+
+// FromJSON converts the JSON representation, as produced by `ToJSON`, back to a value. Returns
+// `errors.ErrIllegal` (with context) for JSON that does not follow the mapping, such as numbers, booleans,
+// null, unmarked strings, or multiple keys that map to the same key. The input is processed token by token,
+// such that duplicate keys are detected, including keys that are written differently but map to the same
+// bytes.
+func FromJSON(data []byte) (Value, error) {
+	var dec = json.NewDecoder(bytes.NewReader(data))
+	v, err := fromJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, errors.Context(errors.ErrIllegal, "unexpected content after JSON value")
+	}
+	return v, nil
+}
+
+// jsonToken reads the next token, converting decoding errors into `errors.ErrIllegal`.
+func jsonToken(dec *json.Decoder) (json.Token, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, errors.Context(errors.ErrIllegal, "invalid JSON: "+err.Error())
+	}
+	return tok, nil
+}
+
+// jsonEnd reads the closing delimiter of an array or object. Returns `errors.ErrIllegal` with `msg` as
+// context, if further elements or entries are present.
+func jsonEnd(dec *json.Decoder, msg string) error {
+	if dec.More() {
+		return errors.Context(errors.ErrIllegal, msg)
+	}
+	_, err := jsonToken(dec)
+	return err
+}
+
+func fromJSON(dec *json.Decoder) (Value, error) {
+	tok, err := jsonToken(dec)
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('['):
+		var seq = make(SequenceValue, 0)
+		for dec.More() {
+			e, err := fromJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, e)
+		}
+		if _, err = jsonToken(dec); err != nil {
+			return nil, err
+		}
+		return seq, nil
+	case json.Delim('{'):
+		return fromJSONObject(dec)
+	}
+	if s, ok := tok.(string); ok {
+		data, err := unmarkJSON(s)
+		if err != nil {
+			return nil, err
+		}
+		return Bytes(data), nil
+	}
+	return nil, errors.Context(errors.ErrIllegal, "JSON type not supported: expected string, array or object")
+}
+
+// fromJSONObject converts the remainder of a JSON object, after its opening delimiter.
+func fromJSONObject(dec *json.Decoder) (Value, error) {
+	var m = make(MapValue)
+	for dec.More() {
+		tok, err := jsonToken(dec)
+		if err != nil {
+			return nil, err
+		}
+		// Object keys are always strings.
+		var k = tok.(string)
+		if k == jsonTagKeyValue && len(m) == 0 {
+			return fromJSONKeyValue(dec)
+		}
+		key, err := unmarkJSON(k)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m[string(key)]; ok {
+			return nil, errors.Context(errors.ErrIllegal, "duplicate key in JSON object: "+k)
+		}
+		if m[string(key)], err = fromJSON(dec); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := jsonToken(dec); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// fromJSONKeyValue converts the remainder of a tagged key-value-pair object, after its tag.
+func fromJSONKeyValue(dec *json.Decoder) (*KeyValue, error) {
+	tok, err := jsonToken(dec)
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') || !dec.More() {
+		return nil, errors.Context(errors.ErrIllegal, "key-value-pair must be object with single entry")
+	}
+	if tok, err = jsonToken(dec); err != nil {
+		return nil, err
+	}
+	key, err := unmarkJSON(tok.(string))
+	if err != nil {
+		return nil, err
+	}
+	v, err := fromJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	if err = jsonEnd(dec, "key-value-pair must be object with single entry"); err != nil {
+		return nil, err
+	}
+	if err = jsonEnd(dec, "tagged key-value-pair object must have single entry"); err != nil {
+		return nil, err
+	}
+	return &KeyValue{K: string(key), V: v}, nil
+}
+
+func unmarkJSON(s string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(s, jsonMarkerUTF8):
+		return []byte(s[len(jsonMarkerUTF8):]), nil
+	case strings.HasPrefix(s, jsonMarkerBase64):
+		data, err := base64.StdEncoding.DecodeString(s[len(jsonMarkerBase64):])
+		if err != nil {
+			return nil, errors.Context(errors.ErrIllegal, "invalid base64-encoded bytes: "+err.Error())
+		}
+		return data, nil
+	default:
+		return nil, errors.Context(errors.ErrIllegal, "string without bytes-marker: "+s)
+	}
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestToJSON(t *testing.T) {
+	var testdata = []struct {
+		value Value
+		json  string
+	}{
+		{value: Bytes{}, json: `"u:"`},
+		{value: Bytes("hello"), json: `"u:hello"`},
+		{value: Bytes{0xff, 0x00}, json: `"b:/wA="`},
+		{value: &KeyValue{K: "id", V: Bytes{1, 2}}, json: `{"kv":{"u:id":"u:\u0001\u0002"}}`},
+		{value: SequenceValue{}, json: `[]`},
+		{value: SequenceValue{Bytes("a"), SequenceValue{}}, json: `["u:a",[]]`},
+		{value: MapValue{}, json: `{}`},
+		{value: MapValue{"b": Bytes("2"), "\xff": Bytes("1")}, json: `{"b:/w==":"u:1","u:b":"u:2"}`},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		data, err := ToJSON(d.value)
+		assert.Nil(t, err)
+		assert.Equal(t, d.json, string(data))
+		v, err := FromJSON(data)
+		assert.Nil(t, err)
+		assert.True(t, d.value.Equal(v))
+	}
+}
+
+func TestToJSONIllegal(t *testing.T) {
+	var testdata = []Value{
+		nil,
+		(*KeyValue)(nil),
+		SequenceValue{nil},
+		MapValue{"a": SequenceValue{Bytes("b"), nil}},
+		&KeyValue{K: "k", V: nil},
+		customValue{Bytes("c")},
+		SequenceValue{customValue{Bytes("c")}},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		data, err := ToJSON(d)
+		assert.IsError(t, errors.ErrIllegal, err)
+		assert.True(t, data == nil)
+	}
+}
+
+func TestJSONRoundtrip(t *testing.T) {
+	var v = MapValue{
+		"":     SequenceValue{Bytes{}, Bytes("<&>"), Bytes{0x80}},
+		"kv":   &KeyValue{K: "\xfe", V: &KeyValue{K: "", V: MapValue{}}},
+		"u:":   Bytes("\u2028"),
+		"\x00": Bytes("b:"),
+	}
+	data, err := ToJSON(v)
+	assert.Nil(t, err)
+	result, err := FromJSON(data)
+	assert.Nil(t, err)
+	assert.True(t, v.Equal(result))
+}
+
+func TestFromJSONIllegal(t *testing.T) {
+	var testdata = []string{
+		``,
+		`1`,
+		`true`,
+		`null`,
+		`"unmarked"`,
+		`"b:not base64"`,
+		`["u:a", 1]`,
+		`{"a": "u:a"}`,
+		`{"u:a": "u:1", "b:YQ==": "u:2"}`,
+		`{"u:a": "u:1", "u:a": "u:2"}`,
+		`{"kv": {"u:a": "u:1", "u:a": "u:2"}}`,
+		`{"kv": {"u:a": "u:1"}, "kv": {"u:a": "u:1"}}`,
+		`["u:a"] ["u:b"]`,
+		`["u:a"`,
+		`{"kv": {"u:a": "u:1"}, "u:b": "u:2"}`,
+		`{"kv": {}}`,
+		`{"kv": {"u:a": "u:1", "u:b": "u:2"}}`,
+		`{"kv": "u:a"}`,
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		_, err := FromJSON([]byte(d))
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+}
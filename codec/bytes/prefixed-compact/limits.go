@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// ErrLimitExceeded indicates that decoding is aborted because the input exceeds one of the configured
+// limits. See `Limits`.
+var ErrLimitExceeded = errors.NewStringError("decoding limit exceeded")
+
+// DefaultMaxDepth is the maximum nesting depth that is enforced if `Limits.MaxDepth` is zero. It prevents
+// deeply nested input from exhausting the stack, as decoding is recursive.
+const DefaultMaxDepth = 128
+
+// Limits restricts the resources that may be consumed while decoding, such that (untrusted) input cannot
+// cause excessive memory use or processing. A zero-value for any of the limits other than `MaxDepth`
+// indicates that the limit is not enforced. A zero-value for `MaxDepth` selects `DefaultMaxDepth`.
+// Consequently, the zero-value for `Limits`, which is what the package-level Read-, Parse- and
+// Verify-functions use, only limits the nesting depth.
+//
+// Exceeding any limit results in `ErrLimitExceeded` (with context).
+type Limits struct {
+	// MaxDepth is the maximum nesting depth. Each sequence, map and key-value-pair (other than map-entries)
+	// adds one level of nesting. A top-level bytes-value has depth 0. (default: `DefaultMaxDepth`)
+	MaxDepth uint
+	// MaxValueSize is the maximum number of bytes of a single bytes-value or key, accumulated over all its
+	// (non-terminated) continuation records.
+	MaxValueSize uint
+	// MaxEntries is the maximum total number of entries, i.e. sequence-entries and map-entries, across all
+	// decoded sequences and maps.
+	MaxEntries uint
+	// MaxInput is the maximum total number of bytes read from the input.
+	MaxInput uint
+}
+
+// ReadBytes reads a bytes-value, honoring the limits. See `ReadBytes`.
+func (l Limits) ReadBytes(in io.Reader, _hdr *Header) (Bytes, error) {
+	return l.decoding(in).readBytes(_hdr)
+}
+
+// ReadKeyValue reads a key-value-pair, honoring the limits. See `ReadKeyValue`.
+func (l Limits) ReadKeyValue(in io.Reader, _hdr *Header) (*KeyValue, error) {
+	return l.decoding(in).readKeyValue(_hdr)
+}
+
+// ReadSequence reads a sequence-value, honoring the limits. See `ReadSequence`.
+func (l Limits) ReadSequence(in io.Reader, _hdr *Header) (SequenceValue, error) {
+	return l.decoding(in).readSequence(_hdr)
+}
+
+// ReadMap reads a map-value, honoring the limits. See `ReadMap`.
+func (l Limits) ReadMap(in io.Reader, _hdr *Header) (MapValue, error) {
+	return l.decoding(in).readMap(_hdr)
+}
+
+// ReadValue reads a value of any type, honoring the limits. See `ReadValue`.
+func (l Limits) ReadValue(in io.Reader) (Value, error) {
+	return l.decoding(in).readValue(nil)
+}
+
+// ParseBytes reads a bytes-value from input-data, honoring the limits. See `ParseBytes`.
+func (l Limits) ParseBytes(data []byte, _hdr *Header) (uint, Bytes, error) {
+	var in = bytes.NewReader(data)
+	v, err := l.ReadBytes(in, _hdr)
+	if err != nil {
+		return 0, nil, err
+	}
+	return consumed(in), v, nil
+}
+
+// ParseKeyValue reads a key-value-pair from input-data, honoring the limits. See `ParseKeyValue`.
+func (l Limits) ParseKeyValue(data []byte, _hdr *Header) (uint, *KeyValue, error) {
+	var in = bytes.NewReader(data)
+	v, err := l.ReadKeyValue(in, _hdr)
+	if err != nil {
+		return 0, nil, err
+	}
+	return consumed(in), v, nil
+}
+
+// ParseSequence reads a sequence-value from input-data, honoring the limits. See `ParseSequence`.
+func (l Limits) ParseSequence(data []byte, _hdr *Header) (uint, SequenceValue, error) {
+	var in = bytes.NewReader(data)
+	v, err := l.ReadSequence(in, _hdr)
+	if err != nil {
+		return 0, nil, err
+	}
+	return consumed(in), v, nil
+}
+
+// ParseMap reads a map-value from input-data, honoring the limits. See `ParseMap`.
+func (l Limits) ParseMap(data []byte, _hdr *Header) (uint, MapValue, error) {
+	var in = bytes.NewReader(data)
+	v, err := l.ReadMap(in, _hdr)
+	if err != nil {
+		return 0, nil, err
+	}
+	return consumed(in), v, nil
+}
+
+// ParseValue reads a value of any type from input-data, honoring the limits. See `ParseValue`.
+func (l Limits) ParseValue(data []byte) (uint, Value, error) {
+	var in = bytes.NewReader(data)
+	v, err := l.ReadValue(in)
+	if err != nil {
+		return 0, nil, err
+	}
+	return consumed(in), v, nil
+}
+
+// ParseBytesNoCopy reads a bytes-value from input-data without copying, honoring the limits. See
+// `ParseBytesNoCopy`.
+func (l Limits) ParseBytesNoCopy(data []byte, _hdr *Header) (uint, Bytes, error) {
+	return parseNoCopy(l, data, func(d *decoding) (Bytes, error) { return d.readBytes(_hdr) })
+}
+
+// ParseKeyValueNoCopy reads a key-value-pair from input-data without copying, honoring the limits. See
+// `ParseKeyValueNoCopy`.
+func (l Limits) ParseKeyValueNoCopy(data []byte, _hdr *Header) (uint, *KeyValue, error) {
+	return parseNoCopy(l, data, func(d *decoding) (*KeyValue, error) { return d.readKeyValue(_hdr) })
+}
+
+// ParseSequenceNoCopy reads a sequence-value from input-data without copying, honoring the limits. See
+// `ParseSequenceNoCopy`.
+func (l Limits) ParseSequenceNoCopy(data []byte, _hdr *Header) (uint, SequenceValue, error) {
+	return parseNoCopy(l, data, func(d *decoding) (SequenceValue, error) { return d.readSequence(_hdr) })
+}
+
+// ParseMapNoCopy reads a map-value from input-data without copying, honoring the limits. See
+// `ParseMapNoCopy`.
+func (l Limits) ParseMapNoCopy(data []byte, _hdr *Header) (uint, MapValue, error) {
+	return parseNoCopy(l, data, func(d *decoding) (MapValue, error) { return d.readMap(_hdr) })
+}
+
+// ParseValueNoCopy reads a value of any type from input-data without copying, honoring the limits. See
+// `ParseValueNoCopy`.
+func (l Limits) ParseValueNoCopy(data []byte) (uint, Value, error) {
+	return parseNoCopy(l, data, func(d *decoding) (Value, error) { return d.readValue(nil) })
+}
+
+// parseNoCopy reads from input-data without copying, using `read` for the decoding.
+func parseNoCopy[V any](l Limits, data []byte, read func(d *decoding) (V, error)) (uint, V, error) {
+	var d = l.decoding(bytes.NewReader(data))
+	d.borrow = data
+	v, err := read(d)
+	if err != nil {
+		var zero V
+		return 0, zero, err
+	}
+	return d.read, v, nil
+}
+
+// VerifyValue verifies a single value, honoring the limits. See `VerifyValue`.
+func (l Limits) VerifyValue(in io.Reader) error {
+	return l.decoding(in).verifyValue()
+}
+
+// Verify verifies all values until end of input, honoring the limits. Limits apply to all values
+// collectively. See `Verify`.
+func (l Limits) Verify(in io.Reader) error {
+	return l.decoding(in).verify()
+}
+
+// VerifyCanonical verifies that all values until end of input are in canonical form, honoring the limits.
+// Limits apply to all values collectively. See `VerifyCanonical`.
+func (l Limits) VerifyCanonical(in io.Reader) error {
+	var d = l.decoding(in)
+	d.canonical = true
+	return d.verify()
+}
+
+// Canonicalize re-encodes all values until end of input in canonical form, honoring the limits. Limits apply
+// to all values collectively. See `Canonicalize`.
+func (l Limits) Canonicalize(in io.Reader, out io.Writer) error {
+	var d = l.decoding(in)
+	for {
+		v, err := d.readValue(nil)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if _, err = v.WriteTo(out); err != nil {
+			return err
+		}
+	}
+}
+
+// SelectReader selects the value at `path` from the next value in the input, honoring the limits. See
+// `SelectReader`.
+func (l Limits) SelectReader(in io.Reader, path string) (Value, error) {
+	return l.decoding(in).selectReader(path)
+}
+
+// NewDecoder creates a new decoder that honors the limits. Limits apply to all values collectively.
+func (l Limits) NewDecoder(in io.Reader) *Decoder {
+	return &Decoder{in: l.decoding(in)}
+}
+
+func (l Limits) decoding(in io.Reader) *decoding {
+	return &decoding{in: in, limits: l}
+}
+
+// consumed returns the number of bytes read from the reader.
+func consumed(in *bytes.Reader) uint {
+	return uint(in.Size() - int64(in.Len()))
+}
+
+// decoding tracks the state of the decoding process, for enforcing limits. `decoding` wraps the input
+// reader, such that the number of bytes read is tracked.
+type decoding struct {
+	in      io.Reader
+	limits  Limits
+	read    uint
+	depth   uint
+	entries uint
+	// borrow is the input-data, if available, for zero-copy decoding. Decoding must start at the beginning
+	// of `borrow`, such that `read` is the offset into `borrow`.
+	borrow []byte
+	// canonical indicates that the encoding must be canonical. See `VerifyCanonical`.
+	canonical bool
+	// exceeded indicates that input beyond `MaxInput` is available.
+	exceeded bool
+}
+
+var _ io.Reader = (*decoding)(nil)
+
+// Read reads from the underlying reader, such that reading beyond `MaxInput` is prevented. Reading is
+// restricted to at most one byte beyond `MaxInput`, which distinguishes exceeding the limit from
+// end-of-input. Upon reaching `MaxInput`, end-of-input is still reported as `io.EOF`. The additional byte is
+// never delivered, and is returned to the underlying reader if it is an `io.ByteScanner`.
+func (d *decoding) Read(p []byte) (int, error) {
+	if d.limits.MaxInput == 0 {
+		n, err := d.in.Read(p)
+		d.read += uint(n)
+		return n, err
+	}
+	if d.exceeded {
+		return 0, errors.Context(ErrLimitExceeded, "maximum input size reached")
+	}
+	var remaining = d.limits.MaxInput - d.read
+	if uint(len(p)) > remaining+1 {
+		p = p[:remaining+1]
+	}
+	n, err := d.in.Read(p)
+	if uint(n) > remaining {
+		d.exceeded = true
+		if scanner, ok := d.in.(io.ByteScanner); ok {
+			// Return the additional byte, such that no input is lost beyond the limit.
+			_ = scanner.UnreadByte()
+		}
+		n = int(remaining)
+		if n == 0 {
+			return 0, errors.Context(ErrLimitExceeded, "maximum input size reached")
+		}
+		err = nil
+	}
+	d.read += uint(n)
+	return n, err
+}
+
+// enter registers descending one level of nesting.
+func (d *decoding) enter() error {
+	var limit = d.limits.MaxDepth
+	if limit == 0 {
+		limit = DefaultMaxDepth
+	}
+	if d.depth >= limit {
+		return errors.Context(ErrLimitExceeded, "maximum nesting depth reached")
+	}
+	d.depth++
+	return nil
+}
+
+// leave registers ascending one level of nesting.
+func (d *decoding) leave() {
+	d.depth--
+}
+
+// entry registers one additional sequence-entry or map-entry.
+func (d *decoding) entry() error {
+	if d.limits.MaxEntries > 0 && d.entries >= d.limits.MaxEntries {
+		return errors.Context(ErrLimitExceeded, "maximum number of entries reached")
+	}
+	d.entries++
+	return nil
+}
+
+// size checks whether accumulating `size` bytes onto `total` bytes exceeds the maximum value size.
+func (d *decoding) size(total uint, size uint16) error {
+	if d.limits.MaxValueSize > 0 && total+uint(size) > d.limits.MaxValueSize {
+		return errors.Context(ErrLimitExceeded, "maximum value size exceeded")
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func encodeTestValue(t testing.TB, v Value) []byte {
+	var b bytes.Buffer
+	_, err := v.WriteTo(&b)
+	assert.Nil(t, err)
+	return b.Bytes()
+}
+
+func nestedSequence(depth int) Value {
+	var v Value = Bytes("leaf")
+	for i := 0; i < depth; i++ {
+		v = SequenceValue{v}
+	}
+	return v
+}
+
+func TestLimitsUnlimited(t *testing.T) {
+	data := encodeTestValue(t, nestedSequence(100))
+	n, v, err := Limits{}.ParseValue(data)
+	assert.Nil(t, err)
+	assert.Equal(t, uint(len(data)), n)
+	assert.EqualT(t, nestedSequence(100), v)
+}
+
+func TestLimitsDefaultMaxDepth(t *testing.T) {
+	data := encodeTestValue(t, nestedSequence(DefaultMaxDepth))
+	_, _, err := Limits{}.ParseValue(data)
+	assert.Nil(t, err)
+	data = encodeTestValue(t, nestedSequence(DefaultMaxDepth+1))
+	_, err = ReadValue(bytes.NewReader(data))
+	assert.IsError(t, ErrLimitExceeded, err)
+	assert.IsError(t, ErrLimitExceeded, Verify(bytes.NewReader(data)))
+}
+
+func TestLimitsMaxDepth(t *testing.T) {
+	var limits = Limits{MaxDepth: 3}
+	for depth, expected := range []error{nil, nil, nil, nil, ErrLimitExceeded, ErrLimitExceeded} {
+		t.Log("Depth:", depth)
+		data := encodeTestValue(t, nestedSequence(depth))
+		_, _, err := limits.ParseValue(data)
+		assert.IsError(t, expected, err)
+		assert.IsError(t, expected, limits.Verify(bytes.NewReader(data)))
+	}
+}
+
+func TestLimitsMaxDepthMapEntries(t *testing.T) {
+	data := encodeTestValue(t, MapValue{"a": MapValue{"b": Bytes("c")}})
+	_, _, err := Limits{MaxDepth: 2}.ParseValue(data)
+	assert.Nil(t, err)
+	_, _, err = Limits{MaxDepth: 1}.ParseValue(data)
+	assert.IsError(t, ErrLimitExceeded, err)
+	data = encodeTestValue(t, &KeyValue{K: "a", V: &KeyValue{K: "b", V: Bytes("c")}})
+	_, _, err = Limits{MaxDepth: 2}.ParseValue(data)
+	assert.Nil(t, err)
+	assert.IsError(t, ErrLimitExceeded, Limits{MaxDepth: 1}.Verify(bytes.NewReader(data)))
+}
+
+func TestLimitsMaxValueSize(t *testing.T) {
+	data := encodeTestValue(t, Bytes(make([]byte, 10000)))
+	var limits = Limits{MaxValueSize: 9999}
+	_, _, err := limits.ParseValue(data)
+	assert.IsError(t, ErrLimitExceeded, err)
+	assert.IsError(t, ErrLimitExceeded, limits.Verify(bytes.NewReader(data)))
+	limits.MaxValueSize = 10000
+	_, _, err = limits.ParseValue(data)
+	assert.Nil(t, err)
+	assert.Nil(t, limits.Verify(bytes.NewReader(data)))
+	key := encodeTestValue(t, &KeyValue{K: "0123456789", V: Bytes("short")})
+	_, _, err = Limits{MaxValueSize: 9}.ParseKeyValue(key, nil)
+	assert.IsError(t, ErrLimitExceeded, err)
+}
+
+func TestLimitsMaxEntries(t *testing.T) {
+	data := encodeTestValue(t, SequenceValue{SequenceValue{Bytes{}, Bytes{}}, MapValue{"a": Bytes{}}})
+	_, _, err := Limits{MaxEntries: 5}.ParseValue(data)
+	assert.Nil(t, err)
+	_, _, err = Limits{MaxEntries: 4}.ParseValue(data)
+	assert.IsError(t, ErrLimitExceeded, err)
+	assert.IsError(t, ErrLimitExceeded, Limits{MaxEntries: 4}.Verify(bytes.NewReader(data)))
+}
+
+func TestLimitsMaxInput(t *testing.T) {
+	data := encodeTestValue(t, Bytes("hello"))
+	_, _, err := Limits{MaxInput: 6}.ParseValue(data)
+	assert.Nil(t, err)
+	_, _, err = Limits{MaxInput: 5}.ParseValue(data)
+	assert.IsError(t, ErrLimitExceeded, err)
+	assert.IsError(t, ErrLimitExceeded, Limits{MaxInput: 11}.Verify(bytes.NewReader(append(data, data...))))
+	assert.Nil(t, Limits{MaxInput: 12}.Verify(bytes.NewReader(append(data, data...))))
+}
+
+func TestLimitsMaxInputPreservesInput(t *testing.T) {
+	data := encodeTestValue(t, Bytes("hello"))
+	in := bytes.NewReader(append(data, data...))
+	assert.IsError(t, ErrLimitExceeded, Limits{MaxInput: 6}.Verify(in))
+	assert.Equal(t, len(data), in.Len())
+	v, err := ReadValue(in)
+	assert.Nil(t, err)
+	assert.EqualT[Value](t, Bytes("hello"), v)
+}
+
+func TestLimitsDecoder(t *testing.T) {
+	data := encodeTestValue(t, SequenceValue{SequenceValue{Bytes("abc")}})
+	d := Limits{MaxDepth: 1}.NewDecoder(bytes.NewReader(data))
+	_, err := d.Next()
+	assert.Nil(t, err)
+	_, err = d.Next()
+	assert.IsError(t, ErrLimitExceeded, err)
+	d = Limits{MaxDepth: 1}.NewDecoder(bytes.NewReader(data))
+	_, err = d.Next()
+	assert.Nil(t, err)
+	var v Value
+	assert.IsError(t, ErrLimitExceeded, d.Decode(&v))
+	d = Limits{MaxValueSize: 2}.NewDecoder(bytes.NewReader(data))
+	for err == nil {
+		_, err = d.Next()
+	}
+	assert.IsError(t, ErrLimitExceeded, err)
+	d = Limits{MaxEntries: 1}.NewDecoder(bytes.NewReader(data))
+	for err = nil; err == nil; {
+		_, err = d.Next()
+	}
+	assert.IsError(t, ErrLimitExceeded, err)
+	d = Limits{MaxEntries: 2, MaxDepth: 2, MaxValueSize: 3}.NewDecoder(bytes.NewReader(data))
+	for err = nil; err == nil; {
+		_, err = d.Next()
+	}
+	assert.IsError(t, io.EOF, err)
+}
+
+func TestReadTruncatedUnexpectedEOF(t *testing.T) {
+	data := encodeTestValue(t, SequenceValue{Bytes("hello")})
+	for i := 1; i < len(data); i++ {
+		_, err := ReadValue(bytes.NewReader(data[:i]))
+		assert.IsError(t, io.ErrUnexpectedEOF, err)
+		assert.IsError(t, io.ErrUnexpectedEOF, Verify(bytes.NewReader(data[:i])))
+	}
+}
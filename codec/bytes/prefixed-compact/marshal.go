@@ -0,0 +1,477 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/cobratbq/goutils/codec/bytes/bigendian"
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// TAG_NAME is the struct-tag name that is used for marshalling and unmarshalling structs.
+//
+// The tag-value is formatted as `name,option,option,...`. The name overrides the field-name as map-key. If
+// the name is omitted, the field-name is used. The name `-` indicates that the field must be skipped.
+// Option `omitempty` indicates that the field is not written if its value is the zero-value or empty.
+const TAG_NAME = "prefixed"
+
+// Marshal encodes a Go value into its prefixed-compact representation.
+//
+// The following convention is used to map Go data-types onto prefixed-compact values:
+//
+//   - `PrefixedEncoder` implementations are written as the value they produce,
+//   - `Value` implementations are written as-is,
+//   - structs are written as `MapValue`, with exported fields as entries (see `TAG_NAME` for options),
+//   - `map[string]T` is written as `MapValue`,
+//   - slices and arrays are written as `SequenceValue`, with the exception of byte-slices and byte-arrays,
+//   - `[]byte`, `[N]byte` and `string` are written as `Bytes`,
+//   - `bool` is written as single byte `Bytes`, with value 0 or 1,
+//   - integers are written as fixed-width big-endian `Bytes`, i.e. `int8`/`uint8` in 1 byte,
+//     `int16`/`uint16` in 2 bytes, `int32`/`uint32` in 4 bytes, `int64`/`uint64` in 8 bytes. `int` and
+//     `uint` are platform-independent and always written in 8 bytes. Signed integers in two's complement.
+//   - floats are written as big-endian IEEE-754 representation in 4 bytes (`float32`) or 8 bytes
+//     (`float64`),
+//   - pointers and interfaces are written as the value they refer to.
+//
+// There is no representation for `nil`. A struct-field that is a nil-pointer or nil-interface is not
+// written. Anywhere else, `nil` results in `errors.ErrIllegal`. Any other data-type, e.g. channels,
+// functions, complex numbers, results in `errors.ErrUnsupported`.
+func Marshal(v any) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := NewEncoder(&buffer).Encode(v); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// MarshalValue converts a Go value into its prefixed-compact `Value`-representation. See `Marshal` for the
+// convention that is used.
+func MarshalValue(v any) (Value, error) {
+	return marshalValue(reflect.ValueOf(v))
+}
+
+// Unmarshal decodes prefixed-compact encoded data into the value pointed to by `v`. Data must contain
+// exactly one encoded value. See `Marshal` for the convention that is used.
+//
+// When unmarshalling, `PrefixedDecoder` implementations decode themselves. Map-entries without corresponding
+// struct-field are ignored. Struct-fields without map-entry are left untouched. Integers and floats must have
+// exact width, as determined by the data-type.
+func Unmarshal(data []byte, v any) error {
+	var in = bytes.NewReader(data)
+	var val Value
+	var err error
+	if val, err = ReadValue(in); err != nil {
+		return err
+	}
+	if in.Len() > 0 {
+		return errors.Context(errors.ErrIllegal, "unexpected data remaining after value")
+	}
+	return UnmarshalValue(val, v)
+}
+
+// UnmarshalValue maps a prefixed-compact `Value` onto the Go value pointed to by `v`. See `Unmarshal`.
+func UnmarshalValue(val Value, v any) error {
+	if val == nil {
+		return errors.Context(errors.ErrIllegal, "nil-value cannot be unmarshalled")
+	}
+	var rv = reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.Context(errors.ErrIllegal, "unmarshalling requires a non-nil pointer")
+	}
+	return unmarshalValue(val, rv.Elem())
+}
+
+// Encoder writes Go values as prefixed-compact encoded values to the underlying writer.
+type Encoder struct {
+	out io.Writer
+}
+
+// NewEncoder creates a new encoder that writes to `out`.
+func NewEncoder(out io.Writer) *Encoder {
+	return &Encoder{out: out}
+}
+
+// Encode marshals `v` and writes the encoded value. See `Marshal`.
+func (e *Encoder) Encode(v any) error {
+	val, err := MarshalValue(v)
+	if err != nil {
+		return err
+	}
+	_, err = val.WriteTo(e.out)
+	return err
+}
+
+var typeValue = reflect.TypeFor[Value]()
+var typeEncoder = reflect.TypeFor[PrefixedEncoder]()
+var typeDecoder = reflect.TypeFor[PrefixedDecoder]()
+
+func marshalValue(rv reflect.Value) (Value, error) {
+	if !rv.IsValid() {
+		return nil, errors.Context(errors.ErrIllegal, "nil cannot be represented")
+	}
+	if rv.Kind() != reflect.Pointer && rv.CanAddr() && rv.Addr().Type().Implements(typeEncoder) {
+		return rv.Addr().Interface().(PrefixedEncoder).PrefixedEncode()
+	}
+	if rv.Type().Implements(typeEncoder) {
+		if (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil() {
+			return nil, errors.Context(errors.ErrIllegal, "nil cannot be represented")
+		}
+		return rv.Interface().(PrefixedEncoder).PrefixedEncode()
+	}
+	if rv.Type().Implements(typeValue) {
+		if (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil() {
+			return nil, errors.Context(errors.ErrIllegal, "nil cannot be represented")
+		}
+		return rv.Interface().(Value), nil
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return Bytes{1}, nil
+		}
+		return Bytes{0}, nil
+	case reflect.Int8:
+		return Bytes{uint8(rv.Int())}, nil
+	case reflect.Int16:
+		b := bigendian.FromUint16(uint16(rv.Int()))
+		return Bytes(b[:]), nil
+	case reflect.Int32:
+		b := bigendian.FromUint32(uint32(rv.Int()))
+		return Bytes(b[:]), nil
+	case reflect.Int64, reflect.Int:
+		b := bigendian.FromUint64(uint64(rv.Int()))
+		return Bytes(b[:]), nil
+	case reflect.Uint8:
+		return Bytes{uint8(rv.Uint())}, nil
+	case reflect.Uint16:
+		b := bigendian.FromUint16(uint16(rv.Uint()))
+		return Bytes(b[:]), nil
+	case reflect.Uint32:
+		b := bigendian.FromUint32(uint32(rv.Uint()))
+		return Bytes(b[:]), nil
+	case reflect.Uint64, reflect.Uint:
+		b := bigendian.FromUint64(rv.Uint())
+		return Bytes(b[:]), nil
+	case reflect.Float32:
+		b := bigendian.FromUint32(math.Float32bits(float32(rv.Float())))
+		return Bytes(b[:]), nil
+	case reflect.Float64:
+		b := bigendian.FromUint64(math.Float64bits(rv.Float()))
+		return Bytes(b[:]), nil
+	case reflect.String:
+		return Bytes(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			var b = make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return Bytes(b), nil
+		}
+		var seq = make(SequenceValue, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			e, err := marshalValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, e)
+		}
+		return seq, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, errors.Context(errors.ErrUnsupported, "map-keys must be of kind string, got "+rv.Type().Key().String())
+		}
+		var m = make(MapValue, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			e, err := marshalValue(iter.Value())
+			if err != nil {
+				return nil, errors.Context(err, "map-entry '"+iter.Key().String()+"'")
+			}
+			m[iter.Key().String()] = e
+		}
+		return m, nil
+	case reflect.Struct:
+		return marshalStruct(rv)
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, errors.Context(errors.ErrIllegal, "nil cannot be represented")
+		}
+		return marshalValue(rv.Elem())
+	default:
+		return nil, errors.Context(errors.ErrUnsupported, "cannot marshal data-type "+rv.Type().String())
+	}
+}
+
+func marshalStruct(rv reflect.Value) (MapValue, error) {
+	var m = make(MapValue, rv.NumField())
+	var t = rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, omitempty, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		var f = rv.Field(i)
+		if (f.Kind() == reflect.Pointer || f.Kind() == reflect.Interface) && f.IsNil() {
+			continue
+		}
+		if omitempty && isEmpty(f) {
+			continue
+		}
+		e, err := marshalValue(f)
+		if err != nil {
+			return nil, errors.Context(err, "struct-field '"+t.Field(i).Name+"'")
+		}
+		m[name] = e
+	}
+	return m, nil
+}
+
+// fieldName determines the map-key for a struct-field, whether `omitempty` is specified, and whether the
+// field is included at all.
+func fieldName(field reflect.StructField) (string, bool, bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag, ok := field.Tag.Lookup(TAG_NAME)
+	if !ok {
+		return field.Name, false, true
+	}
+	var name, options, _ = strings.Cut(tag, ",")
+	if name == "-" && options == "" {
+		return "", false, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	var omitempty bool
+	for _, opt := range strings.Split(options, ",") {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, true
+}
+
+func isEmpty(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
+
+func unmarshalValue(val Value, rv reflect.Value) error {
+	if rv.Kind() != reflect.Pointer && rv.CanAddr() && rv.Addr().Type().Implements(typeDecoder) {
+		return decodeInto(val, rv.Addr().Interface().(PrefixedDecoder))
+	}
+	if reflect.TypeOf(val).AssignableTo(rv.Type()) {
+		rv.Set(reflect.ValueOf(val))
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		b, err := expectBytes(val, rv.Type(), 1)
+		if err != nil {
+			return err
+		}
+		if b[0] > 1 {
+			return errors.Context(errors.ErrIllegal, "expected boolean value 0 or 1")
+		}
+		rv.SetBool(b[0] == 1)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		b, err := expectBytes(val, rv.Type(), fixedWidth(rv.Kind()))
+		if err != nil {
+			return err
+		}
+		var value = toInt64(b)
+		if rv.OverflowInt(value) {
+			return errors.Context(errors.ErrOverflow, "value does not fit in "+rv.Type().String())
+		}
+		rv.SetInt(value)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		b, err := expectBytes(val, rv.Type(), fixedWidth(rv.Kind()))
+		if err != nil {
+			return err
+		}
+		var value = toUint64(b)
+		if rv.OverflowUint(value) {
+			return errors.Context(errors.ErrOverflow, "value does not fit in "+rv.Type().String())
+		}
+		rv.SetUint(value)
+	case reflect.Float32:
+		b, err := expectBytes(val, rv.Type(), 4)
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(float64(bigendian.ToFloat32(b[0], b[1], b[2], b[3])))
+	case reflect.Float64:
+		b, err := expectBytes(val, rv.Type(), 8)
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(bigendian.ToFloat64(b[0], b[1], b[2], b[3], b[4], b[5], b[6], b[7]))
+	case reflect.String:
+		b, err := expectBytes(val, rv.Type(), -1)
+		if err != nil {
+			return err
+		}
+		rv.SetString(string(b))
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := expectBytes(val, rv.Type(), -1)
+			if err != nil {
+				return err
+			}
+			var dst = reflect.MakeSlice(rv.Type(), len(b), len(b))
+			reflect.Copy(dst, reflect.ValueOf([]byte(b)))
+			rv.Set(dst)
+			return nil
+		}
+		seq, ok := val.(SequenceValue)
+		if !ok {
+			return unexpectedType(val, TYPE_SEQUENCE, rv.Type())
+		}
+		var dst = reflect.MakeSlice(rv.Type(), len(seq), len(seq))
+		for i := range seq {
+			if err := unmarshalValue(seq[i], dst.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(dst)
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := expectBytes(val, rv.Type(), rv.Len())
+			if err != nil {
+				return err
+			}
+			reflect.Copy(rv, reflect.ValueOf([]byte(b)))
+			return nil
+		}
+		seq, ok := val.(SequenceValue)
+		if !ok {
+			return unexpectedType(val, TYPE_SEQUENCE, rv.Type())
+		}
+		if len(seq) != rv.Len() {
+			return errors.Context(errors.ErrIllegal, "sequence-length does not match array-length for "+rv.Type().String())
+		}
+		for i := range seq {
+			if err := unmarshalValue(seq[i], rv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return errors.Context(errors.ErrUnsupported, "map-keys must be of kind string, got "+rv.Type().Key().String())
+		}
+		m, ok := val.(MapValue)
+		if !ok {
+			return unexpectedType(val, TYPE_MAP, rv.Type())
+		}
+		var dst = reflect.MakeMapWithSize(rv.Type(), len(m))
+		for k, e := range m {
+			var elem = reflect.New(rv.Type().Elem()).Elem()
+			if err := unmarshalValue(e, elem); err != nil {
+				return errors.Context(err, "map-entry '"+k+"'")
+			}
+			dst.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		}
+		rv.Set(dst)
+	case reflect.Struct:
+		m, ok := val.(MapValue)
+		if !ok {
+			return unexpectedType(val, TYPE_MAP, rv.Type())
+		}
+		var t = rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, ok := fieldName(t.Field(i))
+			if !ok {
+				continue
+			}
+			e, ok := m[name]
+			if !ok {
+				continue
+			}
+			if err := unmarshalValue(e, rv.Field(i)); err != nil {
+				return errors.Context(err, "struct-field '"+t.Field(i).Name+"'")
+			}
+		}
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshalValue(val, rv.Elem())
+	default:
+		return errors.Context(errors.ErrUnsupported, "cannot unmarshal into data-type "+rv.Type().String())
+	}
+	return nil
+}
+
+// fixedWidth returns the number of bytes in the fixed-width representation of integer kinds.
+func fixedWidth(kind reflect.Kind) int {
+	switch kind {
+	case reflect.Int8, reflect.Uint8:
+		return 1
+	case reflect.Int16, reflect.Uint16:
+		return 2
+	case reflect.Int32, reflect.Uint32:
+		return 4
+	case reflect.Int64, reflect.Uint64, reflect.Int, reflect.Uint:
+		return 8
+	default:
+		panic("BUG: fixed-width only defined for integer kinds")
+	}
+}
+
+// expectBytes checks that `val` is a bytes-value of `width` bytes, or any width if `width < 0`.
+func expectBytes(val Value, target reflect.Type, width int) (Bytes, error) {
+	b, ok := val.(Bytes)
+	if !ok {
+		return nil, unexpectedType(val, TYPE_BYTES, target)
+	}
+	if width >= 0 && len(b) != width {
+		return nil, errors.Context(errors.ErrIllegal, "unexpected number of bytes for "+target.String())
+	}
+	return b, nil
+}
+
+func unexpectedType(val Value, expected CompositeType, target reflect.Type) error {
+	return errors.Context(errors.ErrIllegal, "expected "+expected.String()+"-value for "+target.String()+
+		", got "+typeOf(val).String())
+}
+
+// toUint64 interprets the fixed-width bytes, of 1, 2, 4 or 8 bytes, as big-endian unsigned integer.
+func toUint64(b Bytes) uint64 {
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(bigendian.ToUint16(b[0], b[1]))
+	case 4:
+		return uint64(bigendian.ToUint32(b[0], b[1], b[2], b[3]))
+	case 8:
+		return bigendian.ToUint64(b[0], b[1], b[2], b[3], b[4], b[5], b[6], b[7])
+	default:
+		panic("BUG: fixed-width integers are 1, 2, 4 or 8 bytes")
+	}
+}
+
+// toInt64 interprets the fixed-width bytes, of 1, 2, 4 or 8 bytes, as big-endian two's complement signed
+// integer.
+func toInt64(b Bytes) int64 {
+	switch len(b) {
+	case 1:
+		return int64(int8(b[0]))
+	case 2:
+		return int64(bigendian.ToInt16(b[0], b[1]))
+	case 4:
+		return int64(bigendian.ToInt32(b[0], b[1], b[2], b[3]))
+	case 8:
+		return bigendian.ToInt64(b[0], b[1], b[2], b[3], b[4], b[5], b[6], b[7])
+	default:
+		panic("BUG: fixed-width integers are 1, 2, 4 or 8 bytes")
+	}
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+type testServer struct {
+	Host    string
+	Port    uint16   `prefixed:"port"`
+	Weight  int32    `prefixed:"weight,omitempty"`
+	Tags    []string `prefixed:",omitempty"`
+	Secret  string   `prefixed:"-"`
+	Enabled bool     `prefixed:"enabled"`
+	hidden  int
+}
+
+type testConfig struct {
+	Name    string
+	Servers []testServer `prefixed:"servers"`
+	Ratio   float64      `prefixed:"ratio"`
+	Labels  map[string]string
+	Raw     Value
+	Backup  *testServer `prefixed:"backup"`
+	ID      [4]byte     `prefixed:"id"`
+}
+
+func TestMarshalValueConvention(t *testing.T) {
+	var testdata = []struct {
+		value    any
+		expected Value
+	}{
+		{value: true, expected: Bytes{1}},
+		{value: false, expected: Bytes{0}},
+		{value: int8(-1), expected: Bytes{0xff}},
+		{value: uint16(0x0102), expected: Bytes{1, 2}},
+		{value: int32(-2), expected: Bytes{0xff, 0xff, 0xff, 0xfe}},
+		{value: 1, expected: Bytes{0, 0, 0, 0, 0, 0, 0, 1}},
+		{value: uint(258), expected: Bytes{0, 0, 0, 0, 0, 0, 1, 2}},
+		{value: float32(1), expected: Bytes{0x3f, 0x80, 0, 0}},
+		{value: "hello", expected: Bytes("hello")},
+		{value: []byte{1, 2, 3}, expected: Bytes{1, 2, 3}},
+		{value: [2]byte{4, 5}, expected: Bytes{4, 5}},
+		{value: []uint8(nil), expected: Bytes{}},
+		{value: []string{"a", "b"}, expected: SequenceValue{Bytes("a"), Bytes("b")}},
+		{value: map[string]bool{"x": true}, expected: MapValue{"x": Bytes{1}}},
+		{value: &KeyValue{K: "id", V: Bytes("v")}, expected: &KeyValue{K: "id", V: Bytes("v")}},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		v, err := MarshalValue(d.value)
+		assert.Nil(t, err)
+		assert.EqualT(t, d.expected, v)
+	}
+}
+
+func TestMarshalStructTags(t *testing.T) {
+	v, err := MarshalValue(testServer{Host: "localhost", Port: 8080, Secret: "s3cr3t", hidden: 3})
+	assert.Nil(t, err)
+	assert.EqualT[Value](t, MapValue{"Host": Bytes("localhost"), "port": Bytes{0x1f, 0x90}, "enabled": Bytes{0}}, v)
+	v, err = MarshalValue(testServer{Weight: 1, Tags: []string{"x"}})
+	assert.Nil(t, err)
+	m := v.(MapValue)
+	assert.EqualT(t, m["weight"], Value(Bytes{0, 0, 0, 1}))
+	assert.EqualT(t, m["Tags"], Value(SequenceValue{Bytes("x")}))
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	_, err := Marshal(make(chan int))
+	assert.IsError(t, errors.ErrUnsupported, err)
+	_, err = Marshal(map[int]string{1: "a"})
+	assert.IsError(t, errors.ErrUnsupported, err)
+	_, err = Marshal(nil)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = Marshal([]*testServer{nil})
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestMarshalUnmarshalRoundtrip(t *testing.T) {
+	var original = testConfig{
+		Name: "production",
+		Servers: []testServer{
+			{Host: "alpha", Port: 1, Weight: -5, Enabled: true},
+			{Host: "beta", Port: 65535, Tags: []string{"primary", "eu"}},
+		},
+		Ratio:  0.75,
+		Labels: map[string]string{"team": "ops"},
+		Raw:    SequenceValue{Bytes("raw")},
+		Backup: &testServer{Host: "gamma", Port: 2},
+		ID:     [4]byte{1, 2, 3, 4},
+	}
+	data, err := Marshal(original)
+	assert.Nil(t, err)
+	var result testConfig
+	assert.Nil(t, Unmarshal(data, &result))
+	assert.Equal(t, original.Name, result.Name)
+	assert.Equal(t, len(original.Servers), len(result.Servers))
+	assert.Equal(t, original.Servers[0].Host, result.Servers[0].Host)
+	assert.Equal(t, original.Servers[0].Weight, result.Servers[0].Weight)
+	assert.Equal(t, original.Servers[0].Enabled, result.Servers[0].Enabled)
+	assert.Equal(t, original.Servers[1].Port, result.Servers[1].Port)
+	assert.SlicesEqual(t, original.Servers[1].Tags, result.Servers[1].Tags)
+	assert.Equal(t, original.Ratio, result.Ratio)
+	assert.Equal(t, "ops", result.Labels["team"])
+	assert.EqualT(t, original.Raw, result.Raw)
+	assert.Equal(t, original.Backup.Host, result.Backup.Host)
+	assert.Equal(t, original.Backup.Port, result.Backup.Port)
+	assert.Equal(t, original.ID, result.ID)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var n uint32
+	data, err := Marshal(uint16(1))
+	assert.Nil(t, err)
+	assert.IsError(t, errors.ErrIllegal, Unmarshal(data, &n))
+	assert.IsError(t, errors.ErrIllegal, Unmarshal(data, n))
+	var s []string
+	assert.IsError(t, errors.ErrIllegal, Unmarshal(data, &s))
+	var b bool
+	assert.IsError(t, errors.ErrIllegal, Unmarshal([]byte{1 | FLAG_TERMINATION, 2}, &b))
+	assert.IsError(t, errors.ErrIllegal, Unmarshal([]byte{1 | FLAG_TERMINATION, 1, 0}, &b))
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	var buffer bytes.Buffer
+	enc := NewEncoder(&buffer)
+	assert.Nil(t, enc.Encode("first"))
+	assert.Nil(t, enc.Encode(testServer{Host: "second", Port: 2}))
+	dec := NewDecoder(&buffer)
+	var first string
+	assert.Nil(t, dec.Decode(&first))
+	assert.Equal(t, "first", first)
+	var second testServer
+	assert.Nil(t, dec.Decode(&second))
+	assert.Equal(t, "second", second.Host)
+	assert.Equal(t, 2, second.Port)
+	assert.Equal(t, 0, buffer.Len())
+}
+
+// customValue is a user-implemented `Value`, i.e. not one of the package's value-types.
+type customValue struct{ Bytes }
+
+func TestUnmarshalCustomValue(t *testing.T) {
+	var n uint16
+	assert.IsError(t, errors.ErrIllegal, UnmarshalValue(customValue{Bytes{0, 1}}, &n))
+	var p testPoint
+	assert.IsError(t, errors.ErrIllegal, UnmarshalValue(customValue{Bytes{0, 1}}, &p))
+	var v Value
+	assert.Nil(t, UnmarshalValue(customValue{Bytes{0, 1}}, &v))
+	custom, ok := v.(customValue)
+	assert.True(t, ok)
+	assert.SlicesEqual(t, Bytes{0, 1}, custom.Bytes)
+}
+
+func TestUnmarshalIntegers(t *testing.T) {
+	var i8, i16, i32, i64 = int8(-2), int16(-300), int32(-70000), int64(-5000000000)
+	var u = uint(1 << 40)
+	var i = -1
+	data, err := Marshal([]any{i8, i16, i32, i64, u, i})
+	assert.Nil(t, err)
+	var result struct {
+		A int8
+		B int16
+		C int32
+		D int64
+		E uint
+		F int
+	}
+	var seq []Value
+	assert.Nil(t, Unmarshal(data, &seq))
+	assert.Nil(t, UnmarshalValue(seq[0], &result.A))
+	assert.Nil(t, UnmarshalValue(seq[1], &result.B))
+	assert.Nil(t, UnmarshalValue(seq[2], &result.C))
+	assert.Nil(t, UnmarshalValue(seq[3], &result.D))
+	assert.Nil(t, UnmarshalValue(seq[4], &result.E))
+	assert.Nil(t, UnmarshalValue(seq[5], &result.F))
+	assert.Equal(t, i8, result.A)
+	assert.Equal(t, i16, result.B)
+	assert.Equal(t, i32, result.C)
+	assert.Equal(t, i64, result.D)
+	assert.Equal(t, u, result.E)
+	assert.Equal(t, i, result.F)
+}
@@ -24,6 +24,42 @@ const (
 	TYPE_MAP
 )
 
+// typeUnknown is the composite type of values that are not one of the package's value-types, e.g. custom
+// implementations of `Value`.
+const typeUnknown CompositeType = 0xff
+
+// String returns a human-readable name for the composite type.
+func (t CompositeType) String() string {
+	switch t {
+	case TYPE_BYTES:
+		return "bytes"
+	case TYPE_KEYVALUE:
+		return "key-value"
+	case TYPE_SEQUENCE:
+		return "sequence"
+	case TYPE_MAP:
+		return "map"
+	default:
+		return "unknown"
+	}
+}
+
+// typeOf determines the composite type of a value. Returns `typeUnknown` for values of other types.
+func typeOf(v Value) CompositeType {
+	switch v.(type) {
+	case Bytes:
+		return TYPE_BYTES
+	case *KeyValue:
+		return TYPE_KEYVALUE
+	case SequenceValue:
+		return TYPE_SEQUENCE
+	case MapValue:
+		return TYPE_MAP
+	default:
+		return typeUnknown
+	}
+}
+
 type Header struct {
 	Vtype      CompositeType
 	Size       uint16
@@ -31,10 +67,16 @@ type Header struct {
 }
 
 func ReadHeader(in io.Reader) (Header, error) {
+	h, _, err := readHeader(in)
+	return h, err
+}
+
+// readHeader reads the header, additionally returning the number of bytes of the header.
+func readHeader(in io.Reader) (Header, uint, error) {
 	var err error
 	var b byte
 	if b, err = io_.ReadByte(in); err != nil {
-		return Header{}, err
+		return Header{}, 0, err
 	}
 	var vtype CompositeType
 	if b&FLAG_KEYVALUE == FLAG_KEYVALUE {
@@ -46,15 +88,15 @@ func ReadHeader(in io.Reader) (Header, error) {
 	var term = b&FLAG_TERMINATION == FLAG_TERMINATION
 	var size = uint16(b & MASK_SIZEBITS)
 	if b&FLAG_HEADERSIZE == 0 {
-		return Header{vtype, size, term}, nil
+		return Header{vtype, size, term}, 1, nil
 	}
 	if b, err = io_.ReadByte(in); err != nil {
-		return Header{}, err
+		return Header{}, 0, err
 	}
 	size <<= 8
 	size |= uint16(b)
 	size += SIZE_2BYTE_OFFSET
-	return Header{vtype, size, term}, nil
+	return Header{vtype, size, term}, 2, nil
 }
 
 func readOrCopyHeader(in io.Reader, _hdr *Header) (Header, error) {
@@ -78,24 +120,53 @@ func ParseHeader(data []byte) (uint, Header) {
 // ReadBytes reads a plain byte-array. A header `_hdr` may be provided if it was already read, or if `nil` is
 // provided, then the header is first read from `in`.
 func ReadBytes(in io.Reader, _hdr *Header) (Bytes, error) {
+	return Limits{}.ReadBytes(in, _hdr)
+}
+
+func (d *decoding) readBytes(_hdr *Header) (Bytes, error) {
 	var h Header
 	var err error
-	if h, err = readOrCopyHeader(in, _hdr); err != nil {
+	if h, err = readOrCopyHeader(d, _hdr); err != nil {
 		return nil, err
 	} else if h.Vtype != TYPE_BYTES {
 		return nil, errors.ErrIllegal
 	}
+	var b []byte
+	if b, err = d.readRaw(h); err != nil {
+		return nil, err
+	}
+	return Bytes(b), nil
+}
+
+// readRaw reads the raw bytes of a bytes-value or key, including any continuation records.
+func (d *decoding) readRaw(h Header) ([]byte, error) {
+	var vtype = h.Vtype
 	var b bytes.Buffer
-	for {
-		if _, err = io.CopyN(&b, in, int64(h.Size)); err != nil {
+	var err error
+	for first := true; ; first = false {
+		if err = d.size(uint(b.Len()), h.Size); err != nil {
+			return nil, err
+		}
+		if err = d.record(h, !first); err != nil {
 			return nil, err
 		}
+		if first && h.Terminated && d.borrow != nil {
+			// Single record, therefore the raw bytes are present as-is in the input-data.
+			var start = d.read
+			if _, err = io_.DiscardN(d, int64(h.Size)); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			return d.borrow[start:d.read:d.read], nil
+		}
+		if _, err = io.CopyN(&b, d, int64(h.Size)); err != nil {
+			return nil, unexpectedEOF(err)
+		}
 		if h.Terminated {
-			return Bytes(bytes.Clone(b.Bytes())), nil
+			return bytes.Clone(b.Bytes()), nil
 		}
-		if h, err = ReadHeader(in); err != nil {
-			return nil, err
-		} else if h.Vtype != TYPE_BYTES {
+		if h, err = d.header(); err != nil {
+			return nil, unexpectedEOF(err)
+		} else if h.Vtype != vtype {
 			return nil, errors.ErrIllegal
 		}
 	}
@@ -105,79 +176,112 @@ func ReadBytes(in io.Reader, _hdr *Header) (Bytes, error) {
 // - data: input-data
 // - _hdr: the header is first read if it is not already provided.
 func ParseBytes(data []byte, _hdr *Header) (uint, Bytes) {
-	var in = bytes.NewReader(data)
-	v, err := ReadBytes(in, _hdr)
+	n, v, err := Limits{}.ParseBytes(data, _hdr)
+	if err != nil {
+		return 0, nil
+	}
+	return n, v
+}
+
+// ParseBytesNoCopy reads plain bytes, without copying. See `ParseValueNoCopy`.
+// - data: input-data
+// - _hdr: the header is first read if it is not already provided.
+func ParseBytesNoCopy(data []byte, _hdr *Header) (uint, Bytes) {
+	n, v, err := Limits{}.ParseBytesNoCopy(data, _hdr)
 	if err != nil {
 		return 0, nil
 	}
-	return uint(in.Size() - int64(in.Len())), v
+	return n, v
 }
 
 func ReadKeyValue(in io.Reader, _hdr *Header) (*KeyValue, error) {
+	return Limits{}.ReadKeyValue(in, _hdr)
+}
+
+func (d *decoding) readKeyValue(_hdr *Header) (*KeyValue, error) {
 	var h Header
 	var err error
-	if h, err = readOrCopyHeader(in, _hdr); err != nil {
+	if h, err = readOrCopyHeader(d, _hdr); err != nil {
 		return nil, err
 	} else if h.Vtype != TYPE_KEYVALUE {
 		return nil, errors.ErrIllegal
 	}
-	var keybuffer bytes.Buffer
-	for {
-		var chunk []byte
-		if chunk, err = io_.ReadN(in, uint(h.Size)); err != nil {
-			return nil, err
-		}
-		keybuffer.Write(chunk)
-		if h.Terminated {
-			break
-		}
-		if h, err = ReadHeader(in); err != nil {
-			return nil, err
-		} else if h.Vtype != TYPE_KEYVALUE {
-			return nil, errors.ErrIllegal
-		}
+	if err = d.enter(); err != nil {
+		return nil, err
 	}
-	var val Value
-	if val, err = ReadValue(in); err != nil {
+	defer d.leave()
+	return d.readEntry(h)
+}
+
+// readEntry reads the key and value of a key-value-pair, given the header of the key.
+func (d *decoding) readEntry(h Header) (*KeyValue, error) {
+	var key []byte
+	var err error
+	if key, err = d.readRaw(h); err != nil {
 		return nil, err
 	}
-	return &KeyValue{K: string(bytes.Clone(keybuffer.Bytes())), V: val}, nil
+	var val Value
+	if val, err = d.readValue(nil); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return &KeyValue{K: string(key), V: val}, nil
 }
 
 // ParseKeyValue reads the key-value from input-data.
 // - data: input-data
 // - _hdr: the header is first read if it is not already provided.
 func ParseKeyValue(data []byte, _hdr *Header) (uint, *KeyValue) {
-	var in = bytes.NewReader(data)
-	v, err := ReadKeyValue(in, _hdr)
+	n, v, err := Limits{}.ParseKeyValue(data, _hdr)
 	if err != nil {
 		return 0, nil
 	}
-	return uint(in.Size() - int64(in.Len())), v
+	return n, v
+}
+
+// ParseKeyValueNoCopy reads the key-value from input-data, without copying. See `ParseValueNoCopy`.
+// - data: input-data
+// - _hdr: the header is first read if it is not already provided.
+func ParseKeyValueNoCopy(data []byte, _hdr *Header) (uint, *KeyValue) {
+	n, v, err := Limits{}.ParseKeyValueNoCopy(data, _hdr)
+	if err != nil {
+		return 0, nil
+	}
+	return n, v
 }
 
 func ReadSequence(in io.Reader, _hdr *Header) (SequenceValue, error) {
+	return Limits{}.ReadSequence(in, _hdr)
+}
+
+func (d *decoding) readSequence(_hdr *Header) (SequenceValue, error) {
 	var h Header
 	var err error
-	if h, err = readOrCopyHeader(in, _hdr); err != nil {
+	if h, err = readOrCopyHeader(d, _hdr); err != nil {
 		return nil, err
 	} else if h.Vtype != TYPE_SEQUENCE {
 		return nil, errors.ErrIllegal
 	}
+	if err = d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
 	var entries = make([]Value, 0, h.Size)
 	for {
 		for i := uint16(0); i < h.Size; i++ {
-			entry, err := ReadValue(in)
-			if err != nil {
+			if err = d.entry(); err != nil {
 				return nil, err
 			}
+			entry, err := d.readValue(nil)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
 			entries = append(entries, entry)
 		}
 		if h.Terminated {
 			return entries, nil
 		}
-		if h, err = ReadHeader(in); err != nil {
-			return nil, err
+		if h, err = ReadHeader(d); err != nil {
+			return nil, unexpectedEOF(err)
 		} else if h.Vtype != TYPE_SEQUENCE {
 			return nil, errors.ErrIllegal
 		}
@@ -188,28 +292,55 @@ func ReadSequence(in io.Reader, _hdr *Header) (SequenceValue, error) {
 // - data: input-data
 // - _hdr: the header is first read if it is not already provided.
 func ParseSequence(data []byte, _hdr *Header) (uint, SequenceValue) {
-	var in = bytes.NewReader(data)
-	v, err := ReadSequence(in, _hdr)
+	n, v, err := Limits{}.ParseSequence(data, _hdr)
 	if err != nil {
 		return 0, nil
 	}
-	return uint(in.Size() - int64(in.Len())), v
+	return n, v
+}
+
+// ParseSequenceNoCopy reads a sequence-value from input-data, without copying. See `ParseValueNoCopy`.
+// - data: input-data
+// - _hdr: the header is first read if it is not already provided.
+func ParseSequenceNoCopy(data []byte, _hdr *Header) (uint, SequenceValue) {
+	n, v, err := Limits{}.ParseSequenceNoCopy(data, _hdr)
+	if err != nil {
+		return 0, nil
+	}
+	return n, v
 }
 
 // TODO map assumes distinct keys, hence count is exact number of map entries.
 func ReadMap(in io.Reader, _hdr *Header) (MapValue, error) {
+	return Limits{}.ReadMap(in, _hdr)
+}
+
+func (d *decoding) readMap(_hdr *Header) (MapValue, error) {
 	var h Header
 	var err error
-	if h, err = readOrCopyHeader(in, _hdr); err != nil {
+	if h, err = readOrCopyHeader(d, _hdr); err != nil {
 		return nil, err
 	} else if h.Vtype != TYPE_MAP {
 		return nil, errors.ErrIllegal
 	}
+	if err = d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
 	entries := make(map[string]Value, h.Size)
 	var v *KeyValue
 	for {
 		for i := uint16(0); i < h.Size; i++ {
-			if v, err = ReadKeyValue(in, nil); err != nil {
+			if err = d.entry(); err != nil {
+				return nil, err
+			}
+			var kh Header
+			if kh, err = ReadHeader(d); err != nil {
+				return nil, unexpectedEOF(err)
+			} else if kh.Vtype != TYPE_KEYVALUE {
+				return nil, errors.ErrIllegal
+			}
+			if v, err = d.readEntry(kh); err != nil {
 				return nil, err
 			}
 			entries[v.K] = v.V
@@ -217,8 +348,8 @@ func ReadMap(in io.Reader, _hdr *Header) (MapValue, error) {
 		if h.Terminated {
 			return entries, nil
 		}
-		if h, err = ReadHeader(in); err != nil {
-			return nil, err
+		if h, err = ReadHeader(d); err != nil {
+			return nil, unexpectedEOF(err)
 		} else if h.Vtype != TYPE_MAP {
 			return nil, errors.ErrIllegal
 		}
@@ -229,44 +360,78 @@ func ReadMap(in io.Reader, _hdr *Header) (MapValue, error) {
 // - data: input-data
 // - _hdr: the header is first read if it is not already provided.
 func ParseMap(data []byte, _hdr *Header) (uint, MapValue) {
-	var in = bytes.NewReader(data)
-	v, err := ReadMap(in, _hdr)
+	n, v, err := Limits{}.ParseMap(data, _hdr)
+	if err != nil {
+		return 0, nil
+	}
+	return n, v
+}
+
+// ParseMapNoCopy reads a map-value from input-data, without copying. See `ParseValueNoCopy`.
+// - data: input-data
+// - _hdr: the header is first read if it is not already provided.
+func ParseMapNoCopy(data []byte, _hdr *Header) (uint, MapValue) {
+	n, v, err := Limits{}.ParseMapNoCopy(data, _hdr)
 	if err != nil {
 		return 0, nil
 	}
-	return uint(in.Size() - int64(in.Len())), v
+	return n, v
 }
 
 func ReadValue(in io.Reader) (Value, error) {
-	var err error
+	return Limits{}.ReadValue(in)
+}
+
+// readValue reads the value corresponding to the header. If `_hdr` is nil, the header is read first.
+func (d *decoding) readValue(_hdr *Header) (Value, error) {
 	var h Header
-	h, err = ReadHeader(in)
-	if err != nil {
+	var err error
+	if h, err = readOrCopyHeader(d, _hdr); err != nil {
 		return nil, err
 	}
 	switch h.Vtype {
 	case TYPE_BYTES:
-		return ReadBytes(in, &h)
+		return d.readBytes(&h)
 	case TYPE_KEYVALUE:
-		return ReadKeyValue(in, &h)
+		return d.readKeyValue(&h)
 	case TYPE_SEQUENCE:
-		return ReadSequence(in, &h)
+		return d.readSequence(&h)
 	case TYPE_MAP:
-		return ReadMap(in, &h)
+		return d.readMap(&h)
 	default:
 		panic("BUG: should not be reached")
 	}
 }
 
-// ParseValue reads a value of any type from input-data.
+// ParseValue reads a value of any type from input-data. Bytes-values are copied, i.e. the value does not
+// share memory with `data`. See `ParseValueNoCopy` for the zero-copy variant.
 // - data: input-data
-// TODO currently borrows data from input-array when constructing types, i.e. no cloning.
 // TODO future: add support for custom mapping of type-to-readFunction mapping for custom types
 func ParseValue(data []byte) (uint, Value) {
-	var in = bytes.NewReader(data)
-	v, err := ReadValue(in)
+	n, v, err := Limits{}.ParseValue(data)
 	if err != nil {
 		return 0, nil
 	}
-	return uint(in.Size() - int64(in.Len())), v
+	return n, v
+}
+
+// ParseValueNoCopy reads a value of any type from input-data, without copying. Bytes-values alias
+// subslices of `data`, unless split across (continuation) records. Consequently, `data` must not be
+// modified while the value is in use. The aliased subslices are capacity-limited, such that appending to
+// a bytes-value does not overwrite `data`. (Keys are always copied, as keys are strings.)
+// - data: input-data
+func ParseValueNoCopy(data []byte) (uint, Value) {
+	n, v, err := Limits{}.ParseValueNoCopy(data)
+	if err != nil {
+		return 0, nil
+	}
+	return n, v
+}
+
+// unexpectedEOF converts `io.EOF` into `io.ErrUnexpectedEOF`, for use when a value is partially read.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
 }
@@ -92,3 +92,61 @@ func TestParseMap(t *testing.T) {
 		// FIXME could use a assert.EqualMaps
 	}
 }
+
+func TestParseValueNoCopy(t *testing.T) {
+	var b bytes.Buffer
+	var large = make([]byte, 5000)
+	_, err := SequenceValue{Bytes("hello"), Bytes(large), MapValue{"k": Bytes("v")}}.WriteTo(&b)
+	assert.Nil(t, err)
+	var data = b.Bytes()
+	n, v := ParseValueNoCopy(data)
+	assert.Equal(t, uint(len(data)), n)
+	var seq = v.(SequenceValue)
+	assert.True(t, SequenceValue{Bytes("hello"), Bytes(large), MapValue{"k": Bytes("v")}}.Equal(seq))
+	// single-record bytes alias the input-data, split bytes do not
+	data[2] = 'j'
+	assert.SlicesEqual(t, []byte("jello"), seq[0].(Bytes))
+	assert.Equal(t, 5, cap(seq[0].(Bytes)))
+	data[9] = 0xff
+	assert.Equal(t, 0, seq[1].(Bytes)[0])
+	n, v = ParseValueNoCopy(data[:len(data)-1])
+	assert.Equal(t, 0, n)
+	assert.Nil(t, v)
+}
+
+func TestParseNoCopyTyped(t *testing.T) {
+	var b bytes.Buffer
+	_, err := SequenceValue{Bytes("hello")}.WriteTo(&b)
+	assert.Nil(t, err)
+	var data = bytes.Clone(b.Bytes())
+	n, seq := ParseSequenceNoCopy(data, nil)
+	assert.Equal(t, uint(len(data)), n)
+	data[2] = 'j'
+	assert.SlicesEqual(t, []byte("jello"), seq[0].(Bytes))
+	// with header already read, the data starts after the header
+	h, err := ReadHeader(bytes.NewReader(data[1:]))
+	assert.Nil(t, err)
+	n, bytesv := ParseBytesNoCopy(data[2:], &h)
+	assert.Equal(t, 5, n)
+	data[2] = 'h'
+	assert.SlicesEqual(t, []byte("hello"), bytesv)
+	b.Reset()
+	_, err = MapValue{"k": Bytes("v")}.WriteTo(&b)
+	assert.Nil(t, err)
+	data = bytes.Clone(b.Bytes())
+	n, m := ParseMapNoCopy(data, nil)
+	assert.Equal(t, uint(len(data)), n)
+	data[len(data)-1] = 'w'
+	assert.SlicesEqual(t, []byte("w"), m["k"].(Bytes))
+	b.Reset()
+	_, err = (&KeyValue{K: "k", V: Bytes("v")}).WriteTo(&b)
+	assert.Nil(t, err)
+	data = bytes.Clone(b.Bytes())
+	n, kv := ParseKeyValueNoCopy(data, nil)
+	assert.Equal(t, uint(len(data)), n)
+	data[len(data)-1] = 'w'
+	assert.SlicesEqual(t, []byte("w"), kv.V.(Bytes))
+	n, kv = ParseKeyValueNoCopy(data[:len(data)-1], nil)
+	assert.Equal(t, 0, n)
+	assert.True(t, kv == nil)
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package scalar provides constructors and accessors for common conventions of interpreting prefixed-compact
+// bytes-values as scalars. The conventions match those of (un)marshalling in package prefixed:
+//   - integers: fixed-width, big-endian, two's complement for signed integers,
+//   - floating-point numbers: IEEE-754, big-endian,
+//   - booleans: single byte, 0 (false) or 1 (true),
+//   - strings: UTF-8.
+//
+// Additionally, integers can be compactsize-encoded and timestamps represented either as RFC3339 text or as
+// unix-time in nanoseconds.
+//
+// Accessors accept any `prefixed.Value`, such that results from e.g. map lookups can be passed directly.
+// Accessors return `errors.ErrIllegal` (with context) if the value is not a bytes-value or is of wrong
+// length.
+package scalar
+
+import (
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cobratbq/goutils/codec/bytes/bigendian"
+	"github.com/cobratbq/goutils/codec/bytes/compactsize"
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// bytesOf returns the bytes of a bytes-value, checking the length if `width >= 0`.
+func bytesOf(v prefixed.Value, width int) (prefixed.Bytes, error) {
+	b, ok := v.(prefixed.Bytes)
+	if !ok {
+		return nil, errors.Context(errors.ErrIllegal, "expected bytes-value")
+	}
+	if width >= 0 && len(b) != width {
+		return nil, errors.Context(errors.ErrIllegal, "expected "+strconv.Itoa(width)+" bytes, got "+
+			strconv.Itoa(len(b)))
+	}
+	return b, nil
+}
+
+// FromUint8 creates a 1-byte value.
+func FromUint8(v uint8) prefixed.Bytes {
+	return prefixed.Bytes{v}
+}
+
+// ToUint8 interprets a 1-byte value.
+func ToUint8(v prefixed.Value) (uint8, error) {
+	b, err := bytesOf(v, 1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// FromUint16 creates a 2-byte big-endian value.
+func FromUint16(v uint16) prefixed.Bytes {
+	var b = bigendian.FromUint16(v)
+	return b[:]
+}
+
+// ToUint16 interprets a 2-byte big-endian value.
+func ToUint16(v prefixed.Value) (uint16, error) {
+	b, err := bytesOf(v, 2)
+	if err != nil {
+		return 0, err
+	}
+	return bigendian.ToUint16(b[0], b[1]), nil
+}
+
+// FromUint32 creates a 4-byte big-endian value.
+func FromUint32(v uint32) prefixed.Bytes {
+	var b = bigendian.FromUint32(v)
+	return b[:]
+}
+
+// ToUint32 interprets a 4-byte big-endian value.
+func ToUint32(v prefixed.Value) (uint32, error) {
+	b, err := bytesOf(v, 4)
+	if err != nil {
+		return 0, err
+	}
+	return bigendian.ToUint32(b[0], b[1], b[2], b[3]), nil
+}
+
+// FromUint64 creates an 8-byte big-endian value.
+func FromUint64(v uint64) prefixed.Bytes {
+	var b = bigendian.FromUint64(v)
+	return b[:]
+}
+
+// ToUint64 interprets an 8-byte big-endian value.
+func ToUint64(v prefixed.Value) (uint64, error) {
+	b, err := bytesOf(v, 8)
+	if err != nil {
+		return 0, err
+	}
+	return bigendian.ToUint64(b[0], b[1], b[2], b[3], b[4], b[5], b[6], b[7]), nil
+}
+
+// FromInt8 creates a 1-byte two's complement value.
+func FromInt8(v int8) prefixed.Bytes {
+	return FromUint8(uint8(v))
+}
+
+// ToInt8 interprets a 1-byte two's complement value.
+func ToInt8(v prefixed.Value) (int8, error) {
+	u, err := ToUint8(v)
+	return int8(u), err
+}
+
+// FromInt16 creates a 2-byte big-endian two's complement value.
+func FromInt16(v int16) prefixed.Bytes {
+	return FromUint16(uint16(v))
+}
+
+// ToInt16 interprets a 2-byte big-endian two's complement value.
+func ToInt16(v prefixed.Value) (int16, error) {
+	u, err := ToUint16(v)
+	return int16(u), err
+}
+
+// FromInt32 creates a 4-byte big-endian two's complement value.
+func FromInt32(v int32) prefixed.Bytes {
+	return FromUint32(uint32(v))
+}
+
+// ToInt32 interprets a 4-byte big-endian two's complement value.
+func ToInt32(v prefixed.Value) (int32, error) {
+	u, err := ToUint32(v)
+	return int32(u), err
+}
+
+// FromInt64 creates an 8-byte big-endian two's complement value.
+func FromInt64(v int64) prefixed.Bytes {
+	return FromUint64(uint64(v))
+}
+
+// ToInt64 interprets an 8-byte big-endian two's complement value.
+func ToInt64(v prefixed.Value) (int64, error) {
+	u, err := ToUint64(v)
+	return int64(u), err
+}
+
+// FromCompactSize creates a compactsize-encoded value, of 1, 3, 5 or 9 bytes.
+func FromCompactSize(v uint64) prefixed.Bytes {
+	return compactsize.EncodeUint64(v)
+}
+
+// ToCompactSize interprets a compactsize-encoded value. The encoding must span the full value.
+func ToCompactSize(v prefixed.Value) (uint64, error) {
+	b, err := bytesOf(v, -1)
+	if err != nil {
+		return 0, err
+	}
+	value, n := compactsize.DecodeUint64(b)
+	if n == 0 || n != uint(len(b)) {
+		return 0, errors.Context(errors.ErrIllegal, "expected compactsize-encoding spanning "+
+			strconv.Itoa(len(b))+" bytes")
+	}
+	return value, nil
+}
+
+// FromFloat32 creates a 4-byte IEEE-754 big-endian value.
+func FromFloat32(v float32) prefixed.Bytes {
+	return FromUint32(math.Float32bits(v))
+}
+
+// ToFloat32 interprets a 4-byte IEEE-754 big-endian value.
+func ToFloat32(v prefixed.Value) (float32, error) {
+	u, err := ToUint32(v)
+	return math.Float32frombits(u), err
+}
+
+// FromFloat64 creates an 8-byte IEEE-754 big-endian value.
+func FromFloat64(v float64) prefixed.Bytes {
+	return FromUint64(math.Float64bits(v))
+}
+
+// ToFloat64 interprets an 8-byte IEEE-754 big-endian value.
+func ToFloat64(v prefixed.Value) (float64, error) {
+	u, err := ToUint64(v)
+	return math.Float64frombits(u), err
+}
+
+// FromBool creates a 1-byte boolean value.
+func FromBool(v bool) prefixed.Bytes {
+	if v {
+		return prefixed.Bytes{1}
+	}
+	return prefixed.Bytes{0}
+}
+
+// ToBool interprets a 1-byte boolean value. Values other than 0 and 1 are illegal.
+func ToBool(v prefixed.Value) (bool, error) {
+	b, err := ToUint8(v)
+	if err != nil {
+		return false, err
+	}
+	if b > 1 {
+		return false, errors.Context(errors.ErrIllegal, "expected boolean value 0 or 1")
+	}
+	return b == 1, nil
+}
+
+// FromString creates a UTF-8 string value. The string is not validated.
+func FromString(v string) prefixed.Bytes {
+	return prefixed.Bytes(v)
+}
+
+// ToString interprets a value as UTF-8 string. Invalid UTF-8 is illegal.
+func ToString(v prefixed.Value) (string, error) {
+	b, err := bytesOf(v, -1)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(b) {
+		return "", errors.Context(errors.ErrIllegal, "expected valid UTF-8")
+	}
+	return string(b), nil
+}
+
+// FromTimeRFC3339 creates a timestamp as RFC3339 text, with nanosecond precision as needed.
+func FromTimeRFC3339(t time.Time) prefixed.Bytes {
+	return prefixed.Bytes(t.Format(time.RFC3339Nano))
+}
+
+// ToTimeRFC3339 interprets a timestamp as RFC3339 text.
+func ToTimeRFC3339(v prefixed.Value) (time.Time, error) {
+	s, err := ToString(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, errors.Context(errors.ErrIllegal, "expected RFC3339 timestamp: "+err.Error())
+	}
+	return t, nil
+}
+
+// FromTimeUnixNano creates a timestamp as unix-time in nanoseconds, as 8-byte big-endian signed integer.
+// Time-zone information is not preserved. The time must be within the range of `time.Time.UnixNano`.
+func FromTimeUnixNano(t time.Time) prefixed.Bytes {
+	return FromInt64(t.UnixNano())
+}
+
+// ToTimeUnixNano interprets a timestamp as unix-time in nanoseconds, resulting in local time.
+func ToTimeUnixNano(v prefixed.Value) (time.Time, error) {
+	ns, err := ToInt64(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, ns), nil
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package scalar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestIntegers(t *testing.T) {
+	assert.SlicesEqual(t, []byte{0xfe}, FromUint8(0xfe))
+	assert.SlicesEqual(t, []byte{0x12, 0x34}, FromUint16(0x1234))
+	assert.SlicesEqual(t, []byte{0x12, 0x34, 0x56, 0x78}, FromUint32(0x12345678))
+	assert.SlicesEqual(t, []byte{0, 0, 0, 0, 0, 0, 0x01, 0x00}, FromUint64(256))
+	assert.SlicesEqual(t, []byte{0xff, 0xfe}, FromInt16(-2))
+	assert.Equal(t, uint8(0xfe), builtin.Expect(ToUint8(FromUint8(0xfe))))
+	assert.Equal(t, uint16(0x1234), builtin.Expect(ToUint16(FromUint16(0x1234))))
+	assert.Equal(t, uint32(0x12345678), builtin.Expect(ToUint32(FromUint32(0x12345678))))
+	assert.Equal(t, uint64(math.MaxUint64), builtin.Expect(ToUint64(FromUint64(math.MaxUint64))))
+	assert.Equal(t, int8(-128), builtin.Expect(ToInt8(FromInt8(-128))))
+	assert.Equal(t, int16(-2), builtin.Expect(ToInt16(FromInt16(-2))))
+	assert.Equal(t, int32(math.MinInt32), builtin.Expect(ToInt32(FromInt32(math.MinInt32))))
+	assert.Equal(t, int64(-1), builtin.Expect(ToInt64(FromInt64(-1))))
+}
+
+func TestCompactSize(t *testing.T) {
+	for _, v := range []uint64{0, 0xfc, 0xfd, 0xffff, 0x10000, math.MaxUint32 + 1, math.MaxUint64} {
+		assert.Equal(t, v, builtin.Expect(ToCompactSize(FromCompactSize(v))))
+	}
+	_, err := ToCompactSize(prefixed.Bytes{0x01, 0x00})
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = ToCompactSize(prefixed.Bytes{0xfd, 0x00})
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = ToCompactSize(prefixed.Bytes{})
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestFloats(t *testing.T) {
+	assert.Equal(t, float32(-1.5), builtin.Expect(ToFloat32(FromFloat32(-1.5))))
+	assert.Equal(t, math.Pi, builtin.Expect(ToFloat64(FromFloat64(math.Pi))))
+	assert.True(t, math.IsInf(builtin.Expect(ToFloat64(FromFloat64(math.Inf(-1)))), -1))
+	assert.SlicesEqual(t, []byte{0x3f, 0xf0, 0, 0, 0, 0, 0, 0}, FromFloat64(1))
+}
+
+func TestBool(t *testing.T) {
+	assert.True(t, builtin.Expect(ToBool(FromBool(true))))
+	assert.False(t, builtin.Expect(ToBool(FromBool(false))))
+	_, err := ToBool(prefixed.Bytes{2})
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "héllo", builtin.Expect(ToString(FromString("héllo"))))
+	_, err := ToString(prefixed.Bytes{0xff})
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestTime(t *testing.T) {
+	var ts = time.Date(2024, 2, 29, 13, 14, 15, 123456789, time.FixedZone("X", 3600))
+	assert.SlicesEqual(t, []byte("2024-02-29T13:14:15.123456789+01:00"), FromTimeRFC3339(ts))
+	assert.True(t, ts.Equal(builtin.Expect(ToTimeRFC3339(FromTimeRFC3339(ts)))))
+	assert.True(t, ts.Equal(builtin.Expect(ToTimeUnixNano(FromTimeUnixNano(ts)))))
+	_, err := ToTimeRFC3339(prefixed.Bytes("yesterday"))
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestWrongLengthOrType(t *testing.T) {
+	var testdata = []func(prefixed.Value) error{
+		func(v prefixed.Value) error { _, err := ToUint8(v); return err },
+		func(v prefixed.Value) error { _, err := ToUint16(v); return err },
+		func(v prefixed.Value) error { _, err := ToUint32(v); return err },
+		func(v prefixed.Value) error { _, err := ToUint64(v); return err },
+		func(v prefixed.Value) error { _, err := ToInt8(v); return err },
+		func(v prefixed.Value) error { _, err := ToInt16(v); return err },
+		func(v prefixed.Value) error { _, err := ToInt32(v); return err },
+		func(v prefixed.Value) error { _, err := ToInt64(v); return err },
+		func(v prefixed.Value) error { _, err := ToFloat32(v); return err },
+		func(v prefixed.Value) error { _, err := ToFloat64(v); return err },
+		func(v prefixed.Value) error { _, err := ToBool(v); return err },
+		func(v prefixed.Value) error { _, err := ToTimeUnixNano(v); return err },
+	}
+	for i, f := range testdata {
+		t.Log("Iteration:", i)
+		assert.IsError(t, errors.ErrIllegal, f(prefixed.Bytes{1, 2, 3}))
+		assert.IsError(t, errors.ErrIllegal, f(prefixed.SequenceValue{}))
+	}
+	_, err := ToString(prefixed.MapValue{})
+	assert.IsError(t, errors.ErrIllegal, err)
+}
@@ -0,0 +1,419 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package schema describes the expected structure of prefixed-compact values, such that message formats can
+// be declared once and malformed input is rejected up front, either as decoded `prefixed.Value` or directly
+// from the (untrusted) input stream.
+package schema
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cobratbq/goutils/assert"
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// ErrSchema indicates that a value does not conform to the schema. The context indicates the path of the
+// offending value, in the notation of `prefixed.Select`.
+var ErrSchema = errors.NewStringError("value does not conform to schema")
+
+type kind uint8
+
+const (
+	kindAny kind = iota
+	kindBytes
+	kindKeyValue
+	kindSequence
+	kindMap
+)
+
+// Schema describes the expected structure of a value. Schemas are constructed using the package functions,
+// e.g. `Map(Required("id", Bytes(16)), Optional("tags", Sequence(AnyBytes())))`.
+type Schema struct {
+	kind kind
+	// min and max bound the length of bytes-values, or the number of entries of sequences.
+	min, max uint
+	// key is the expected key of a key-value-pair.
+	key string
+	// elem is the schema for sequence-entries, or for the value of a key-value-pair.
+	elem   *Schema
+	fields []Field
+	strict bool
+}
+
+// Field describes a map-entry. See `Required` and `Optional`.
+type Field struct {
+	key      string
+	schema   *Schema
+	required bool
+}
+
+// Any accepts any (valid) value.
+func Any() *Schema {
+	return &Schema{kind: kindAny}
+}
+
+// AnyBytes accepts bytes-values of any length.
+func AnyBytes() *Schema {
+	return BytesRange(0, math.MaxUint)
+}
+
+// Bytes accepts bytes-values of exactly `n` bytes.
+func Bytes(n uint) *Schema {
+	return BytesRange(n, n)
+}
+
+// BytesRange accepts bytes-values with length in range `[min, max]`.
+func BytesRange(min, max uint) *Schema {
+	assert.True(min <= max)
+	return &Schema{kind: kindBytes, min: min, max: max}
+}
+
+// Int accepts fixed-width (signed or unsigned) integers of `bits` bits, i.e. bytes-values of `bits/8` bytes,
+// as in (un)marshalling for 8, 16, 32 and 64 bits. `bits` must be a positive multiple of 8, such that widths
+// such as 24 and 48 bits are supported too. Panics for any other value of `bits`.
+func Int(bits uint) *Schema {
+	if bits == 0 || bits%8 != 0 {
+		panic("number of bits must be a positive multiple of 8")
+	}
+	return Bytes(bits / 8)
+}
+
+// KeyValue accepts key-value-pairs with key `key` and a value conforming to `value`.
+func KeyValue(key string, value *Schema) *Schema {
+	return &Schema{kind: kindKeyValue, key: key, elem: value}
+}
+
+// Sequence accepts sequences of any length, with all entries conforming to `elem`.
+func Sequence(elem *Schema) *Schema {
+	return SequenceRange(elem, 0, math.MaxUint)
+}
+
+// SequenceRange accepts sequences with number of entries in range `[min, max]`, with all entries conforming
+// to `elem`.
+func SequenceRange(elem *Schema, min, max uint) *Schema {
+	assert.True(min <= max)
+	return &Schema{kind: kindSequence, elem: elem, min: min, max: max}
+}
+
+// Map accepts maps with the specified fields. Unspecified keys are accepted with any value, such that
+// (newer) formats may add fields.
+func Map(fields ...Field) *Schema {
+	return &Schema{kind: kindMap, fields: fields}
+}
+
+// StrictMap accepts maps with the specified fields. Unspecified keys are rejected.
+func StrictMap(fields ...Field) *Schema {
+	return &Schema{kind: kindMap, fields: fields, strict: true}
+}
+
+// Required describes a map-entry that must be present.
+func Required(key string, schema *Schema) Field {
+	return Field{key: key, schema: schema, required: true}
+}
+
+// Optional describes a map-entry that may be present.
+func Optional(key string, schema *Schema) Field {
+	return Field{key: key, schema: schema}
+}
+
+func (s *Schema) field(key string) *Field {
+	for i := range s.fields {
+		if s.fields[i].key == key {
+			return &s.fields[i]
+		}
+	}
+	return nil
+}
+
+// Validate validates the value against the schema. Returns `ErrSchema` (with context) for the first
+// violation. Values other than the value-types of `prefixed`, including nil, are violations.
+//
+// Duplicate keys cannot be detected, because a decoded map-value contains only the last entry for each key.
+// Use `ValidateReader` or `ValidateDecoder` to reject map-values with duplicate keys.
+func (s *Schema) Validate(v prefixed.Value) error {
+	return s.validate(v, "")
+}
+
+func (s *Schema) validate(v prefixed.Value, path string) error {
+	switch s.kind {
+	case kindAny:
+		switch val := v.(type) {
+		case prefixed.Bytes, prefixed.SequenceValue, prefixed.MapValue:
+			return nil
+		case *prefixed.KeyValue:
+			if val != nil {
+				return nil
+			}
+		}
+		return violation(path, "expected value, got "+valueType(v))
+	case kindBytes:
+		val, ok := v.(prefixed.Bytes)
+		if !ok {
+			return violation(path, "expected bytes-value, got "+valueType(v))
+		}
+		return s.length(path, "bytes", uint(len(val)))
+	case kindKeyValue:
+		val, ok := v.(*prefixed.KeyValue)
+		if !ok || val == nil {
+			return violation(path, "expected key-value-pair, got "+valueType(v))
+		}
+		if val.K != s.key {
+			return violation(path, "expected key "+strconv.Quote(s.key)+", got "+strconv.Quote(val.K))
+		}
+		return s.elem.validate(val.V, keyPath(path, val.K))
+	case kindSequence:
+		val, ok := v.(prefixed.SequenceValue)
+		if !ok {
+			return violation(path, "expected sequence-value, got "+valueType(v))
+		}
+		if err := s.length(path, "entries", uint(len(val))); err != nil {
+			return err
+		}
+		for i, e := range val {
+			if err := s.elem.validate(e, indexPath(path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case kindMap:
+		val, ok := v.(prefixed.MapValue)
+		if !ok {
+			return violation(path, "expected map-value, got "+valueType(v))
+		}
+		for _, f := range s.fields {
+			e, ok := val[f.key]
+			if !ok {
+				if f.required {
+					return violation(keyPath(path, f.key), "required key missing")
+				}
+				continue
+			}
+			if err := f.schema.validate(e, keyPath(path, f.key)); err != nil {
+				return err
+			}
+		}
+		if s.strict {
+			var keys = make([]string, 0, len(val))
+			for k := range val {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if s.field(k) == nil {
+					return violation(keyPath(path, k), "unexpected key")
+				}
+			}
+		}
+		return nil
+	default:
+		panic("BUG: unsupported schema kind")
+	}
+}
+
+// ValidateReader validates the next value from the input against the schema, without decoding the value
+// in memory. See `ValidateDecoder`.
+func (s *Schema) ValidateReader(in io.Reader) error {
+	return s.ValidateDecoder(prefixed.NewDecoder(in))
+}
+
+// ValidateDecoder validates the next value from the decoder against the schema. Use a decoder created by
+// `prefixed.Limits.NewDecoder` to bound resource use for untrusted input. Validation stops at the first
+// violation, leaving the decoder in the middle of the value. Map-values with duplicate keys are rejected.
+func (s *Schema) ValidateDecoder(d *prefixed.Decoder) error {
+	return s.stream(d, "")
+}
+
+func (s *Schema) stream(d *prefixed.Decoder, path string) error {
+	if s.kind == kindAny {
+		return d.Skip()
+	}
+	tok, err := d.Next()
+	if err != nil {
+		return err
+	}
+	switch s.kind {
+	case kindBytes:
+		if tok.Kind != prefixed.TOKEN_BYTES {
+			return violation(path, "expected bytes-value, got "+tokenType(tok.Kind))
+		}
+		var n = uint(len(tok.Data))
+		for !tok.Terminated {
+			if tok, err = d.Next(); err != nil {
+				return err
+			}
+			n += uint(len(tok.Data))
+		}
+		return s.length(path, "bytes", n)
+	case kindKeyValue:
+		if tok.Kind != prefixed.TOKEN_KEY {
+			return violation(path, "expected key-value-pair, got "+tokenType(tok.Kind))
+		}
+		var key []byte
+		if key, err = readKey(d, tok); err != nil {
+			return err
+		}
+		if string(key) != s.key {
+			return violation(path, "expected key "+strconv.Quote(s.key)+", got "+strconv.Quote(string(key)))
+		}
+		return s.elem.stream(d, keyPath(path, s.key))
+	case kindSequence:
+		if tok.Kind != prefixed.TOKEN_START_SEQUENCE {
+			return violation(path, "expected sequence-value, got "+tokenType(tok.Kind))
+		}
+		var n uint
+		for {
+			more, err := d.More()
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			if n >= s.max {
+				return s.length(path, "entries", n+1)
+			}
+			if err = s.elem.stream(d, indexPath(path, int(n))); err != nil {
+				return err
+			}
+			n++
+		}
+		if err = s.length(path, "entries", n); err != nil {
+			return err
+		}
+		_, err = d.Next()
+		return err
+	case kindMap:
+		if tok.Kind != prefixed.TOKEN_START_MAP {
+			return violation(path, "expected map-value, got "+tokenType(tok.Kind))
+		}
+		var seen = make(map[string]struct{})
+		for {
+			more, err := d.More()
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			if tok, err = d.Next(); err != nil {
+				return err
+			}
+			key, err := readKey(d, tok)
+			if err != nil {
+				return err
+			}
+			if _, ok := seen[string(key)]; ok {
+				return violation(keyPath(path, string(key)), "duplicate key")
+			}
+			seen[string(key)] = struct{}{}
+			if f := s.field(string(key)); f != nil {
+				err = f.schema.stream(d, keyPath(path, f.key))
+			} else if s.strict {
+				return violation(keyPath(path, string(key)), "unexpected key")
+			} else {
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, f := range s.fields {
+			if _, ok := seen[f.key]; f.required && !ok {
+				return violation(keyPath(path, f.key), "required key missing")
+			}
+		}
+		_, err = d.Next()
+		return err
+	default:
+		panic("BUG: unsupported schema kind")
+	}
+}
+
+// readKey reads all chunks of a key, given the first key-token.
+func readKey(d *prefixed.Decoder, tok prefixed.Token) ([]byte, error) {
+	var key = bytes.Clone(tok.Data)
+	var err error
+	for !tok.Terminated {
+		if tok, err = d.Next(); err != nil {
+			return nil, err
+		}
+		key = append(key, tok.Data...)
+	}
+	return key, nil
+}
+
+// length verifies that `n` is in the range of the schema. Exceeding the maximum is reported without `n`, as
+// streaming validation stops counting upon exceeding the maximum.
+func (s *Schema) length(path string, unit string, n uint) error {
+	if n < s.min {
+		return violation(path, "expected at least "+strconv.FormatUint(uint64(s.min), 10)+" "+unit+", got "+
+			strconv.FormatUint(uint64(n), 10))
+	}
+	if n > s.max {
+		return violation(path, "expected at most "+strconv.FormatUint(uint64(s.max), 10)+" "+unit)
+	}
+	return nil
+}
+
+// valueType describes the type of the value, for reporting violations.
+func valueType(v prefixed.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case prefixed.Bytes:
+		return "bytes-value"
+	case *prefixed.KeyValue:
+		if v == nil {
+			return "nil"
+		}
+		return "key-value-pair"
+	case prefixed.SequenceValue:
+		return "sequence-value"
+	case prefixed.MapValue:
+		return "map-value"
+	default:
+		return "unsupported value-type"
+	}
+}
+
+func tokenType(k prefixed.TokenKind) string {
+	switch k {
+	case prefixed.TOKEN_BYTES:
+		return "bytes-value"
+	case prefixed.TOKEN_KEY:
+		return "key-value-pair"
+	case prefixed.TOKEN_START_SEQUENCE:
+		return "sequence-value"
+	case prefixed.TOKEN_START_MAP:
+		return "map-value"
+	default:
+		return k.String()
+	}
+}
+
+func violation(path string, msg string) error {
+	if path == "" {
+		return errors.Context(ErrSchema, "at root: "+msg)
+	}
+	return errors.Context(ErrSchema, "at "+path+": "+msg)
+}
+
+func keyPath(path string, key string) string {
+	if key != "" && !strings.ContainsAny(key, ".[]\"") {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+func indexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
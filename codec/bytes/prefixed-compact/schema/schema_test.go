@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package schema
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	prefixed "github.com/cobratbq/goutils/codec/bytes/prefixed-compact"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+var message = StrictMap(
+	Required("id", Bytes(16)),
+	Required("port", Int(16)),
+	Optional("name", BytesRange(1, 32)),
+	Optional("tags", SequenceRange(AnyBytes(), 0, 3)),
+	Optional("version", KeyValue("v", Int(8))),
+	Optional("extra", Map(Required("a.b", Any()))),
+)
+
+// validateBoth validates both in-memory and streaming, expecting the same outcome.
+func validateBoth(t *testing.T, s *Schema, v prefixed.Value) error {
+	err := s.Validate(v)
+	var b bytes.Buffer
+	_, werr := v.WriteTo(&b)
+	assert.Nil(t, werr)
+	serr := s.ValidateReader(&b)
+	if err == nil {
+		assert.Nil(t, serr)
+	} else {
+		assert.Equal(t, err.Error(), serr.Error())
+	}
+	return err
+}
+
+func TestValidateValid(t *testing.T) {
+	var testdata = []prefixed.Value{
+		prefixed.MapValue{"id": prefixed.Bytes(make([]byte, 16)), "port": prefixed.Bytes{0x1f, 0x90}},
+		prefixed.MapValue{
+			"id":      prefixed.Bytes(make([]byte, 16)),
+			"port":    prefixed.Bytes{0x1f, 0x90},
+			"name":    prefixed.Bytes("server"),
+			"tags":    prefixed.SequenceValue{prefixed.Bytes("a"), prefixed.Bytes{}},
+			"version": &prefixed.KeyValue{K: "v", V: prefixed.Bytes{1}},
+			"extra":   prefixed.MapValue{"a.b": prefixed.SequenceValue{}, "other": prefixed.Bytes{}},
+		},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		assert.Nil(t, validateBoth(t, message, d))
+	}
+}
+
+func TestValidateViolations(t *testing.T) {
+	var id = prefixed.Bytes(make([]byte, 16))
+	var port = prefixed.Bytes{0, 80}
+	var testdata = []struct {
+		value prefixed.Value
+		path  string
+	}{
+		{value: prefixed.Bytes{}, path: "at root: expected map-value, got bytes-value"},
+		{value: prefixed.MapValue{"port": port}, path: "at id: required key missing"},
+		{value: prefixed.MapValue{"id": prefixed.Bytes{1}, "port": port}, path: "at id: expected at least 16 bytes, got 1"},
+		{value: prefixed.MapValue{"id": id, "port": prefixed.Bytes{80}}, path: "at port: expected at least 2 bytes, got 1"},
+		{value: prefixed.MapValue{"id": id, "port": port, "name": prefixed.Bytes{}}, path: "at name: expected at least 1 bytes, got 0"},
+		{value: prefixed.MapValue{"id": id, "port": port, "unknown": prefixed.Bytes{}}, path: "at unknown: unexpected key"},
+		{value: prefixed.MapValue{"id": id, "port": port, "tags": prefixed.SequenceValue{prefixed.SequenceValue{}}}, path: "at tags[0]: expected bytes-value, got sequence-value"},
+		{value: prefixed.MapValue{"id": id, "port": port, "tags": prefixed.SequenceValue{prefixed.Bytes{}, prefixed.Bytes{}, prefixed.Bytes{}, prefixed.Bytes{}}}, path: "at tags: expected at most 3 entries"},
+		{value: prefixed.MapValue{"id": id, "port": port, "version": &prefixed.KeyValue{K: "x", V: prefixed.Bytes{1}}}, path: `at version: expected key "v", got "x"`},
+		{value: prefixed.MapValue{"id": id, "port": port, "version": &prefixed.KeyValue{K: "v", V: prefixed.Bytes{}}}, path: "at version.v: expected at least 1 bytes, got 0"},
+		{value: prefixed.MapValue{"id": id, "port": port, "extra": prefixed.MapValue{}}, path: `at extra["a.b"]: required key missing`},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		err := validateBoth(t, message, d.value)
+		assert.IsError(t, ErrSchema, err)
+		assert.True(t, strings.Contains(err.Error(), d.path))
+	}
+}
+
+func TestValidateReaderDuplicateKey(t *testing.T) {
+	var encoded = []byte{2 | prefixed.FLAG_TERMINATION | prefixed.FLAG_MULTIPLICITY | prefixed.FLAG_KEYVALUE,
+		1 | prefixed.FLAG_TERMINATION | prefixed.FLAG_KEYVALUE, 'a', 0 | prefixed.FLAG_TERMINATION,
+		1 | prefixed.FLAG_TERMINATION | prefixed.FLAG_KEYVALUE, 'a', 0 | prefixed.FLAG_TERMINATION}
+	err := Map().ValidateReader(bytes.NewReader(encoded))
+	assert.IsError(t, ErrSchema, err)
+}
+
+func TestValidateReaderTruncated(t *testing.T) {
+	var b bytes.Buffer
+	_, err := prefixed.SequenceValue{prefixed.Bytes("abc")}.WriteTo(&b)
+	assert.Nil(t, err)
+	err = Sequence(AnyBytes()).ValidateReader(bytes.NewReader(b.Bytes()[:b.Len()-1]))
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrSchema))
+}
+
+// customValue is a user-implemented `prefixed.Value`, i.e. not one of the value-types of `prefixed`.
+type customValue struct{ prefixed.Bytes }
+
+func TestValidateUnsupportedValues(t *testing.T) {
+	var id = prefixed.Bytes(make([]byte, 16))
+	var port = prefixed.Bytes{0, 80}
+	var testdata = []struct {
+		schema *Schema
+		value  prefixed.Value
+		msg    string
+	}{
+		{schema: message, value: nil, msg: "at root: expected map-value, got nil"},
+		{schema: message, value: customValue{}, msg: "at root: expected map-value, got unsupported value-type"},
+		{schema: message, value: prefixed.MapValue{"id": nil, "port": port}, msg: "at id: expected bytes-value, got nil"},
+		{schema: message, value: prefixed.MapValue{"id": id, "port": port, "version": (*prefixed.KeyValue)(nil)},
+			msg: "at version: expected key-value-pair, got nil"},
+		{schema: Any(), value: nil, msg: "at root: expected value, got nil"},
+		{schema: Any(), value: customValue{}, msg: "at root: expected value, got unsupported value-type"},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		err := d.schema.Validate(d.value)
+		assert.IsError(t, ErrSchema, err)
+		assert.True(t, strings.Contains(err.Error(), d.msg))
+	}
+}
+
+func TestInt(t *testing.T) {
+	assert.Nil(t, Int(24).Validate(prefixed.Bytes{1, 2, 3}))
+	assert.IsError(t, ErrSchema, Int(64).Validate(prefixed.Bytes{1, 2, 3, 4}))
+}
+
+func TestIntIllegalWidth(t *testing.T) {
+	for _, bits := range []uint{0, 1, 12, 63} {
+		func() {
+			defer assert.RequirePanic(t)
+			Int(bits)
+		}()
+	}
+}
@@ -0,0 +1,288 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// ErrNotFound indicates that the path does not select any value.
+var ErrNotFound = errors.NewStringError("not found")
+
+// segment is a single step in a path: either a key or an index.
+type segment struct {
+	key   string
+	index int
+	// indexed indicates that the segment is an index (into a sequence), rather than a key.
+	indexed bool
+}
+
+func (s segment) String() string {
+	if s.indexed {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	return "[" + strconv.Quote(s.key) + "]"
+}
+
+// parsePath parses a path in selector notation. See `Select`.
+func parsePath(path string) ([]segment, error) {
+	var segments []segment
+	for pos := 0; pos < len(path); {
+		switch {
+		case path[pos] == '[' && pos+1 < len(path) && path[pos+1] == '"':
+			var end = pos + 2
+			for ; end < len(path) && path[end] != '"'; end++ {
+				if path[end] == '\\' {
+					end++
+				}
+			}
+			if end+1 >= len(path) || path[end+1] != ']' {
+				return nil, errors.Context(errors.ErrIllegal, "unterminated quoted key at offset "+strconv.Itoa(pos))
+			}
+			key, err := strconv.Unquote(path[pos+1 : end+1])
+			if err != nil {
+				return nil, errors.Context(errors.ErrIllegal, "invalid quoted key at offset "+strconv.Itoa(pos))
+			}
+			segments = append(segments, segment{key: key})
+			pos = end + 2
+		case path[pos] == '[':
+			var end = strings.IndexByte(path[pos:], ']')
+			if end < 0 {
+				return nil, errors.Context(errors.ErrIllegal, "unterminated index at offset "+strconv.Itoa(pos))
+			}
+			index, err := strconv.ParseUint(path[pos+1:pos+end], 10, 31)
+			if err != nil {
+				return nil, errors.Context(errors.ErrIllegal, "invalid index at offset "+strconv.Itoa(pos))
+			}
+			segments = append(segments, segment{index: int(index), indexed: true})
+			pos += end + 1
+		default:
+			if path[pos] == '.' {
+				if len(segments) == 0 {
+					return nil, errors.Context(errors.ErrIllegal, "path starts with '.'")
+				}
+				pos++
+			}
+			var end = pos
+			for end < len(path) && strings.IndexByte(".[]\"", path[end]) < 0 {
+				end++
+			}
+			if end == pos {
+				return nil, errors.Context(errors.ErrIllegal, "empty key at offset "+strconv.Itoa(pos))
+			}
+			segments = append(segments, segment{key: path[pos:end]})
+			pos = end
+		}
+	}
+	return segments, nil
+}
+
+// Select selects the value at `path`, e.g. `config.servers[2].host`. A path consists of segments:
+//   - `name` or `.name`: select by key, where name consists of any characters other than `.`, `[`, `]`, `"`,
+//   - `[N]`: select by (zero-based) index,
+//   - `["key"]`: select by key, quoted with Go escape-sequences, for keys that are not valid names.
+//
+// A key selects a map-entry, or the value of a key-value-pair with matching key. An index selects a
+// sequence-entry. The empty path selects the root-value. Returns `ErrNotFound` (with context) if no value
+// exists at the path, and `errors.ErrIllegal` if the path is invalid.
+func Select(v Value, path string) (Value, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range segments {
+		var ok bool
+		switch val := v.(type) {
+		case SequenceValue:
+			if ok = s.indexed && s.index < len(val); ok {
+				v = val[s.index]
+			}
+		case MapValue:
+			if !s.indexed {
+				v, ok = val[s.key]
+			}
+		case *KeyValue:
+			if ok = !s.indexed && val.K == s.key; ok {
+				v = val.V
+			}
+		}
+		if !ok {
+			return nil, notFound(segments[:i+1])
+		}
+	}
+	return v, nil
+}
+
+func notFound(segments []segment) error {
+	var b strings.Builder
+	for _, s := range segments {
+		b.WriteString(s.String())
+	}
+	return errors.Context(ErrNotFound, "no value at "+b.String())
+}
+
+// SelectReader selects the value at `path` from the next value in the input. See `Select`. Only the selected
+// value is decoded. Other parts of the value are verified and discarded without allocating, such that
+// the whole value is consumed from the input. In case of duplicate keys in a map-value, the last entry
+// wins, such that the result is equal to selecting from the value read with `ReadValue`.
+func SelectReader(in io.Reader, path string) (Value, error) {
+	return Limits{}.SelectReader(in, path)
+}
+
+func (d *decoding) selectReader(path string) (Value, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := d.header()
+	if err != nil {
+		return nil, err
+	}
+	v, depth, err := d.selectValue(hdr, segments)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if depth < len(segments) {
+		return nil, notFound(segments[:depth+1])
+	}
+	return v, nil
+}
+
+// selectValue consumes the value with provided header, decoding the value at the path of segments. Returns
+// the value, if found, and the number of segments that matched.
+func (d *decoding) selectValue(hdr Header, segments []segment) (Value, int, error) {
+	if len(segments) == 0 {
+		v, err := d.readValue(&hdr)
+		return v, 0, err
+	}
+	var s = segments[0]
+	var v Value
+	var depth int
+	var err error
+	switch hdr.Vtype {
+	case TYPE_BYTES:
+		return nil, 0, d.discardRaw(hdr)
+	case TYPE_KEYVALUE:
+		if err = d.enter(); err != nil {
+			return nil, 0, err
+		}
+		defer d.leave()
+		if v, depth, err = d.selectEntry(hdr, s, segments[1:]); err != nil {
+			return nil, 0, err
+		}
+		return v, depth, nil
+	case TYPE_SEQUENCE, TYPE_MAP:
+		if err = d.enter(); err != nil {
+			return nil, 0, err
+		}
+		defer d.leave()
+		var vtype = hdr.Vtype
+		var index int
+		for {
+			for i := uint16(0); i < hdr.Size; i, index = i+1, index+1 {
+				if err = d.entry(); err != nil {
+					return nil, 0, err
+				}
+				var entryV Value
+				var entryDepth int
+				switch {
+				case vtype == TYPE_MAP:
+					var kh Header
+					if kh, err = d.header(); err != nil {
+						return nil, 0, err
+					} else if kh.Vtype != TYPE_KEYVALUE {
+						return nil, 0, errors.ErrIllegal
+					}
+					entryV, entryDepth, err = d.selectEntry(kh, s, segments[1:])
+				case s.indexed && s.index == index:
+					var h Header
+					if h, err = d.header(); err != nil {
+						return nil, 0, err
+					}
+					entryV, entryDepth, err = d.selectValue(h, segments[1:])
+					entryDepth++
+				default:
+					err = d.verifyValue()
+				}
+				if err != nil {
+					return nil, 0, err
+				}
+				// For duplicate keys, the last entry wins, as is the case for `ReadMap`.
+				if entryDepth > 0 {
+					v, depth = entryV, entryDepth
+				}
+			}
+			if hdr.Terminated {
+				return v, depth, nil
+			}
+			if hdr, err = d.header(); err != nil {
+				return nil, 0, err
+			} else if hdr.Vtype != vtype {
+				return nil, 0, errors.ErrIllegal
+			}
+		}
+	default:
+		panic("BUG: should not be reached")
+	}
+}
+
+// selectEntry consumes the key and value of a key-value-pair, given the header of the key, continuing
+// selection in the value if the key matches segment `s`.
+func (d *decoding) selectEntry(hdr Header, s segment, remainder []segment) (Value, int, error) {
+	var match bool
+	var err error
+	if s.indexed {
+		err = d.discardRaw(hdr)
+	} else {
+		match, err = d.matchRaw(hdr, []byte(s.key))
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if !match {
+		return nil, 0, d.verifyValue()
+	}
+	if hdr, err = d.header(); err != nil {
+		return nil, 0, err
+	}
+	v, depth, err := d.selectValue(hdr, remainder)
+	return v, depth + 1, err
+}
+
+// matchRaw consumes the raw bytes of a key, including continuation records, comparing against `key` without
+// allocating.
+func (d *decoding) matchRaw(hdr Header, key []byte) (bool, error) {
+	var vtype = hdr.Vtype
+	var buffer [64]byte
+	var match = true
+	var total uint
+	var err error
+	for {
+		if err = d.size(total, hdr.Size); err != nil {
+			return false, err
+		}
+		for remaining := uint(hdr.Size); remaining > 0; {
+			var chunk = buffer[:min(remaining, uint(len(buffer)))]
+			if _, err = io.ReadFull(d, chunk); err != nil {
+				return false, unexpectedEOF(err)
+			}
+			match = match && total+uint(len(chunk)) <= uint(len(key)) &&
+				bytes.Equal(key[total:total+uint(len(chunk))], chunk)
+			total += uint(len(chunk))
+			remaining -= uint(len(chunk))
+		}
+		if hdr.Terminated {
+			return match && total == uint(len(key)), nil
+		}
+		if hdr, err = d.header(); err != nil {
+			return false, unexpectedEOF(err)
+		} else if hdr.Vtype != vtype {
+			return false, errors.ErrIllegal
+		}
+	}
+}
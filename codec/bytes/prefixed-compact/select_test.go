@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+var selectDocument = MapValue{
+	"config": MapValue{
+		"servers": SequenceValue{
+			MapValue{"host": Bytes("alpha")},
+			MapValue{"host": Bytes("beta")},
+			MapValue{"host": Bytes("gamma"), "port": Bytes{0x1f, 0x90}},
+		},
+		"a.b":     Bytes("dotted"),
+		"version": &KeyValue{K: "v1", V: Bytes{1}},
+	},
+	"empty": SequenceValue{},
+}
+
+func TestSelect(t *testing.T) {
+	var testdata = []struct {
+		path  string
+		value Value
+	}{
+		{path: "", value: selectDocument},
+		{path: "config.servers[2].host", value: Bytes("gamma")},
+		{path: `["config"].servers[0]["host"]`, value: Bytes("alpha")},
+		{path: `config["a.b"]`, value: Bytes("dotted")},
+		{path: "config.version.v1", value: Bytes{1}},
+		{path: "config.servers[2].port", value: Bytes{0x1f, 0x90}},
+		{path: "empty", value: SequenceValue{}},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i, d.path)
+		v, err := Select(selectDocument, d.path)
+		assert.Nil(t, err)
+		assert.True(t, d.value.Equal(v))
+		var b bytes.Buffer
+		_, err = selectDocument.WriteTo(&b)
+		assert.Nil(t, err)
+		b.Write([]byte{0 | FLAG_TERMINATION})
+		v, err = SelectReader(&b, d.path)
+		assert.Nil(t, err)
+		assert.True(t, d.value.Equal(v))
+		// the whole value is consumed, leaving the input positioned at the next value
+		assert.Equal(t, 1, b.Len())
+	}
+}
+
+func TestSelectNotFound(t *testing.T) {
+	var testdata = []string{
+		"missing",
+		"config.servers[3]",
+		"config.servers[0].port",
+		"config.servers.host",
+		"config[0]",
+		"config.version.v2",
+		"config.servers[0].host.deeper",
+		"empty[0]",
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i, d)
+		_, err := Select(selectDocument, d)
+		assert.IsError(t, ErrNotFound, err)
+		var b bytes.Buffer
+		_, err = selectDocument.WriteTo(&b)
+		assert.Nil(t, err)
+		_, err = SelectReader(&b, d)
+		assert.IsError(t, ErrNotFound, err)
+		assert.Equal(t, 0, b.Len())
+	}
+}
+
+func TestSelectIllegalPath(t *testing.T) {
+	var testdata = []string{".a", "a..b", "a[", "a[x]", "a[-1]", `a["b`, `a["b"`, "a]"}
+	for i, d := range testdata {
+		t.Log("Iteration:", i, d)
+		_, err := Select(selectDocument, d)
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+}
+
+func TestSelectReaderTruncated(t *testing.T) {
+	var b bytes.Buffer
+	_, err := selectDocument.WriteTo(&b)
+	assert.Nil(t, err)
+	_, err = SelectReader(bytes.NewReader(b.Bytes()[:b.Len()-1]), "config.servers[0].host")
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+	_, err = SelectReader(bytes.NewReader(nil), "config")
+	assert.IsError(t, io.EOF, err)
+}
+
+func TestSelectReaderLongKey(t *testing.T) {
+	var key = string(bytes.Repeat([]byte("k"), 5000))
+	var v = MapValue{key: Bytes("found"), key[:4999]: Bytes("prefix"), key + "k": Bytes("longer")}
+	var b bytes.Buffer
+	_, err := v.WriteTo(&b)
+	assert.Nil(t, err)
+	result, err := SelectReader(&b, `["`+key+`"]`)
+	assert.Nil(t, err)
+	assert.True(t, Bytes("found").Equal(result))
+}
+
+func TestSelectReaderDuplicateKeys(t *testing.T) {
+	var b bytes.Buffer
+	_, err := writeHeader(&b, 3, FLAG_KEYVALUE|FLAG_MULTIPLICITY|FLAG_TERMINATION)
+	assert.Nil(t, err)
+	for _, e := range []struct {
+		key   string
+		value Value
+	}{{"a", MapValue{"b": Bytes("1")}}, {"c", Bytes("3")}, {"a", Bytes("2")}} {
+		_, err = writeRaw(&b, []byte(e.key), FLAG_KEYVALUE)
+		assert.Nil(t, err)
+		_, err = e.value.WriteTo(&b)
+		assert.Nil(t, err)
+	}
+	var data = b.Bytes()
+	decoded, err := ReadValue(bytes.NewReader(data))
+	assert.Nil(t, err)
+	v, err := SelectReader(bytes.NewReader(data), "a")
+	assert.Nil(t, err)
+	assert.EqualT[Value](t, Bytes("2"), v)
+	expected, err := Select(decoded, "a")
+	assert.Nil(t, err)
+	assert.EqualT(t, expected, v)
+	_, err = SelectReader(bytes.NewReader(data), "a.b")
+	assert.IsError(t, ErrNotFound, err)
+	_, err = Select(decoded, "a.b")
+	assert.IsError(t, ErrNotFound, err)
+}
@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// bufferMax is the maximum number of bytes of entries that sequence- and map-writers buffer.
+const bufferMax = 64 * 1024
+
+// records writes a value of unknown length as records of up to SIZE_2BYTE_MAX bytes or entries. Content is
+// buffered until the record is full and more content follows, or until closed.
+//
+// Bytes-values are buffered up to the size of one record, i.e. SIZE_2BYTE_MAX bytes. Entries of sequences
+// and maps are buffered up to `bufferMax` bytes. An entry that does not fit is written to the output
+// directly, as a separate record, after the record with the preceding entries. Consequently, the output is
+// identical to writing the whole value at once, i.e. in canonical form, only if all content fits in the
+// buffer, and memory use is bounded by `bufferMax` bytes.
+type records struct {
+	out    io.Writer
+	flags  byte
+	count  uint
+	buffer bytes.Buffer
+	// start is the offset in `buffer` of the entry that is being written.
+	start int
+	// spilled indicates that the entry that is being written did not fit in the buffer, and is being written
+	// directly to `out`.
+	spilled bool
+	closed  bool
+}
+
+// reserve prepares for adding content, writing the full buffered record as non-terminated record. Returns
+// the remaining capacity of the record.
+func (r *records) reserve() (uint, error) {
+	if r.closed {
+		return 0, errors.Context(errors.ErrInternalState, "writer is closed")
+	}
+	if r.count == SIZE_2BYTE_MAX {
+		if err := r.flush(false); err != nil {
+			return 0, err
+		}
+	}
+	return SIZE_2BYTE_MAX - r.count, nil
+}
+
+// flush writes the buffered record.
+func (r *records) flush(terminated bool) error {
+	var flags = r.flags
+	if terminated {
+		flags |= FLAG_TERMINATION
+	}
+	if _, err := writeHeader(r.out, int(r.count), flags); err != nil {
+		return err
+	}
+	if _, err := r.out.Write(r.buffer.Bytes()); err != nil {
+		return err
+	}
+	r.count = 0
+	r.buffer.Reset()
+	return nil
+}
+
+// begin prepares for writing the next entry.
+func (r *records) begin() error {
+	if _, err := r.reserve(); err != nil {
+		return err
+	}
+	r.start = r.buffer.Len()
+	return nil
+}
+
+// end completes the entry that is being written. In case of failure, a buffered entry is discarded. An
+// entry that is written directly cannot be discarded, therefore the value is incomplete.
+func (r *records) end(err error) error {
+	switch {
+	case r.spilled:
+		// The entry is written as separate record.
+		r.spilled = false
+	case err != nil:
+		r.buffer.Truncate(r.start)
+	default:
+		r.count++
+	}
+	return err
+}
+
+// spill writes the buffered entries as non-terminated record, followed by the header and the buffered part
+// of the entry that is being written, as separate record. The remainder of the entry is written directly.
+func (r *records) spill() error {
+	var data = r.buffer.Bytes()
+	if r.count > 0 {
+		if _, err := writeHeader(r.out, int(r.count), r.flags); err != nil {
+			return err
+		}
+		if _, err := r.out.Write(data[:r.start]); err != nil {
+			return err
+		}
+	}
+	if _, err := writeHeader(r.out, 1, r.flags); err != nil {
+		return err
+	}
+	if _, err := r.out.Write(data[r.start:]); err != nil {
+		return err
+	}
+	r.count, r.start, r.spilled = 0, 0, true
+	r.buffer.Reset()
+	return nil
+}
+
+// entry is the writer for the encoded entry that is being written.
+type entry records
+
+var _ io.Writer = (*entry)(nil)
+
+// Write buffers the data, or writes it directly once the entry does not fit in the buffer.
+func (e *entry) Write(p []byte) (int, error) {
+	var r = (*records)(e)
+	if !r.spilled {
+		if r.buffer.Len()+len(p) <= bufferMax {
+			return r.buffer.Write(p)
+		}
+		if err := r.spill(); err != nil {
+			return 0, err
+		}
+	}
+	return r.out.Write(p)
+}
+
+// Flush writes buffered content as non-terminated record, such that the content is available to the
+// reader without waiting for the record to fill up. Flushing is not necessary for correctness. Flushing
+// results in smaller records, therefore output that is not in canonical form.
+func (r *records) Flush() error {
+	if r.closed {
+		return errors.Context(errors.ErrInternalState, "writer is closed")
+	}
+	if r.count == 0 {
+		return nil
+	}
+	return r.flush(false)
+}
+
+// Close writes the final, terminated record. Close must be called to complete the value.
+func (r *records) Close() error {
+	if r.closed {
+		return errors.Context(errors.ErrInternalState, "writer is closed")
+	}
+	r.closed = true
+	return r.flush(true)
+}
+
+// BytesWriter writes a bytes-value of unknown length incrementally. The value is completed by `Close`.
+type BytesWriter struct {
+	records
+}
+
+var _ io.WriteCloser = (*BytesWriter)(nil)
+
+// NewBytesWriter creates a writer for a bytes-value.
+func NewBytesWriter(out io.Writer) *BytesWriter {
+	return &BytesWriter{records{out: out}}
+}
+
+// Write writes data as part of the bytes-value.
+func (w *BytesWriter) Write(data []byte) (int, error) {
+	var n int
+	for len(data) > 0 {
+		capacity, err := w.reserve()
+		if err != nil {
+			return n, err
+		}
+		var part = data[:min(capacity, uint(len(data)))]
+		w.buffer.Write(part)
+		w.count += uint(len(part))
+		n += len(part)
+		data = data[len(part):]
+	}
+	return n, nil
+}
+
+// SequenceWriter writes a sequence-value of unknown length, entry by entry. The value is completed by
+// `Close`.
+//
+// Entries are buffered up to 64 KiB. An entry that does not fit is written directly, as a separate record,
+// such that memory use is bounded, however the output is then not in canonical form.
+type SequenceWriter struct {
+	records
+}
+
+// NewSequenceWriter creates a writer for a sequence-value.
+func NewSequenceWriter(out io.Writer) *SequenceWriter {
+	return &SequenceWriter{records{out: out, flags: FLAG_MULTIPLICITY}}
+}
+
+// WriteEntry writes the value as next entry of the sequence.
+func (w *SequenceWriter) WriteEntry(v Value) error {
+	if err := w.begin(); err != nil {
+		return err
+	}
+	_, err := v.WriteTo((*entry)(&w.records))
+	return w.end(err)
+}
+
+// WriteEntryOf writes the value as next entry of the sequence. The value must either be a `Value` or a
+// `PrefixedEncoder`.
+func (w *SequenceWriter) WriteEntryOf(e any) error {
+	v, err := valueOf(e)
+	if err != nil {
+		return err
+	}
+	return w.WriteEntry(v)
+}
+
+// MapWriter writes a map-value of unknown length, entry by entry. The value is completed by `Close`.
+//
+// Entries are written in order of occurrence. MapWriter does not check for duplicate keys. Consequently, the
+// output is in canonical form only if entries are written in order of (byte-wise) sorted, unique keys.
+// Entries are buffered as for `SequenceWriter`.
+type MapWriter struct {
+	records
+}
+
+// NewMapWriter creates a writer for a map-value.
+func NewMapWriter(out io.Writer) *MapWriter {
+	return &MapWriter{records{out: out, flags: FLAG_KEYVALUE | FLAG_MULTIPLICITY}}
+}
+
+// WriteEntry writes the key and value as next entry of the map.
+func (w *MapWriter) WriteEntry(key string, v Value) error {
+	if err := w.begin(); err != nil {
+		return err
+	}
+	var out = (*entry)(&w.records)
+	_, err := writeRaw(out, []byte(key), FLAG_KEYVALUE)
+	if err == nil {
+		_, err = v.WriteTo(out)
+	}
+	return w.end(err)
+}
+
+// WriteEntryOf writes the key and value as next entry of the map. The value must either be a `Value` or a
+// `PrefixedEncoder`.
+func (w *MapWriter) WriteEntryOf(key string, e any) error {
+	v, err := valueOf(e)
+	if err != nil {
+		return err
+	}
+	return w.WriteEntry(key, v)
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/crypto/rand"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestBytesWriterMatchesWriteTo(t *testing.T) {
+	for _, size := range []int{0, 1, 15, 16, 4095, 4096, 4097, 8192, 10000} {
+		t.Log("Size:", size)
+		var data = make([]byte, size)
+		rand.MustReadBytes(data)
+		var expected, actual bytes.Buffer
+		_, err := Bytes(data).WriteTo(&expected)
+		assert.Nil(t, err)
+		w := NewBytesWriter(&actual)
+		// write in uneven pieces
+		for remaining := data; len(remaining) > 0; {
+			var n = min(len(remaining), 1000)
+			written, err := w.Write(remaining[:n])
+			assert.Nil(t, err)
+			assert.Equal(t, n, written)
+			remaining = remaining[n:]
+		}
+		assert.Nil(t, w.Close())
+		assert.SlicesEqual(t, expected.Bytes(), actual.Bytes())
+	}
+}
+
+func TestSequenceWriterMatchesWriteTo(t *testing.T) {
+	for _, size := range []int{0, 1, 16, 4096, 4097, 8193} {
+		t.Log("Size:", size)
+		var seq = make(SequenceValue, size)
+		for i := range seq {
+			seq[i] = Bytes(strconv.Itoa(i))
+		}
+		var expected, actual bytes.Buffer
+		_, err := seq.WriteTo(&expected)
+		assert.Nil(t, err)
+		w := NewSequenceWriter(&actual)
+		for _, e := range seq {
+			assert.Nil(t, w.WriteEntry(e))
+		}
+		assert.Nil(t, w.Close())
+		assert.SlicesEqual(t, expected.Bytes(), actual.Bytes())
+		assert.Nil(t, VerifyCanonical(&actual))
+	}
+}
+
+func TestMapWriterMatchesWriteTo(t *testing.T) {
+	var m = make(MapValue, 5000)
+	var keys = make([]string, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		var key = strconv.Itoa(i)
+		m[key] = SequenceValue{Bytes(key)}
+		keys = append(keys, key)
+	}
+	var expected, actual bytes.Buffer
+	_, err := m.WriteTo(&expected)
+	assert.Nil(t, err)
+	w := NewMapWriter(&actual)
+	sort.Strings(keys)
+	for _, k := range keys {
+		assert.Nil(t, w.WriteEntry(k, m[k]))
+	}
+	assert.Nil(t, w.Close())
+	assert.SlicesEqual(t, expected.Bytes(), actual.Bytes())
+}
+
+func TestSequenceWriterLargeEntries(t *testing.T) {
+	var seq = SequenceValue{Bytes("a"), Bytes(make([]byte, 40000)), Bytes(make([]byte, 70000)), Bytes("b"),
+		SequenceValue{Bytes(make([]byte, bufferMax))}}
+	var b bytes.Buffer
+	w := NewSequenceWriter(&b)
+	for _, e := range seq {
+		assert.Nil(t, w.WriteEntry(e))
+		assert.True(t, w.buffer.Len() <= bufferMax)
+	}
+	assert.Nil(t, w.Close())
+	assert.Nil(t, Verify(bytes.NewReader(b.Bytes())))
+	result, err := ReadSequence(&b, nil)
+	assert.Nil(t, err)
+	assert.True(t, seq.Equal(result))
+}
+
+func TestMapWriterLargeEntries(t *testing.T) {
+	var m = MapValue{"a": Bytes(make([]byte, 50000)), "b": Bytes(make([]byte, 50000)), "c": Bytes{}}
+	var b bytes.Buffer
+	w := NewMapWriter(&b)
+	for _, k := range []string{"a", "b", "c"} {
+		assert.Nil(t, w.WriteEntry(k, m[k]))
+	}
+	assert.Nil(t, w.Close())
+	result, err := ReadMap(&b, nil)
+	assert.Nil(t, err)
+	assert.True(t, m.Equal(result))
+}
+
+func TestWriteEntryOf(t *testing.T) {
+	var b bytes.Buffer
+	sw := NewSequenceWriter(&b)
+	assert.Nil(t, sw.WriteEntryOf(testPoint{1, 2}))
+	assert.Nil(t, sw.WriteEntryOf(Bytes("a")))
+	assert.IsError(t, errors.ErrIllegal, sw.WriteEntryOf((*testPoint)(nil)))
+	assert.IsError(t, errors.ErrUnsupported, sw.WriteEntryOf(3))
+	assert.Nil(t, sw.Close())
+	seq, err := ReadSequence(&b, nil)
+	assert.Nil(t, err)
+	assert.True(t, SequenceValue{Bytes{1, 2}, Bytes("a")}.Equal(seq))
+	mw := NewMapWriter(&b)
+	assert.Nil(t, mw.WriteEntryOf("p", testPoint{3, 4}))
+	assert.IsError(t, errors.ErrIllegal, mw.WriteEntryOf("x", nil))
+	assert.Nil(t, mw.Close())
+	m, err := ReadMap(&b, nil)
+	assert.Nil(t, err)
+	assert.True(t, MapValue{"p": Bytes{3, 4}}.Equal(m))
+}
+
+func TestSequenceWriterFlush(t *testing.T) {
+	var b bytes.Buffer
+	w := NewSequenceWriter(&b)
+	assert.Nil(t, w.WriteEntry(Bytes("a")))
+	assert.Nil(t, w.Flush())
+	assert.SlicesEqual(t, []byte{1 | FLAG_MULTIPLICITY, 1 | FLAG_TERMINATION, 'a'}, b.Bytes())
+	assert.Nil(t, w.Flush())
+	assert.Nil(t, w.WriteEntry(Bytes("b")))
+	assert.Nil(t, w.Close())
+	seq, err := ReadSequence(&b, nil)
+	assert.Nil(t, err)
+	assert.True(t, SequenceValue{Bytes("a"), Bytes("b")}.Equal(seq))
+}
+
+func TestWriterClosed(t *testing.T) {
+	var b bytes.Buffer
+	w := NewMapWriter(&b)
+	assert.Nil(t, w.Close())
+	assert.IsError(t, errors.ErrInternalState, w.WriteEntry("a", Bytes{}))
+	assert.IsError(t, errors.ErrInternalState, w.Flush())
+	assert.IsError(t, errors.ErrInternalState, w.Close())
+	m, err := ReadMap(&b, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m))
+}
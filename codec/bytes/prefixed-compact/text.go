@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// Format formats the value in (indented) text notation. Bytes are formatted as quoted string if the bytes
+// are valid UTF-8 and consist only of graphic characters and whitespace, and hexadecimal otherwise.
+// Map-entries are formatted in order of sorted keys.
+func Format(v Value) string {
+	var b strings.Builder
+	formatValue(&b, v, 0)
+	return b.String()
+}
+
+func formatValue(b *strings.Builder, v Value, indent int) {
+	switch v := v.(type) {
+	case Bytes:
+		formatBytes(b, v)
+	case *KeyValue:
+		formatBytes(b, []byte(v.K))
+		b.WriteString(": ")
+		formatValue(b, v.V, indent)
+	case SequenceValue:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[\n")
+		for i, e := range v {
+			formatIndent(b, indent+1)
+			formatValue(b, e, indent+1)
+			formatSeparator(b, i < len(v)-1)
+		}
+		formatIndent(b, indent)
+		b.WriteByte(']')
+	case MapValue:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		var keys = make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("{\n")
+		for i, k := range keys {
+			formatIndent(b, indent+1)
+			formatBytes(b, []byte(k))
+			b.WriteString(": ")
+			formatValue(b, v[k], indent+1)
+			formatSeparator(b, i < len(keys)-1)
+		}
+		formatIndent(b, indent)
+		b.WriteByte('}')
+	default:
+		panic("BUG: unsupported value-type")
+	}
+}
+
+func formatBytes(b *strings.Builder, data []byte) {
+	if printable(data) {
+		b.WriteString(strconv.Quote(string(data)))
+		return
+	}
+	b.WriteString("h'")
+	b.WriteString(hex.EncodeToString(data))
+	b.WriteByte('\'')
+}
+
+func formatIndent(b *strings.Builder, indent int) {
+	for i := 0; i < indent; i++ {
+		b.WriteString("  ")
+	}
+}
+
+func formatSeparator(b *strings.Builder, more bool) {
+	if more {
+		b.WriteByte(',')
+	}
+	b.WriteByte('\n')
+}
+
+// printable tests whether data is valid UTF-8 consisting only of graphic characters and whitespace.
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsGraphic(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse parses a value in text notation. The full text must be consumed by a single value, except for
+// surrounding whitespace. Returns `errors.ErrIllegal` (with context indicating the offset) in case of
+// syntax errors, or duplicate keys in a map. Returns `ErrLimitExceeded` if nesting exceeds `DefaultMaxDepth`.
+func Parse(text string) (Value, error) {
+	var p = textParser{text: text}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if p.skip(); p.pos < len(p.text) {
+		return nil, p.fail("unexpected trailing content")
+	}
+	return v, nil
+}
+
+// textParser is a recursive-descent parser for the text notation.
+type textParser struct {
+	text  string
+	pos   int
+	depth uint
+}
+
+func (p *textParser) fail(msg string) error {
+	return errors.Context(errors.ErrIllegal, msg+" at offset "+strconv.Itoa(p.pos))
+}
+
+// skip skips whitespace.
+func (p *textParser) skip() {
+	for p.pos < len(p.text) && strings.IndexByte(" \t\r\n", p.text[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// peek returns the next non-whitespace character, or 0 at end of text.
+func (p *textParser) peek() byte {
+	if p.skip(); p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+	return 0
+}
+
+func (p *textParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.fail("expected '" + string(c) + "'")
+	}
+	p.pos++
+	return nil
+}
+
+// enter registers descending one level of nesting, failing if this exceeds `DefaultMaxDepth`.
+func (p *textParser) enter() error {
+	if p.depth >= DefaultMaxDepth {
+		return errors.Context(ErrLimitExceeded,
+			"maximum nesting depth reached at offset "+strconv.Itoa(p.pos))
+	}
+	p.depth++
+	return nil
+}
+
+func (p *textParser) value() (Value, error) {
+	switch p.peek() {
+	case '[':
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer func() { p.depth-- }()
+		return p.sequence()
+	case '{':
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer func() { p.depth-- }()
+		return p.mapping()
+	case 0:
+		return nil, p.fail("unexpected end of text")
+	}
+	data, err := p.bytes()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ':' {
+		return Bytes(data), nil
+	}
+	p.pos++
+	if err = p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	var v Value
+	if v, err = p.value(); err != nil {
+		return nil, err
+	}
+	return &KeyValue{K: string(data), V: v}, nil
+}
+
+func (p *textParser) bytes() ([]byte, error) {
+	var c = p.peek()
+	var start = p.pos
+	switch {
+	case c == '"':
+		p.pos++
+		for ; p.pos < len(p.text) && p.text[p.pos] != '"'; p.pos++ {
+			if p.text[p.pos] == '\\' {
+				p.pos++
+			}
+		}
+		if p.pos >= len(p.text) {
+			return nil, p.fail("unterminated string")
+		}
+		p.pos++
+		s, err := strconv.Unquote(p.text[start:p.pos])
+		if err != nil {
+			p.pos = start
+			return nil, p.fail("invalid string")
+		}
+		return []byte(s), nil
+	case strings.HasPrefix(p.text[p.pos:], "h'"):
+		p.pos += 2
+		var end = strings.IndexByte(p.text[p.pos:], '\'')
+		if end < 0 {
+			return nil, p.fail("unterminated hexadecimal bytes")
+		}
+		var digits = strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, p.text[p.pos:p.pos+end])
+		data, err := hex.DecodeString(digits)
+		if err != nil {
+			return nil, p.fail("invalid hexadecimal bytes")
+		}
+		p.pos += end + 1
+		return data, nil
+	default:
+		return nil, p.fail("expected value")
+	}
+}
+
+func (p *textParser) sequence() (SequenceValue, error) {
+	p.pos++
+	var seq = SequenceValue{}
+	if p.peek() == ']' {
+		p.pos++
+		return seq, nil
+	}
+	for {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return seq, nil
+		default:
+			return nil, p.fail("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *textParser) mapping() (MapValue, error) {
+	p.pos++
+	var m = MapValue{}
+	if p.peek() == '}' {
+		p.pos++
+		return m, nil
+	}
+	for {
+		p.skip()
+		var start = p.pos
+		key, err := p.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m[string(key)]; ok {
+			p.pos = start
+			return nil, p.fail("duplicate key")
+		}
+		if err = p.expect(':'); err != nil {
+			return nil, err
+		}
+		if m[string(key)], err = p.value(); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return m, nil
+		default:
+			return nil, p.fail("expected ',' or '}'")
+		}
+	}
+}
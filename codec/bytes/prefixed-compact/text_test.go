@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestFormat(t *testing.T) {
+	var v = MapValue{
+		"b":    SequenceValue{Bytes("x"), Bytes{0, 0xff}, SequenceValue{}},
+		"a":    &KeyValue{K: "id", V: Bytes("line\n")},
+		"\x00": MapValue{},
+	}
+	assert.Equal(t, `{
+  h'00': {},
+  "a": "id": "line\n",
+  "b": [
+    "x",
+    h'00ff',
+    []
+  ]
+}`, Format(v))
+	assert.Equal(t, `""`, Format(Bytes{}))
+	assert.Equal(t, `"k": h'ff'`, Format(&KeyValue{K: "k", V: Bytes{0xff}}))
+}
+
+func TestParse(t *testing.T) {
+	var testdata = []struct {
+		text  string
+		value Value
+	}{
+		{text: `""`, value: Bytes{}},
+		{text: ` h'' `, value: Bytes{}},
+		{text: `h'0011 22FF'`, value: Bytes{0x00, 0x11, 0x22, 0xff}},
+		{text: `"a\tbé"`, value: Bytes("a\tbé")},
+		{text: `"k":"v"`, value: &KeyValue{K: "k", V: Bytes("v")}},
+		{text: `h'6b': "a": []`, value: &KeyValue{K: "k", V: &KeyValue{K: "a", V: SequenceValue{}}}},
+		{text: `["a", "k": "v", {}]`, value: SequenceValue{Bytes("a"), &KeyValue{K: "k", V: Bytes("v")}, MapValue{}}},
+		{text: "{\n\t\"a\": \"1\",\n\t\"b\": [\"2\"]\n}", value: MapValue{"a": Bytes("1"), "b": SequenceValue{Bytes("2")}}},
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		v, err := Parse(d.text)
+		assert.Nil(t, err)
+		assert.True(t, d.value.Equal(v))
+	}
+}
+
+func TestParseIllegal(t *testing.T) {
+	var testdata = []string{
+		``,
+		`"unterminated`,
+		`h'0'`,
+		`h'00`,
+		`"a" "b"`,
+		`["a",]`,
+		`["a" "b"]`,
+		`{"a"}`,
+		`{"a": "1", "a": "2"}`,
+		`{["a"]: "1"}`,
+		`x`,
+		`"a":`,
+	}
+	for i, d := range testdata {
+		t.Log("Iteration:", i)
+		_, err := Parse(d)
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+}
+
+func TestParseMaxDepth(t *testing.T) {
+	var nested = strings.Repeat("[", DefaultMaxDepth) + strings.Repeat("]", DefaultMaxDepth)
+	_, err := Parse(nested)
+	assert.Nil(t, err)
+	_, err = Parse("[" + nested + "]")
+	assert.IsError(t, ErrLimitExceeded, err)
+	_, err = Parse(strings.Repeat("[", 1_000_000))
+	assert.IsError(t, ErrLimitExceeded, err)
+	_, err = Parse(strings.Repeat(`"k":`, DefaultMaxDepth+1) + `"v"`)
+	assert.IsError(t, ErrLimitExceeded, err)
+}
+
+func TestFormatParseRoundtrip(t *testing.T) {
+	var v = SequenceValue{
+		Bytes{},
+		Bytes{1, 2, 3},
+		&KeyValue{K: "\xff", V: MapValue{"": Bytes("\"quoted\""), "seq": SequenceValue{Bytes("日本")}}},
+		MapValue{"x": &KeyValue{K: "y", V: Bytes("z")}},
+	}
+	parsed, err := Parse(Format(v))
+	assert.Nil(t, err)
+	assert.True(t, v.Equal(parsed))
+}
@@ -1,80 +1,141 @@
 // SPDX-License-Identifier: LGPL-3.0-only
 
-// TODO at some point implement read-functions for reading from io.Reader
 package prefixed
 
 import (
+	"bytes"
 	"io"
 
-	"github.com/cobratbq/goutils/assert"
 	"github.com/cobratbq/goutils/std/errors"
 	io_ "github.com/cobratbq/goutils/std/io"
 	"github.com/cobratbq/goutils/std/log"
 )
 
-func verifyKeyValue(in io.Reader, hdr *Header) error {
-	assert.Equal(TYPE_KEYVALUE, hdr.Vtype)
+// discardRaw discards the raw bytes of a bytes-value or key, including any continuation records.
+func (d *decoding) discardRaw(hdr Header) error {
+	var vtype = hdr.Vtype
+	var total uint
 	var err error
 	for {
-		if _, err = io_.DiscardN(in, int64(hdr.Size)); err != nil {
+		if err = d.size(total, hdr.Size); err != nil {
 			return err
 		}
+		if err = d.record(hdr, total > 0); err != nil {
+			return err
+		}
+		if _, err = io_.DiscardN(d, int64(hdr.Size)); err != nil {
+			return unexpectedEOF(err)
+		}
+		total += uint(hdr.Size)
 		if hdr.Terminated {
-			break
+			return nil
 		}
-		if *hdr, err = ReadHeader(in); err != nil {
-			return err
-		} else if hdr.Vtype != TYPE_KEYVALUE {
+		if hdr, err = d.header(); err != nil {
+			return unexpectedEOF(err)
+		} else if hdr.Vtype != vtype {
 			return errors.ErrIllegal
 		}
 	}
-	return VerifyValue(in)
 }
 
-// TODO not tested yet
-func VerifyValue(in io.Reader) error {
+// verifyEntry verifies the key and value of a key-value-pair, given the header of the key.
+func (d *decoding) verifyEntry(hdr Header) error {
+	if err := d.discardRaw(hdr); err != nil {
+		return err
+	}
+	return unexpectedEOF(d.verifyValue())
+}
+
+func (d *decoding) verifyValue() error {
 	log.Traceln("Reading value-header")
 	var hdr Header
 	var err error
-	for terminated := false; !terminated; terminated = hdr.Terminated {
-		// FIXME verify same valuetype|multiplicity upon continuation
-		if hdr, err = ReadHeader(in); err != nil {
+	if hdr, err = d.header(); err != nil {
+		return err
+	}
+	switch hdr.Vtype {
+	case TYPE_BYTES:
+		return d.discardRaw(hdr)
+	case TYPE_KEYVALUE:
+		if err = d.enter(); err != nil {
 			return err
 		}
-		switch hdr.Vtype {
-		case TYPE_BYTES:
-			if _, err = io_.DiscardN(in, int64(hdr.Size)); err != nil {
+		defer d.leave()
+		return d.verifyEntry(hdr)
+	case TYPE_SEQUENCE, TYPE_MAP:
+		if err = d.enter(); err != nil {
+			return err
+		}
+		defer d.leave()
+		var vtype = hdr.Vtype
+		var key, prev []byte
+		for first := true; ; first = false {
+			if err = d.record(hdr, !first); err != nil {
 				return err
 			}
-		case TYPE_KEYVALUE:
-			return verifyKeyValue(in, &hdr)
-		case TYPE_SEQUENCE:
 			for i := uint16(0); i < hdr.Size; i++ {
-				if err = VerifyValue(in); err != nil {
+				if err = d.entry(); err != nil {
 					return err
 				}
-			}
-		case TYPE_MAP:
-			for i := uint16(0); i < hdr.Size; i++ {
-				if hdr, err = ReadHeader(in); err != nil {
-					return err
-				} else if hdr.Vtype != TYPE_KEYVALUE {
-					return errors.ErrIllegal
+				if vtype == TYPE_SEQUENCE {
+					err = unexpectedEOF(d.verifyValue())
+				} else if key, err = d.verifyMapEntry(); err == nil && d.canonical {
+					// Keys must be strictly ascending, which implies that keys are unique.
+					if prev != nil && bytes.Compare(prev, key) >= 0 {
+						return errors.Context(ErrNonCanonical, "map-entries are not ordered by unique keys")
+					}
+					prev = key
 				}
-				if err = verifyKeyValue(in, &hdr); err != nil {
+				if err != nil {
 					return err
 				}
 			}
+			if hdr.Terminated {
+				return nil
+			}
+			if hdr, err = d.header(); err != nil {
+				return unexpectedEOF(err)
+			} else if hdr.Vtype != vtype {
+				return errors.ErrIllegal
+			}
 		}
+	default:
+		panic("BUG: should not be reached")
 	}
-	return nil
 }
 
-// TODO not tested yet
+// verifyMapEntry verifies a map-entry. The key is returned only in case of canonical verification.
+func (d *decoding) verifyMapEntry() ([]byte, error) {
+	hdr, err := d.header()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	} else if hdr.Vtype != TYPE_KEYVALUE {
+		return nil, errors.ErrIllegal
+	}
+	if !d.canonical {
+		return nil, d.verifyEntry(hdr)
+	}
+	var key []byte
+	if key, err = d.readRaw(hdr); err != nil {
+		return nil, err
+	}
+	return key, unexpectedEOF(d.verifyValue())
+}
+
+// VerifyValue verifies the structure of a single value, while discarding its contents.
+func VerifyValue(in io.Reader) error {
+	return Limits{}.VerifyValue(in)
+}
+
+// Verify verifies the structure of all values until the end of input is reached.
 func Verify(in io.Reader) error {
+	return Limits{}.Verify(in)
+}
+
+func (d *decoding) verify() error {
 	var err error
 	for {
-		err = VerifyValue(in)
+		err = d.verifyValue()
 		if errors.Is(err, io.EOF) {
 			return nil
 		} else if err != nil {
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+
+	"github.com/cobratbq/goutils/std/errors"
+)
+
+// View is a read-only view on an encoded value. Only the parts of the value that are accessed are decoded.
+// Sequence-entries and map-entries are indexed upon first access, after which indexing and lookup are
+// constant-time. A view does not copy the encoded data, therefore the data must not be modified while the
+// view is in use.
+//
+// A View is not safe for concurrent use, as indexing modifies the view.
+type View struct {
+	// data is the exact encoded value, including its header.
+	data []byte
+	hdr  Header
+	// n is the size of the header.
+	n uint
+	// limits are the limits with which the data was verified, for re-decoding parts of the value.
+	limits  Limits
+	indexed bool
+	entries []*View
+	keys    map[string]*View
+}
+
+// NewView creates a view on the first value in `data`. The structure of the value is verified, without
+// decoding, such that subsequent accesses cannot fail on malformed data. Returns the view and the number of
+// bytes of the encoded value.
+func NewView(data []byte) (*View, uint, error) {
+	return Limits{}.NewView(data)
+}
+
+// NewView creates a view on the first value in `data`, honoring the limits. See `NewView`.
+func (l Limits) NewView(data []byte) (*View, uint, error) {
+	var in = bytes.NewReader(data)
+	if err := l.decoding(in).verifyValue(); err != nil {
+		return nil, 0, err
+	}
+	var n = consumed(in)
+	return newView(data[:n], l), n, nil
+}
+
+// newView creates a view on data already verified with limits `l`.
+func newView(data []byte, l Limits) *View {
+	n, h := ParseHeader(data)
+	if n == 0 {
+		panic("BUG: view data must be verified")
+	}
+	return &View{data: data, hdr: h, n: n, limits: l}
+}
+
+// Type returns the type of the value.
+func (v *View) Type() CompositeType {
+	return v.hdr.Vtype
+}
+
+// Raw returns the encoded value.
+func (v *View) Raw() []byte {
+	return v.data
+}
+
+// Len returns the count of bytes, sequence-entries or map-entries, similar to `Value.Len`. For
+// key-value-pairs, Len returns the size of the key. For sequences and maps, Len requires indexing.
+func (v *View) Len() int {
+	switch v.hdr.Vtype {
+	case TYPE_BYTES, TYPE_KEYVALUE:
+		return len(v.raw())
+	case TYPE_SEQUENCE:
+		v.index()
+		return len(v.entries)
+	case TYPE_MAP:
+		v.index()
+		return len(v.keys)
+	default:
+		panic("BUG: should not be reached")
+	}
+}
+
+// Bytes returns the bytes of a bytes-value. The bytes alias the encoded data, unless the value is split
+// across continuation records.
+func (v *View) Bytes() (Bytes, error) {
+	if v.hdr.Vtype != TYPE_BYTES {
+		return nil, errors.Context(errors.ErrIllegal, "not a bytes-value, but "+v.hdr.Vtype.String())
+	}
+	return v.raw(), nil
+}
+
+// Key returns the key of a key-value-pair.
+func (v *View) Key() (string, error) {
+	if v.hdr.Vtype != TYPE_KEYVALUE {
+		return "", errors.Context(errors.ErrIllegal, "not a key-value-pair, but "+v.hdr.Vtype.String())
+	}
+	return string(v.raw()), nil
+}
+
+// Value returns the view on the value of a key-value-pair.
+func (v *View) Value() (*View, error) {
+	if v.hdr.Vtype != TYPE_KEYVALUE {
+		return nil, errors.Context(errors.ErrIllegal, "not a key-value-pair, but "+v.hdr.Vtype.String())
+	}
+	v.index()
+	return v.entries[0], nil
+}
+
+// Index returns the view on the sequence-entry at index `i`. Returns `ErrNotFound` if out of range.
+func (v *View) Index(i int) (*View, error) {
+	if v.hdr.Vtype != TYPE_SEQUENCE {
+		return nil, errors.Context(errors.ErrIllegal, "not a sequence-value, but "+v.hdr.Vtype.String())
+	}
+	v.index()
+	if i < 0 || i >= len(v.entries) {
+		return nil, errors.Context(ErrNotFound, "index out of range")
+	}
+	return v.entries[i], nil
+}
+
+// Lookup returns the view on the value of the map-entry with key `key`. In case of duplicate keys, the last
+// entry wins, as is the case for `ReadMap`. Returns `ErrNotFound` if the key is not present.
+func (v *View) Lookup(key string) (*View, error) {
+	if v.hdr.Vtype != TYPE_MAP {
+		return nil, errors.Context(errors.ErrIllegal, "not a map-value, but "+v.hdr.Vtype.String())
+	}
+	v.index()
+	entry, ok := v.keys[key]
+	if !ok {
+		return nil, errors.Context(ErrNotFound, "key not present")
+	}
+	return entry, nil
+}
+
+// Decode decodes the full value, without copying, honoring the limits of the view. See `ParseValueNoCopy`.
+func (v *View) Decode() (Value, error) {
+	_, val, err := v.limits.ParseValueNoCopy(v.data)
+	return val, err
+}
+
+// raw returns the raw bytes of a bytes-value or key.
+func (v *View) raw() []byte {
+	var d = v.limits.decoding(bytes.NewReader(v.data[v.n:]))
+	d.borrow = v.data[v.n:]
+	raw, err := d.readRaw(v.hdr)
+	if err != nil {
+		panic("BUG: view data must be verified")
+	}
+	return raw
+}
+
+// index indexes the entries of a sequence or map, or the value of a key-value-pair.
+func (v *View) index() {
+	if v.indexed {
+		return
+	}
+	var in = bytes.NewReader(v.data)
+	var d = v.limits.decoding(in)
+	var h Header
+	var err error
+	if h, err = d.header(); err != nil {
+		panic("BUG: view data must be verified")
+	}
+	if v.hdr.Vtype == TYPE_KEYVALUE {
+		if err = d.discardRaw(h); err != nil {
+			panic("BUG: view data must be verified")
+		}
+		v.entries = []*View{newView(v.data[d.read:], v.limits)}
+		v.indexed = true
+		return
+	}
+	if v.hdr.Vtype == TYPE_MAP {
+		v.keys = make(map[string]*View)
+	}
+	for {
+		for i := uint16(0); i < h.Size; i++ {
+			var key []byte
+			if v.hdr.Vtype == TYPE_MAP {
+				var kh Header
+				if kh, err = d.header(); err != nil {
+					panic("BUG: view data must be verified")
+				}
+				if key, err = d.readRaw(kh); err != nil {
+					panic("BUG: view data must be verified")
+				}
+			}
+			var start = d.read
+			if err = d.verifyValue(); err != nil {
+				panic("BUG: view data must be verified")
+			}
+			var entry = newView(v.data[start:d.read], v.limits)
+			if v.hdr.Vtype == TYPE_MAP {
+				v.keys[string(key)] = entry
+			} else {
+				v.entries = append(v.entries, entry)
+			}
+		}
+		if h.Terminated {
+			break
+		}
+		if h, err = d.header(); err != nil {
+			panic("BUG: view data must be verified")
+		}
+	}
+	v.indexed = true
+}
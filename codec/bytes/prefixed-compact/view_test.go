@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package prefixed
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestView(t *testing.T) {
+	var data = encodeTestValue(t, selectDocument)
+	view, n, err := NewView(append(data, 0xff))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(len(data)), n)
+	assert.Equal(t, TYPE_MAP, view.Type())
+	assert.Equal(t, 2, view.Len())
+	assert.SlicesEqual(t, data, view.Raw())
+	config, err := view.Lookup("config")
+	assert.Nil(t, err)
+	servers, err := config.Lookup("servers")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, servers.Len())
+	server, err := servers.Index(2)
+	assert.Nil(t, err)
+	host, err := server.Lookup("host")
+	assert.Nil(t, err)
+	value, err := host.Bytes()
+	assert.Nil(t, err)
+	assert.SlicesEqual(t, []byte("gamma"), value)
+	version, err := config.Lookup("version")
+	assert.Nil(t, err)
+	key, err := version.Key()
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", key)
+	inner, err := version.Value()
+	assert.Nil(t, err)
+	value, err = inner.Bytes()
+	assert.Nil(t, err)
+	assert.SlicesEqual(t, []byte{1}, value)
+	decoded, err := config.Decode()
+	assert.Nil(t, err)
+	assert.True(t, selectDocument["config"].Equal(decoded))
+}
+
+func TestViewErrors(t *testing.T) {
+	view, _, err := NewView(encodeTestValue(t, SequenceValue{Bytes("a")}))
+	assert.Nil(t, err)
+	_, err = view.Index(1)
+	assert.IsError(t, ErrNotFound, err)
+	_, err = view.Index(-1)
+	assert.IsError(t, ErrNotFound, err)
+	_, err = view.Lookup("a")
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = view.Bytes()
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = view.Key()
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = view.Value()
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, _, err = NewView([]byte{2 | FLAG_TERMINATION, 'a'})
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestViewSplit(t *testing.T) {
+	var large = make(MapValue, 5000)
+	for i := 0; i < 5000; i++ {
+		large[strconv.Itoa(i)] = Bytes(bytes.Repeat([]byte{byte(i)}, i))
+	}
+	view, _, err := NewView(encodeTestValue(t, large))
+	assert.Nil(t, err)
+	assert.Equal(t, 5000, view.Len())
+	entry, err := view.Lookup("4999")
+	assert.Nil(t, err)
+	assert.Equal(t, 4999, entry.Len())
+	value, err := entry.Bytes()
+	assert.Nil(t, err)
+	assert.True(t, large["4999"].Equal(value))
+}
+
+func TestViewLimits(t *testing.T) {
+	var value = nestedSequence(200)
+	view, _, err := Limits{MaxDepth: 300}.NewView(encodeTestValue(t, value))
+	assert.Nil(t, err)
+	entry, err := view.Index(0)
+	assert.Nil(t, err)
+	assert.Equal(t, TYPE_SEQUENCE, entry.Type())
+	decoded, err := view.Decode()
+	assert.Nil(t, err)
+	assert.True(t, value.Equal(decoded))
+	decoded, err = entry.Decode()
+	assert.Nil(t, err)
+	assert.True(t, value.(SequenceValue)[0].Equal(decoded))
+	_, _, err = NewView(encodeTestValue(t, value))
+	assert.IsError(t, ErrLimitExceeded, err)
+}
+
+func benchmarkDocument(b *testing.B) []byte {
+	var servers = make(SequenceValue, 1000)
+	for i := range servers {
+		servers[i] = MapValue{"host": Bytes("host-" + strconv.Itoa(i)), "payload": Bytes(make([]byte, 1024))}
+	}
+	return encodeTestValue(b, MapValue{"config": MapValue{"servers": servers}})
+}
+
+func BenchmarkParseValue(b *testing.B) {
+	var data = benchmarkDocument(b)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if n, _ := ParseValue(data); n == 0 {
+			b.FailNow()
+		}
+	}
+}
+
+func BenchmarkParseValueNoCopy(b *testing.B) {
+	var data = benchmarkDocument(b)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if n, _ := ParseValueNoCopy(data); n == 0 {
+			b.FailNow()
+		}
+	}
+}
+
+func BenchmarkViewLookup(b *testing.B) {
+	var data = benchmarkDocument(b)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		view, _, err := NewView(data)
+		if err != nil {
+			b.FailNow()
+		}
+		config, _ := view.Lookup("config")
+		servers, _ := config.Lookup("servers")
+		server, _ := servers.Index(500)
+		host, _ := server.Lookup("host")
+		if _, err = host.Bytes(); err != nil {
+			b.FailNow()
+		}
+	}
+}
+
+func BenchmarkViewLookupIndexed(b *testing.B) {
+	var data = benchmarkDocument(b)
+	view, _, err := NewView(data)
+	if err != nil {
+		b.FailNow()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		config, _ := view.Lookup("config")
+		servers, _ := config.Lookup("servers")
+		server, _ := servers.Index(i % 1000)
+		host, _ := server.Lookup("host")
+		if _, err = host.Bytes(); err != nil {
+			b.FailNow()
+		}
+	}
+}
@@ -5,6 +5,7 @@ package prefixed
 import (
 	"bytes"
 	"io"
+	"sort"
 
 	"github.com/cobratbq/goutils/assert"
 	"github.com/cobratbq/goutils/codec/bytes/bigendian"
@@ -185,6 +186,19 @@ func WriteSequence(out io.Writer, seq []Value) (int64, error) {
 	return SequenceValue(seq).WriteTo(out)
 }
 
+// WriteSequenceOf is a one-shot function for writing an encoded sequence-value. Elements must either be a
+// `Value` or a `PrefixedEncoder`.
+func WriteSequenceOf[E any](out io.Writer, seq []E) (int64, error) {
+	var values = make(SequenceValue, len(seq))
+	var err error
+	for i := range seq {
+		if values[i], err = valueOf(seq[i]); err != nil {
+			return 0, err
+		}
+	}
+	return values.WriteTo(out)
+}
+
 // Type 1, 1 (multiple, key-value-pairs) for any length.
 type MapValue map[string]Value
 
@@ -204,56 +218,50 @@ func (v MapValue) Len() int {
 	return len(v)
 }
 
+// WriteTo writes the map-value with its entries in order of (byte-wise) sorted keys, such that the encoding
+// is deterministic.
 func (v MapValue) WriteTo(_out io.Writer) (int64, error) {
 	var err error
 	out := io_.NewCountingWriter(_out)
-	if len(v) <= int(SIZE_2BYTE_MAX) {
-		if _, err = writeHeader(&out, len(v), FLAG_TERMINATION|FLAG_KEYVALUE|FLAG_MULTIPLICITY); err != nil {
+	var keys = maps.ExtractKeys(v)
+	sort.Strings(keys)
+	for {
+		// Write entries in batches of at most SIZE_2BYTE_MAX, each batch with its own header.
+		var part = math.Min(uint(len(keys)), SIZE_2BYTE_MAX)
+		var flags = FLAG_KEYVALUE | FLAG_MULTIPLICITY
+		if part == uint(len(keys)) {
+			flags |= FLAG_TERMINATION
+		}
+		if _, err = writeHeader(&out, int(part), flags); err != nil {
 			return out.Cum, err
 		}
-		for key, value := range v {
+		for _, key := range keys[:part] {
 			if _, err = writeRaw(&out, []byte(key), FLAG_KEYVALUE); err != nil {
 				return out.Cum, err
 			}
-			if _, err = value.WriteTo(&out); err != nil {
+			if _, err = v[key].WriteTo(&out); err != nil {
 				return out.Cum, err
 			}
 		}
-	} else {
-		var total, processed, part = uint(len(v)), uint(0), uint(0)
-		for key, value := range v {
-			if part == 0 {
-				// Whenever part==0, start a new batch, i.e. new map-value with its own items.
-				part = math.Min(total-processed, SIZE_2BYTE_MAX)
-				assert.AtMost(total, processed+part)
-				assert.Positive(part)
-				if part <= SIZE_2BYTE_MAX {
-					flags := FLAG_KEYVALUE | FLAG_MULTIPLICITY
-					if processed+part == total {
-						flags |= FLAG_TERMINATION
-					}
-					if _, err = writeHeader(&out, int(part), flags); err != nil {
-						return out.Cum, err
-					}
-				} else {
-					panic("BUG: we should have selected at most SIZE_2BYTE_MAX for part")
-				}
-			}
-			if _, err = writeRaw(&out, []byte(key), FLAG_KEYVALUE); err != nil {
-				return out.Cum, err
-			}
-			if _, err = value.WriteTo(&out); err != nil {
-				return out.Cum, err
-			}
-			processed, part = processed+1, part-1
+		if keys = keys[part:]; len(keys) == 0 {
+			return out.Cum, nil
 		}
-		assert.Equal(0, part)
-		assert.Equal(total, processed)
 	}
-	return out.Cum, nil
 }
 
 // WriteMap write a map as encoded map-value.
 func WriteMap(out io.Writer, mapv map[string]Value) (int64, error) {
 	return MapValue(mapv).WriteTo(out)
 }
+
+// WriteMapOf write a map as encoded map-value. Map-values must either be a `Value` or a `PrefixedEncoder`.
+func WriteMapOf[V any](out io.Writer, mapv map[string]V) (int64, error) {
+	var values = make(MapValue, len(mapv))
+	var err error
+	for k, v := range mapv {
+		if values[k], err = valueOf(v); err != nil {
+			return 0, err
+		}
+	}
+	return values.WriteTo(out)
+}
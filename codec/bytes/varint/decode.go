@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package varint
+
+import (
+	"io"
+	"math"
+
+	"github.com/cobratbq/goutils/std/errors"
+	io_ "github.com/cobratbq/goutils/std/io"
+	"github.com/cobratbq/goutils/types"
+)
+
+// next processes the next byte `b` of the encoding into `value`. Returns whether more bytes follow.
+func next[T types.UnsignedInteger](value *T, b byte) (bool, error) {
+	var limit = ^T(0)
+	if *value > limit>>7 {
+		return false, errors.Context(errors.ErrOverflow, "encoded value exceeds range of data-type")
+	}
+	*value = *value<<7 | T(b&0x7f)
+	if b&0x80 == 0 {
+		return false, nil
+	}
+	if *value == limit {
+		return false, errors.Context(errors.ErrOverflow, "encoded value exceeds range of data-type")
+	}
+	*value++
+	return true, nil
+}
+
+func decode[T types.UnsignedInteger](buffer []byte) (T, uint, error) {
+	var value T
+	for i, b := range buffer {
+		more, err := next(&value, b)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !more {
+			return value, uint(i + 1), nil
+		}
+	}
+	return 0, 0, io.ErrUnexpectedEOF
+}
+
+func read[T types.UnsignedInteger](in io.Reader) (T, error) {
+	var value T
+	for first := true; ; first = false {
+		b, err := io_.ReadByte(in)
+		if err == io.EOF && !first {
+			return 0, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return 0, err
+		}
+		more, err := next(&value, b)
+		if err != nil {
+			return 0, err
+		}
+		if !more {
+			return value, nil
+		}
+	}
+}
+
+// Decode next value in buffer into `uint8`.
+//
+// Returns value as uint8, and number of bytes read. Returns `io.ErrUnexpectedEOF` if the buffer ends before
+// the end of the encoding, and `errors.ErrOverflow` if the value is too big to fit in the data-type.
+func DecodeUint8(buffer []byte) (uint8, uint, error) {
+	return decode[uint8](buffer)
+}
+
+// ReadUint8 reads the next value into `uint8`. See `DecodeUint8`.
+func ReadUint8(in io.Reader) (uint8, error) {
+	return read[uint8](in)
+}
+
+// Decode next value in buffer into `uint16`.
+//
+// Returns value as uint16, and number of bytes read. Returns `io.ErrUnexpectedEOF` if the buffer ends before
+// the end of the encoding, and `errors.ErrOverflow` if the value is too big to fit in the data-type.
+func DecodeUint16(buffer []byte) (uint16, uint, error) {
+	return decode[uint16](buffer)
+}
+
+// ReadUint16 reads the next value into `uint16`. See `DecodeUint16`.
+func ReadUint16(in io.Reader) (uint16, error) {
+	return read[uint16](in)
+}
+
+// Decode next value in buffer into `uint32`.
+//
+// Returns value as uint32, and number of bytes read. Returns `io.ErrUnexpectedEOF` if the buffer ends before
+// the end of the encoding, and `errors.ErrOverflow` if the value is too big to fit in the data-type.
+func DecodeUint32(buffer []byte) (uint32, uint, error) {
+	return decode[uint32](buffer)
+}
+
+// ReadUint32 reads the next value into `uint32`. See `DecodeUint32`.
+func ReadUint32(in io.Reader) (uint32, error) {
+	return read[uint32](in)
+}
+
+// Decode next value in buffer into `uint64`.
+//
+// Returns value as uint64, and number of bytes read. Returns `io.ErrUnexpectedEOF` if the buffer ends before
+// the end of the encoding, and `errors.ErrOverflow` if the value is too big to fit in the data-type.
+func DecodeUint64(buffer []byte) (uint64, uint, error) {
+	return decode[uint64](buffer)
+}
+
+// ReadUint64 reads the next value into `uint64`. See `DecodeUint64`.
+func ReadUint64(in io.Reader) (uint64, error) {
+	return read[uint64](in)
+}
+
+func DecodeUint(buffer []byte) (uint, uint, error) {
+	if types.MaxUint == math.MaxUint32 {
+		value, n, err := DecodeUint32(buffer)
+		return uint(value), n, err
+	}
+	value, n, err := DecodeUint64(buffer)
+	return uint(value), n, err
+}
+
+func ReadUint(in io.Reader) (uint, error) {
+	if types.MaxUint == math.MaxUint32 {
+		value, err := ReadUint32(in)
+		return uint(value), err
+	}
+	value, err := ReadUint64(in)
+	return uint(value), err
+}
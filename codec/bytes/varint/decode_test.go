@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package varint
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestDecodeUint64(t *testing.T) {
+	for _, test := range testvectors {
+		value, n, err := DecodeUint64(append(test.encoded, 0xaa))
+		assert.Nil(t, err)
+		assert.Equal(t, uint(len(test.encoded)), n)
+		assert.Equal(t, test.value, value)
+	}
+}
+
+func TestDecodeUint32(t *testing.T) {
+	for _, test := range testvectors {
+		value, n, err := DecodeUint32(test.encoded)
+		if test.value > math.MaxUint32 {
+			assert.IsError(t, errors.ErrOverflow, err)
+			assert.Equal(t, 0, n)
+			assert.Equal(t, 0, value)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, uint(len(test.encoded)), n)
+		assert.Equal(t, uint32(test.value), value)
+	}
+}
+
+func TestDecodeUint16(t *testing.T) {
+	for _, test := range testvectors {
+		value, n, err := DecodeUint16(test.encoded)
+		if test.value > math.MaxUint16 {
+			assert.IsError(t, errors.ErrOverflow, err)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, uint(len(test.encoded)), n)
+		assert.Equal(t, uint16(test.value), value)
+	}
+}
+
+func TestDecodeUint8(t *testing.T) {
+	testdata := []struct {
+		input []byte
+		value uint8
+		n     uint
+		err   error
+	}{
+		{[]byte{}, 0, 0, io.ErrUnexpectedEOF},
+		{[]byte{0x80}, 0, 0, io.ErrUnexpectedEOF},
+		{[]byte{0x00}, 0, 1, nil},
+		{[]byte{0x7f}, 0x7f, 1, nil},
+		{[]byte{0x80, 0x00}, 0x80, 2, nil},
+		{[]byte{0x80, 0x7f}, 0xff, 2, nil},
+		// 256: exceeds range through addition
+		{[]byte{0x81, 0x00}, 0, 0, errors.ErrOverflow},
+		// exceeds range through shifting
+		{[]byte{0x81, 0x80, 0x00}, 0, 0, errors.ErrOverflow},
+		{[]byte{0x80, 0x80, 0x80}, 0, 0, errors.ErrOverflow},
+	}
+	for _, test := range testdata {
+		value, n, err := DecodeUint8(test.input)
+		assert.IsError(t, test.err, err)
+		assert.Equal(t, test.n, n)
+		assert.Equal(t, test.value, value)
+	}
+}
+
+func TestDecodeUint64Overflow(t *testing.T) {
+	// one more than the maximum value
+	_, _, err := DecodeUint64([]byte{0x80, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xff, 0x00})
+	assert.IsError(t, errors.ErrOverflow, err)
+	_, _, err = DecodeUint64([]byte{0x80, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0x80, 0x00})
+	assert.IsError(t, errors.ErrOverflow, err)
+	_, _, err = DecodeUint64(bytes.Repeat([]byte{0xff}, 20))
+	assert.IsError(t, errors.ErrOverflow, err)
+}
+
+func TestDecodeUint(t *testing.T) {
+	value, n, err := DecodeUint([]byte{0x82, 0xfe, 0x7f})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 0xffff, value)
+}
+
+func TestRoundTrip(t *testing.T) {
+	var values = []uint64{0, 1, 0x7f, 0x80, 0x407f, 0x4080, 0x204080, math.MaxUint32, math.MaxUint64 - 1}
+	for _, v := range values {
+		value, n, err := DecodeUint64(EncodeUint64(v))
+		assert.Nil(t, err)
+		assert.Equal(t, uint(len(EncodeUint64(v))), n)
+		assert.Equal(t, v, value)
+	}
+}
+
+func TestReadUint(t *testing.T) {
+	var in = bytes.NewReader([]byte{0x80, 0x7f, 0x82, 0xfe, 0x7f, 0x86, 0xff, 0xc7, 0xe7, 0x56, 0x00, 0x7f,
+		0x80})
+	assert.Equal(t, uint8(0xff), builtin.Expect(ReadUint8(in)))
+	assert.Equal(t, uint16(0xffff), builtin.Expect(ReadUint16(in)))
+	assert.Equal(t, uint32(0x80123456), builtin.Expect(ReadUint32(in)))
+	assert.Equal(t, uint64(0), builtin.Expect(ReadUint64(in)))
+	assert.Equal(t, uint(0x7f), builtin.Expect(ReadUint(in)))
+	_, err := ReadUint8(in)
+	assert.IsError(t, io.ErrUnexpectedEOF, err)
+	_, err = ReadUint8(in)
+	assert.IsError(t, io.EOF, err)
+	_, err = ReadUint8(bytes.NewReader([]byte{0x81, 0x00}))
+	assert.IsError(t, errors.ErrOverflow, err)
+}
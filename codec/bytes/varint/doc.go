@@ -2,9 +2,17 @@
 
 // Bitcoin VarInt encoding, used internally in Bitcoin Core. (Not part of specification.)
 //
+// The encoding is big-endian base-128 with the most-significant bit of each byte indicating that more bytes
+// follow. Unlike protobuf's varints, every continuation subtracts one from the remaining value, such that
+// each value has exactly one encoding. For example, 127 encodes as `0x7f`, 128 as `0x80 0x00`, and 255 as
+// `0x80 0x7f`.
+//
+// Decoding rejects values that exceed the range of the data-type with `errors.ErrOverflow`.
+//
+// For the protobuf-style varints, i.e. LEB128, see package `leb128`.
+//
 // ref: <https://learnmeabitcoin.com/technical/general/compact-size/#varint>
 // ref: <https://github.com/in3rsha/bitcoin-chainstate-parser/blob/master/README.md#varints>
 // ref: <https://protobuf.dev/programming-guides/encoding/#varints>
 // ref: <https://github.com/bitcoin-core/gui-qml/blob/main/src/serialize.h#L373>
-// TODO implement Bitcoin 'VarInt' en-/decoding
 package varint
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package varint
+
+import (
+	"io"
+	"math"
+
+	"github.com/cobratbq/goutils/types"
+)
+
+// encodeInto encodes value into dest. Returns the number of bytes written, or 0 if dest is too small.
+func encodeInto[T types.UnsignedInteger](dest []byte, value T) uint {
+	// The encoding is produced least-significant byte first, then written in reverse.
+	var tmp [10]byte
+	var n int
+	for {
+		tmp[n] = byte(value & 0x7f)
+		if n > 0 {
+			tmp[n] |= 0x80
+		}
+		if value <= 0x7f {
+			break
+		}
+		value = (value >> 7) - 1
+		n++
+	}
+	if len(dest) < n+1 {
+		return 0
+	}
+	for i := 0; i <= n; i++ {
+		dest[i] = tmp[n-i]
+	}
+	return uint(n + 1)
+}
+
+// EncodeIntoUint8 encodes value into dest. Returns the number of bytes written, or 0 if dest is too small.
+func EncodeIntoUint8(dest []byte, value uint8) uint {
+	return encodeInto(dest, value)
+}
+
+func EncodeUint8(value uint8) []byte {
+	var data [2]byte
+	n := EncodeIntoUint8(data[:], value)
+	return data[:n]
+}
+
+func WriteUint8(out io.Writer, value uint8) error {
+	_, err := out.Write(EncodeUint8(value))
+	return err
+}
+
+// EncodeIntoUint16 encodes value into dest. Returns the number of bytes written, or 0 if dest is too small.
+func EncodeIntoUint16(dest []byte, value uint16) uint {
+	return encodeInto(dest, value)
+}
+
+func EncodeUint16(value uint16) []byte {
+	var data [3]byte
+	n := EncodeIntoUint16(data[:], value)
+	return data[:n]
+}
+
+func WriteUint16(out io.Writer, value uint16) error {
+	_, err := out.Write(EncodeUint16(value))
+	return err
+}
+
+// EncodeIntoUint32 encodes value into dest. Returns the number of bytes written, or 0 if dest is too small.
+func EncodeIntoUint32(dest []byte, value uint32) uint {
+	return encodeInto(dest, value)
+}
+
+func EncodeUint32(value uint32) []byte {
+	var data [5]byte
+	n := EncodeIntoUint32(data[:], value)
+	return data[:n]
+}
+
+func WriteUint32(out io.Writer, value uint32) error {
+	_, err := out.Write(EncodeUint32(value))
+	return err
+}
+
+// EncodeIntoUint64 encodes value into dest. Returns the number of bytes written, or 0 if dest is too small.
+func EncodeIntoUint64(dest []byte, value uint64) uint {
+	return encodeInto(dest, value)
+}
+
+func EncodeUint64(value uint64) []byte {
+	var data [10]byte
+	n := EncodeIntoUint64(data[:], value)
+	return data[:n]
+}
+
+func WriteUint64(out io.Writer, value uint64) error {
+	_, err := out.Write(EncodeUint64(value))
+	return err
+}
+
+func EncodeIntoUint(dest []byte, value uint) uint {
+	if types.MaxUint == math.MaxUint32 {
+		return EncodeIntoUint32(dest, uint32(value))
+	}
+	return EncodeIntoUint64(dest, uint64(value))
+}
+
+func EncodeUint(value uint) []byte {
+	if types.MaxUint == math.MaxUint32 {
+		return EncodeUint32(uint32(value))
+	}
+	return EncodeUint64(uint64(value))
+}
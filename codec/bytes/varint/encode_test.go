@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package varint
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+// testvectors are taken from Bitcoin Core's serialization tests.
+var testvectors = []struct {
+	value   uint64
+	encoded []byte
+}{
+	{0, []byte{0x00}},
+	{0x7f, []byte{0x7f}},
+	{0x80, []byte{0x80, 0x00}},
+	{0xff, []byte{0x80, 0x7f}},
+	{0x1234, []byte{0xa3, 0x34}},
+	{0xffff, []byte{0x82, 0xfe, 0x7f}},
+	{0x123456, []byte{0xc7, 0xe7, 0x56}},
+	{0x80123456, []byte{0x86, 0xff, 0xc7, 0xe7, 0x56}},
+	{0xffffffff, []byte{0x8e, 0xfe, 0xfe, 0xfe, 0x7f}},
+	{0x7fffffffffffffff, []byte{0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0x7f}},
+	{0xffffffffffffffff, []byte{0x80, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0xfe, 0x7f}},
+}
+
+func TestEncodeUint64(t *testing.T) {
+	for _, test := range testvectors {
+		assert.SlicesEqual(t, test.encoded, EncodeUint64(test.value))
+	}
+}
+
+func TestEncodeUint32(t *testing.T) {
+	for _, test := range testvectors {
+		if test.value <= math.MaxUint32 {
+			assert.SlicesEqual(t, test.encoded, EncodeUint32(uint32(test.value)))
+		}
+	}
+}
+
+func TestEncodeUint16(t *testing.T) {
+	for _, test := range testvectors {
+		if test.value <= math.MaxUint16 {
+			assert.SlicesEqual(t, test.encoded, EncodeUint16(uint16(test.value)))
+		}
+	}
+}
+
+func TestEncodeUint8(t *testing.T) {
+	for _, test := range testvectors {
+		if test.value <= math.MaxUint8 {
+			assert.SlicesEqual(t, test.encoded, EncodeUint8(uint8(test.value)))
+		}
+	}
+}
+
+func TestEncodeUint(t *testing.T) {
+	assert.SlicesEqual(t, []byte{0x82, 0xfe, 0x7f}, EncodeUint(0xffff))
+}
+
+func TestEncodeIntoInsufficientSpace(t *testing.T) {
+	var buffer [2]byte
+	assert.Equal(t, 0, EncodeIntoUint16(buffer[:], 0xffff))
+	assert.Equal(t, [2]byte{}, buffer)
+	assert.Equal(t, 0, EncodeIntoUint8(nil, 0))
+}
+
+func TestEncodeIntoConcat(t *testing.T) {
+	var buffer [6]byte
+	var idx uint
+	idx += EncodeIntoUint8(buffer[idx:], 0x7f)
+	idx += EncodeIntoUint32(buffer[idx:], 0x1234)
+	idx += EncodeIntoUint64(buffer[idx:], 0xffff)
+	assert.Equal(t, 6, idx)
+	assert.Equal(t, [6]byte{0x7f, 0xa3, 0x34, 0x82, 0xfe, 0x7f}, buffer)
+}
+
+func TestWriteUint(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, WriteUint8(&b, 0xff))
+	assert.Nil(t, WriteUint16(&b, 0xffff))
+	assert.Nil(t, WriteUint32(&b, 0x80123456))
+	assert.Nil(t, WriteUint64(&b, 0))
+	assert.SlicesEqual(t, []byte{0x80, 0x7f, 0x82, 0xfe, 0x7f, 0x86, 0xff, 0xc7, 0xe7, 0x56, 0x00}, b.Bytes())
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package base32
+
+import (
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+// CrockfordEncoding is Crockford's base32-encoding, without padding. Encoding produces upper-case
+// characters. Decoding is case-insensitive, maps `I` and `L` to `1` and `O` to `0`, and ignores hyphens.
+var CrockfordEncoding = newCrockfordEncoding()
+
+// checkSymbols are the additional symbols for check values 32 to 36.
+const checkSymbols = "*~$=U"
+
+// checkModulus is the modulus of the check value, i.e. the number of check symbols.
+const checkModulus = 37
+
+func newCrockfordEncoding() *Encoding {
+	var e = newEncoding("0123456789ABCDEFGHJKMNPQRSTVWXYZ", false)
+	for i, c := range e.alphabet {
+		if c >= 'A' && c <= 'Z' {
+			e.reverse[c-'A'+'a'] = int8(i)
+		}
+	}
+	for _, alias := range []struct {
+		c     byte
+		value int8
+	}{{'I', 1}, {'i', 1}, {'L', 1}, {'l', 1}, {'O', 0}, {'o', 0}} {
+		e.reverse[alias.c] = alias.value
+	}
+	e.reverse['-'] = ignored
+	return e
+}
+
+// EncodeCrockfordCheck encodes the content with Crockford's encoding, followed by the check symbol. The check
+// value is the numeric value of the encoded content modulo 37.
+func EncodeCrockfordCheck(data []byte) []byte {
+	var encoded = CrockfordEncoding.AppendEncode(make([]byte, 0, CrockfordEncoding.EncodedLen(len(data))+1),
+		data)
+	return append(encoded, checkSymbol(check(data)))
+}
+
+// DecodeCrockfordCheck decodes content with Crockford's encoding, followed by the check symbol, and verifies
+// the check symbol. The check symbol is case-insensitive.
+func DecodeCrockfordCheck(encoded []byte) ([]byte, error) {
+	if len(encoded) == 0 {
+		return nil, errors.Aggregate(errors.ErrIllegal, "check symbol is missing", ErrLength)
+	}
+	var symbol = encoded[len(encoded)-1]
+	var expected = checkValue(symbol)
+	if expected < 0 {
+		return nil, errors.Aggregate(errors.ErrIllegal, "unexpected check symbol "+
+			strconv.FormatUint(symbol, 16)+" at position "+strconv.FormatIntDecimal(len(encoded)-1), ErrCharacter)
+	}
+	// Padding of the encoding, if enabled, would conflict with the check symbol `=`.
+	decoded, err := CrockfordEncoding.WithPadding(false).Decode(encoded[:len(encoded)-1])
+	if err != nil {
+		return nil, err
+	}
+	if actual := check(decoded); actual != expected {
+		return nil, errors.Aggregate(errors.ErrIllegal, "expected check symbol "+
+			string(checkSymbol(actual))+", got "+string(symbol), ErrChecksum)
+	}
+	return decoded, nil
+}
+
+// check calculates the check value of the data: the numeric value of the encoded content, i.e. data followed
+// by the zero-bits to complete the final character, modulo 37.
+func check(data []byte) int {
+	var value int
+	for _, b := range data {
+		value = (value<<8 + int(b)) % checkModulus
+	}
+	var trailing = (5 - len(data)*8%5) % 5
+	return (value << trailing) % checkModulus
+}
+
+func checkSymbol(value int) byte {
+	if value < len(CrockfordEncoding.alphabet) {
+		return CrockfordEncoding.alphabet[value]
+	}
+	return checkSymbols[value-len(CrockfordEncoding.alphabet)]
+}
+
+// checkValue returns the value of the check symbol, or -1 if not a valid check symbol.
+func checkValue(symbol byte) int {
+	if symbol == 'u' {
+		symbol = 'U'
+	}
+	for i := 0; i < len(checkSymbols); i++ {
+		if checkSymbols[i] == symbol {
+			return len(CrockfordEncoding.alphabet) + i
+		}
+	}
+	if v := CrockfordEncoding.reverse[symbol]; v >= 0 {
+		return int(v)
+	}
+	return -1
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package base32
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestCrockfordEncoding(t *testing.T) {
+	assert.Equal(t, "", string(CrockfordEncoding.Encode(nil)))
+	assert.Equal(t, "04", string(CrockfordEncoding.Encode([]byte{0x01})))
+	assert.Equal(t, "CSQPYRK1E8", string(CrockfordEncoding.Encode([]byte("foobar"))))
+	assert.Equal(t, "ZZZZZZZZ", string(CrockfordEncoding.Encode([]byte{0xff, 0xff, 0xff, 0xff, 0xff})))
+}
+
+func TestCrockfordDecodeHumanInput(t *testing.T) {
+	for _, encoded := range []string{"CSQPYRK1E8", "csqpyrk1e8", "CSQP-YRK1-E8", "CsQpYrKiE8", "CSQPYRKLE8",
+		"CSQPYRK1-E-8"} {
+		assert.Equal(t, "foobar", string(CrockfordEncoding.MustDecodeString(encoded)))
+	}
+	assert.SlicesEqual(t, []byte{0, 0, 0, 0, 0}, CrockfordEncoding.MustDecodeString("oOo0-0o0O"))
+	for _, encoded := range []string{"U0", "CSQPYRK1E8=", "CSQP YRK1E8"} {
+		_, err := CrockfordEncoding.Decode([]byte(encoded))
+		assert.IsError(t, ErrCharacter, err)
+	}
+	_, err := CrockfordEncoding.Decode([]byte("CSQPYRK1E9"))
+	assert.IsError(t, ErrPadding, err)
+}
+
+func TestCrockfordCheck(t *testing.T) {
+	assert.Equal(t, "044", string(EncodeCrockfordCheck([]byte{0x01})))
+	assert.Equal(t, "0", string(EncodeCrockfordCheck(nil)))
+	assert.SlicesEqual(t, []byte{}, builtin.Expect(DecodeCrockfordCheck([]byte("0"))))
+	testdata := []struct {
+		encoded string
+		err     error
+	}{
+		{"", ErrLength},
+		{"04", ErrLength},
+		{"045", ErrChecksum},
+		{"04-", ErrCharacter},
+		{"0X4", ErrPadding},
+	}
+	for _, test := range testdata {
+		_, err := DecodeCrockfordCheck([]byte(test.encoded))
+		assert.IsError(t, test.err, err)
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+	for v := 0; v < checkModulus; v++ {
+		assert.Equal(t, v, checkValue(checkSymbol(v)))
+	}
+	assert.Equal(t, 36, checkValue('u'))
+	var data []byte
+	for i := 0; i < 40; i++ {
+		var encoded = EncodeCrockfordCheck(data)
+		// The check value is the numeric value of the encoded content, i.e. including trailing bits, modulo 37.
+		var value = new(big.Int).SetBytes(data)
+		value.Lsh(value, uint((5-len(data)*8%5)%5))
+		assert.Equal(t, value.Mod(value, big.NewInt(37)).Int64(), int64(checkValue(encoded[len(encoded)-1])))
+		decoded, err := DecodeCrockfordCheck(encoded)
+		assert.Nil(t, err)
+		assert.SlicesEqual(t, data, decoded)
+		data = append(data, byte(i*41+7))
+	}
+}
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// base32 is a binary-to-text codec that encodes 5 bits per character, with the RFC 4648 alphabets and
+// Crockford's alphabet.
+//
+// Decoding is strict: characters outside of the alphabet, incorrect padding, and non-zero trailing bits are
+// rejected, such that every input has exactly one accepted encoding. Crockford's encoding is intended for
+// human-typed identifiers, therefore its decoding is case-insensitive, maps `I` and `L` to `1` and `O` to
+// `0`, and ignores hyphens. Padding is optional for every encoding, see `Encoding.WithPadding`.
+//
+// Decoding errors are `errors.ErrIllegal`, as for the other codecs, together with one of the errors below
+// that indicates the specific cause.
+//
+// ref: <https://www.rfc-editor.org/rfc/rfc4648#section-6>
+// ref: <https://www.crockford.com/base32.html>
+package base32
+
+import "github.com/cobratbq/goutils/std/errors"
+
+// ErrCharacter indicates a character outside of the alphabet.
+var ErrCharacter = errors.NewStringError("illegal character")
+
+// ErrLength indicates that the number of characters cannot be the result of encoding.
+var ErrLength = errors.NewStringError("invalid length")
+
+// ErrPadding indicates incorrect padding, or non-zero bits in the final character.
+var ErrPadding = errors.NewStringError("invalid padding")
+
+// ErrChecksum indicates a check symbol that does not match the content.
+var ErrChecksum = errors.NewStringError("checksum mismatch")
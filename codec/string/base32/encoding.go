@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package base32
+
+import (
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+// padChar is the character used for padding.
+const padChar = '='
+
+// Values in the reverse-mapping for characters that are not part of the alphabet.
+const (
+	illegal int8 = -1
+	ignored int8 = -2
+)
+
+// StdEncoding is the standard base32-encoding of RFC 4648, with padding. The TOTP secrets of authenticator
+// apps typically use this alphabet without padding.
+var StdEncoding = newEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZ234567", true)
+
+// HexEncoding is the base32-encoding with "Extended Hex Alphabet" of RFC 4648, with padding.
+var HexEncoding = newEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUV", true)
+
+// Encode encodes the content into base32, using `StdEncoding`.
+func Encode(data []byte) []byte {
+	return StdEncoding.Encode(data)
+}
+
+// Decode decodes the base32-encoded content, using `StdEncoding`.
+func Decode(encoded []byte) ([]byte, error) {
+	return StdEncoding.Decode(encoded)
+}
+
+// MustDecode decodes the base32-encoded content, using `StdEncoding`, and panics on failure.
+func MustDecode(encoded []byte) []byte {
+	return StdEncoding.MustDecode(encoded)
+}
+
+// Encoding is a base32 encoding, defined by its alphabet and whether or not padding is used.
+type Encoding struct {
+	alphabet [32]byte
+	// reverse maps characters to their value, to `illegal`, or to `ignored` for characters that are skipped.
+	reverse [256]int8
+	padding bool
+}
+
+func newEncoding(alphabet string, padding bool) *Encoding {
+	var e = Encoding{padding: padding}
+	for i := range e.reverse {
+		e.reverse[i] = illegal
+	}
+	for i := range e.alphabet {
+		e.alphabet[i] = alphabet[i]
+		e.reverse[alphabet[i]] = int8(i)
+	}
+	return &e
+}
+
+// WithPadding returns a copy of the encoding with padding enabled or disabled.
+func (e *Encoding) WithPadding(padding bool) *Encoding {
+	var result = *e
+	result.padding = padding
+	return &result
+}
+
+// EncodedLen returns the length of the encoding of `n` bytes.
+func (e *Encoding) EncodedLen(n int) int {
+	if e.padding {
+		return (n + 4) / 5 * 8
+	}
+	return (n*8 + 4) / 5
+}
+
+// Encode encodes the content into base32.
+func (e *Encoding) Encode(data []byte) []byte {
+	return e.AppendEncode(make([]byte, 0, e.EncodedLen(len(data))), data)
+}
+
+// AppendEncode appends the base32-encoding of `src` to `dst` and returns the extended buffer.
+func (e *Encoding) AppendEncode(dst, src []byte) []byte {
+	var acc uint16
+	var bits uint
+	for _, b := range src {
+		acc = acc<<8 | uint16(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			dst = append(dst, e.alphabet[acc>>bits&0x1f])
+		}
+	}
+	if bits > 0 {
+		dst = append(dst, e.alphabet[acc<<(5-bits)&0x1f])
+	}
+	if e.padding {
+		for n := e.EncodedLen(len(src)) - (len(src)*8+4)/5; n > 0; n-- {
+			dst = append(dst, padChar)
+		}
+	}
+	return dst
+}
+
+// Decode decodes the base32-encoded content.
+func (e *Encoding) Decode(encoded []byte) ([]byte, error) {
+	return e.AppendDecode(make([]byte, 0, len(encoded)*5/8), encoded)
+}
+
+// MustDecode decodes the base32-encoded content, and panics on failure.
+func (e *Encoding) MustDecode(encoded []byte) []byte {
+	return builtin.Expect(e.Decode(encoded))
+}
+
+// MustDecodeString decodes the base32-encoded string, and panics on failure.
+func (e *Encoding) MustDecodeString(encoded string) []byte {
+	return builtin.Expect(e.Decode([]byte(encoded)))
+}
+
+// AppendDecode appends the decoded base32-encoded content of `src` to `dst` and returns the extended buffer.
+// In case of error, `dst` is returned unchanged, together with the error.
+func (e *Encoding) AppendDecode(dst, src []byte) ([]byte, error) {
+	var padding int
+	if e.padding {
+		for padding < len(src) && src[len(src)-1-padding] == padChar {
+			padding++
+		}
+	}
+	var result = dst
+	var acc uint16
+	var bits uint
+	var n, last int
+	for i, c := range src[:len(src)-padding] {
+		var v = e.reverse[c]
+		if v == ignored {
+			continue
+		} else if v == illegal {
+			return dst, errors.Aggregate(errors.ErrIllegal, "unexpected character "+strconv.FormatUint(c, 16)+
+				" at position "+strconv.FormatIntDecimal(i), ErrCharacter)
+		}
+		acc = acc<<5 | uint16(v)
+		bits += 5
+		n++
+		last = i
+		if bits >= 8 {
+			bits -= 8
+			result = append(result, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 {
+		return dst, errors.Aggregate(errors.ErrIllegal, strconv.FormatIntDecimal(n)+
+			" characters cannot be the result of encoding", ErrLength)
+	}
+	if acc&(1<<bits-1) != 0 {
+		return dst, errors.Aggregate(errors.ErrIllegal, "non-zero trailing bits at position "+
+			strconv.FormatIntDecimal(last), ErrPadding)
+	}
+	if e.padding && (n+padding)%8 != 0 || padding >= 8 {
+		return dst, errors.Aggregate(errors.ErrIllegal, "expected "+strconv.FormatIntDecimal((8-n%8)%8)+
+			" padding characters, got "+strconv.FormatIntDecimal(padding), ErrPadding)
+	}
+	return result, nil
+}
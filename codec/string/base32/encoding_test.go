@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package base32
+
+import (
+	"encoding/base32"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	testdata := []struct {
+		data   string
+		std    string
+		hex    string
+		rawStd string
+		rawHex string
+	}{
+		{"", "", "", "", ""},
+		{"f", "MY======", "CO======", "MY", "CO"},
+		{"fo", "MZXQ====", "CPNG====", "MZXQ", "CPNG"},
+		{"foo", "MZXW6===", "CPNMU===", "MZXW6", "CPNMU"},
+		{"foob", "MZXW6YQ=", "CPNMUOG=", "MZXW6YQ", "CPNMUOG"},
+		{"fooba", "MZXW6YTB", "CPNMUOJ1", "MZXW6YTB", "CPNMUOJ1"},
+		{"foobar", "MZXW6YTBOI======", "CPNMUOJ1E8======", "MZXW6YTBOI", "CPNMUOJ1E8"},
+	}
+	for _, test := range testdata {
+		for encoding, expected := range map[*Encoding]string{StdEncoding: test.std, HexEncoding: test.hex,
+			StdEncoding.WithPadding(false): test.rawStd, HexEncoding.WithPadding(false): test.rawHex} {
+			assert.Equal(t, expected, string(encoding.Encode([]byte(test.data))))
+			assert.Equal(t, len(expected), encoding.EncodedLen(len(test.data)))
+			assert.Equal(t, test.data, string(encoding.MustDecodeString(expected)))
+		}
+	}
+}
+
+func TestStandardLibrary(t *testing.T) {
+	var data []byte
+	for i := 0; i < 64; i++ {
+		assert.Equal(t, base32.StdEncoding.EncodeToString(data), string(StdEncoding.Encode(data)))
+		assert.Equal(t, base32.HexEncoding.WithPadding(base32.NoPadding).EncodeToString(data),
+			string(HexEncoding.WithPadding(false).Encode(data)))
+		data = append(data, byte(i*37+11))
+	}
+}
+
+func TestAppend(t *testing.T) {
+	var prefix = []byte("prefix:")
+	assert.Equal(t, "prefix:MZXW6===", string(StdEncoding.AppendEncode(prefix, []byte("foo"))))
+	decoded, err := StdEncoding.AppendDecode(prefix, []byte("MZXW6==="))
+	assert.Nil(t, err)
+	assert.Equal(t, "prefix:foo", string(decoded))
+	decoded, err = StdEncoding.AppendDecode(prefix, []byte("MZXW6=="))
+	assert.IsError(t, ErrPadding, err)
+	assert.Equal(t, "prefix:", string(decoded))
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	testdata := []struct {
+		encoding *Encoding
+		encoded  string
+		err      error
+	}{
+		{StdEncoding, "mzxw6===", ErrCharacter},
+		{StdEncoding, "MZXW1===", ErrCharacter},
+		{StdEncoding, "MZ=W6===", ErrCharacter},
+		{StdEncoding, "MZXW6", ErrPadding},
+		{StdEncoding, "MZXW6====", ErrPadding},
+		{StdEncoding, "========", ErrPadding},
+		{StdEncoding, "MZXW6YTBO=======", ErrLength},
+		{StdEncoding, "MZ======", ErrPadding},
+		{StdEncoding.WithPadding(false), "MZXW6===", ErrCharacter},
+		{StdEncoding.WithPadding(false), "M", ErrLength},
+		{StdEncoding.WithPadding(false), "MZX", ErrLength},
+		{StdEncoding.WithPadding(false), "MZXW6Y", ErrLength},
+		{StdEncoding.WithPadding(false), "MZ", ErrPadding},
+		{HexEncoding, "CPNMUOJ1E9======", ErrPadding},
+	}
+	for _, test := range testdata {
+		_, err := test.encoding.Decode([]byte(test.encoded))
+		assert.IsError(t, test.err, err)
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+}
+
+func TestPackageFunctions(t *testing.T) {
+	assert.Equal(t, "MZXW6===", string(Encode([]byte("foo"))))
+	assert.SlicesEqual(t, []byte("foo"), builtin.Expect(Decode([]byte("MZXW6==="))))
+	assert.SlicesEqual(t, []byte("foo"), MustDecode([]byte("MZXW6===")))
+	_, err := Decode([]byte("mzxw6==="))
+	assert.IsError(t, errors.ErrIllegal, err)
+	defer assert.RequirePanic(t)
+	MustDecode([]byte("MZXW6"))
+}
+
+func TestWithPadding(t *testing.T) {
+	var raw = StdEncoding.WithPadding(false)
+	assert.True(t, raw != StdEncoding)
+	assert.True(t, StdEncoding.padding)
+	assert.SlicesEqual(t, StdEncoding.Encode([]byte("foo")),
+		raw.WithPadding(true).Encode([]byte("foo")))
+	assert.Equal(t, "MZXW6", string(raw.Encode([]byte("foo"))))
+}
@@ -1,102 +1,127 @@
 // SPDX-License-Identifier: LGPL-3.0-only
 
 // base58 is a binary-to-text codec that encodes into a ASCII/ANSI-string (single bytes) and decodes back to
-// the original data in binary.
+// the original data in binary. The package-level functions use `BitcoinEncoding`.
 package base58
 
 import (
 	"bytes"
 	"crypto/sha256"
 	"crypto/subtle"
-	"math/big"
-	"slices"
+	"encoding/hex"
 
 	"github.com/cobratbq/goutils/std/errors"
-	"github.com/cobratbq/goutils/std/strconv"
 )
 
-var index [58]byte = [...]byte{'1', '2', '3', '4', '5', '6', '7', '8', '9', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'J', 'K', 'L', 'M', 'N', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'm', 'n', 'o', 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z'}
+// ErrChecksum indicates that the checksum does not match the content. It is reported together with
+// `errors.ErrIllegal`.
+var ErrChecksum = errors.NewStringError("checksum mismatch")
 
-func factor() big.Int {
-	var f big.Int
-	f.SetUint64(58)
-	return f
-}
+// ErrVersion indicates that the version-prefix is not the expected version. It is reported together with
+// `errors.ErrIllegal`.
+var ErrVersion = errors.NewStringError("unexpected version")
+
+// checksumSize is the size of the checksum for Base58Check: the first 4 bytes of the double-SHA256 hash.
+const checksumSize = 4
 
 // Encode encodes the content into Base58.
-func encode(data []byte) []byte {
-	var x big.Int
-	x.SetBytes(data)
-	var zero big.Int
-	factor := factor()
-	var rem big.Int
-	var result []byte
-	for x.Cmp(&zero) != 0 {
-		x.QuoRem(&x, &factor, &rem)
-		result = append(result, index[rem.Uint64()])
+func Encode(data []byte) []byte {
+	return BitcoinEncoding.Encode(data)
+}
+
+// AppendEncode appends the Base58-encoding of `src` to `dst` and returns the extended buffer.
+func AppendEncode(dst, src []byte) []byte {
+	return BitcoinEncoding.AppendEncode(dst, src)
+}
+
+// Decode decodes the Base58-encoded content.
+func Decode(encoded []byte) ([]byte, error) {
+	return BitcoinEncoding.Decode(encoded)
+}
+
+// AppendDecode appends the decoded Base58-encoded content of `src` to `dst` and returns the extended buffer.
+// In case of error, `dst` is returned unchanged, together with the error.
+func AppendDecode(dst, src []byte) ([]byte, error) {
+	return BitcoinEncoding.AppendDecode(dst, src)
+}
+
+// checksum calculates the checksum for data.
+func checksum(data []byte) [checksumSize]byte {
+	check := sha256.Sum256(data)
+	check = sha256.Sum256(check[:])
+	return [checksumSize]byte(check[:checksumSize])
+}
+
+// verify verifies the checksum that is concatenated to the data, and returns the data without checksum.
+func verify(dataWithChecksum []byte) ([]byte, error) {
+	if len(dataWithChecksum) < checksumSize {
+		return nil, errors.Aggregate(errors.ErrIllegal, "content too short to contain Base58 check-code",
+			ErrChecksum)
 	}
-	for i := 0; i < len(data) && data[i] == 0; i++ {
-		result = append(result, '1')
+	var data = dataWithChecksum[:len(dataWithChecksum)-checksumSize]
+	check := checksum(data)
+	if subtle.ConstantTimeCompare(check[:], dataWithChecksum[len(data):]) != 1 {
+		return nil, errors.Aggregate(errors.ErrIllegal, "Base58 check-code does not match", ErrChecksum)
 	}
-	slices.Reverse(result)
-	return result
+	return data, nil
 }
 
 // ChecksumEncode calculates the 4-byte checksum, concatenates the checksum, then encodes the content into
 // Base58.
 func ChecksumEncode(data []byte) []byte {
-	check := sha256.Sum256(data)
-	check = sha256.Sum256(check[:])
-	content := make([]byte, 0, len(data)+4)
-	content = append(content, data...)
-	content = append(content, check[:4]...)
-	return encode(content)
+	return BitcoinEncoding.EncodeVersioned(nil, data)
 }
 
 // CheckEncode checks the concatenated 4-byte checksum then encodes the data to Base58.
 func CheckEncode(dataWithChecksum []byte) ([]byte, error) {
-	check := sha256.Sum256(dataWithChecksum[:len(dataWithChecksum)-4])
-	check = sha256.Sum256(check[:])
-	if subtle.ConstantTimeCompare(check[:4], dataWithChecksum[len(dataWithChecksum)-4:]) != 1 {
-		return nil, errors.Context(errors.ErrIllegal, "Base58 check-code does not match")
-	}
-	return encode(dataWithChecksum), nil
-}
-
-// Decode decodes the Base58-encoded content.
-func Decode(encoded []byte) ([]byte, error) {
-	factor := factor()
-	var x big.Int
-	var v big.Int
-	var val int
-	for i := 0; i < len(encoded); i++ {
-		x.Mul(&x, &factor)
-		val = bytes.IndexByte(index[:], encoded[i])
-		if val == -1 {
-			return nil, errors.Context(errors.ErrIllegal, "unexpected value in Base58-encoded content: "+strconv.FormatUint(encoded[i], 16))
-		}
-		v.SetInt64(int64(val))
-		x.Add(&x, &v)
-	}
-	var result []byte
-	for i := 0; i < len(encoded) && encoded[i] == '1'; i++ {
-		result = append(result, 0)
+	if _, err := verify(dataWithChecksum); err != nil {
+		return nil, err
 	}
-	result = append(result, x.Bytes()...)
-	return result, nil
+	return Encode(dataWithChecksum), nil
 }
 
 // Decodes the Base58-content then checks the checksum.
 // Returns content without the checksum.
 func CheckDecode(encoded []byte) ([]byte, error) {
-	result, err := Decode(encoded)
+	return BitcoinEncoding.DecodeVersioned(nil, encoded)
+}
+
+// EncodeVersioned encodes the payload prefixed with version and suffixed with checksum (Base58Check), e.g.
+// version 0x00 for P2PKH addresses and 0x80 for WIF private keys.
+func EncodeVersioned(version, payload []byte) []byte {
+	return BitcoinEncoding.EncodeVersioned(version, payload)
+}
+
+// DecodeVersioned decodes Base58Check-content, verifies the checksum and the version-prefix, and returns the
+// payload.
+func DecodeVersioned(version, encoded []byte) ([]byte, error) {
+	return BitcoinEncoding.DecodeVersioned(version, encoded)
+}
+
+// EncodeVersioned encodes the payload prefixed with version and suffixed with checksum (Base58Check).
+func (e *Encoding) EncodeVersioned(version, payload []byte) []byte {
+	content := make([]byte, 0, len(version)+len(payload)+checksumSize)
+	content = append(content, version...)
+	content = append(content, payload...)
+	check := checksum(content)
+	return e.Encode(append(content, check[:]...))
+}
+
+// DecodeVersioned decodes Base58Check-content, verifies the checksum and the version-prefix, and returns the
+// payload. Errors are `ErrCharacter` for characters outside of the alphabet, `ErrChecksum` for a mismatching
+// checksum and `ErrVersion` for unexpected version bytes. All errors are also `errors.ErrIllegal`.
+func (e *Encoding) DecodeVersioned(version, encoded []byte) ([]byte, error) {
+	decoded, err := e.Decode(encoded)
 	if err != nil {
 		return nil, err
 	}
-	check := sha256.Sum256(result[:len(result)-4])
-	check = sha256.Sum256(check[:])
-	if subtle.ConstantTimeCompare(check[:4], result[len(result)-4:]) != 1 {
-		return nil, errors.Context(errors.ErrIllegal, "Base58 check-code does not match")
+	var data []byte
+	if data, err = verify(decoded); err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, version) {
+		return nil, errors.Aggregate(errors.ErrIllegal, "expected version "+hex.EncodeToString(version)+
+			", got "+hex.EncodeToString(data[:min(len(version), len(data))]), ErrVersion)
 	}
-	return result[:len(result)-4], err
+	return data[len(version):], nil
 }
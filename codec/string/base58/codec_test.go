@@ -3,10 +3,14 @@
 package base58
 
 import (
+	"bytes"
 	"encoding/hex"
+	"math/big"
+	"slices"
 	"testing"
 
 	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
 	assert "github.com/cobratbq/goutils/std/testing"
 )
 
@@ -40,3 +44,221 @@ func TestDecodeBase58Check(t *testing.T) {
 		assert.SlicesEqual(t, entry.decoded, result)
 	}
 }
+
+func TestEncodeDecodeVersioned(t *testing.T) {
+	testdata := []struct {
+		version []byte
+		payload string
+		encoded string
+	}{
+		// P2PKH address
+		{[]byte{0x00}, "f54a5851e9372b87810a8e60cdd2e7cfd80b6e31", "1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs"},
+		// WIF private key
+		{[]byte{0x80}, "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d",
+			"5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ"},
+	}
+	for _, test := range testdata {
+		var payload = builtin.Expect(hex.DecodeString(test.payload))
+		assert.Equal(t, test.encoded, string(EncodeVersioned(test.version, payload)))
+		decoded, err := DecodeVersioned(test.version, []byte(test.encoded))
+		assert.Nil(t, err)
+		assert.SlicesEqual(t, payload, decoded)
+	}
+}
+
+func TestDecodeVersionedErrors(t *testing.T) {
+	var address = []byte("1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs")
+	_, err := DecodeVersioned([]byte{0x05}, address)
+	assert.IsError(t, ErrVersion, err)
+	_, err = DecodeVersioned([]byte{0x00, 0xf5, 0x4a}, address)
+	assert.Nil(t, err)
+	_, err = DecodeVersioned([]byte{0x00}, []byte("1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAt"))
+	assert.IsError(t, ErrChecksum, err)
+	_, err = DecodeVersioned([]byte{0x00}, []byte("1PMycacnJaSqwwJqjawXBErnLsZ7RkXUA0"))
+	assert.IsError(t, ErrCharacter, err)
+	_, err = DecodeVersioned([]byte{0x00}, []byte("1112"))
+	assert.IsError(t, ErrChecksum, err)
+	_, err = DecodeVersioned([]byte{0x00, 0x00}, EncodeVersioned([]byte{0x00}, nil))
+	assert.IsError(t, ErrVersion, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestCheckErrors(t *testing.T) {
+	_, err := CheckDecode([]byte("1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAt"))
+	assert.IsError(t, ErrChecksum, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = CheckDecode([]byte("z"))
+	assert.IsError(t, ErrChecksum, err)
+	_, err = CheckEncode([]byte{0x00, 0x01, 0x02, 0x03, 0x04})
+	assert.IsError(t, ErrChecksum, err)
+	_, err = CheckEncode([]byte{0x00})
+	assert.IsError(t, ErrChecksum, err)
+	assert.Equal(t, "1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs", string(ChecksumEncode(
+		builtin.Expect(hex.DecodeString("00f54a5851e9372b87810a8e60cdd2e7cfd80b6e31")))))
+}
+
+// encodeReference is the original `math/big`-based implementation, as reference for equivalence.
+func encodeReference(data []byte) []byte {
+	var x big.Int
+	x.SetBytes(data)
+	var zero big.Int
+	var factor = big.NewInt(58)
+	var rem big.Int
+	var result []byte
+	for x.Cmp(&zero) != 0 {
+		x.QuoRem(&x, factor, &rem)
+		result = append(result, BitcoinEncoding.alphabet[rem.Uint64()])
+	}
+	for i := 0; i < len(data) && data[i] == 0; i++ {
+		result = append(result, '1')
+	}
+	slices.Reverse(result)
+	return result
+}
+
+// decodeReference is the original `math/big`-based implementation, as reference for equivalence.
+func decodeReference(encoded []byte) ([]byte, bool) {
+	var factor = big.NewInt(58)
+	var x big.Int
+	var v big.Int
+	for i := 0; i < len(encoded); i++ {
+		x.Mul(&x, factor)
+		val := bytes.IndexByte(BitcoinEncoding.alphabet[:], encoded[i])
+		if val == -1 {
+			return nil, false
+		}
+		v.SetInt64(int64(val))
+		x.Add(&x, &v)
+	}
+	var result []byte
+	for i := 0; i < len(encoded) && encoded[i] == '1'; i++ {
+		result = append(result, 0)
+	}
+	return append(result, x.Bytes()...), true
+}
+
+func TestPowers(t *testing.T) {
+	var p uint64 = 1
+	for i := range powers {
+		assert.Equal(t, p, powers[i])
+		p *= 58
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	testdata := []struct {
+		data    []byte
+		encoded string
+	}{
+		{[]byte{}, ""},
+		{[]byte{0}, "1"},
+		{[]byte{0, 0, 0}, "111"},
+		{[]byte{57}, "z"},
+		{[]byte{58}, "21"},
+		{[]byte{0, 0, 1}, "112"},
+		{[]byte("Hello World!"), "2NEpo7TZRRrLZSi2U"},
+		{[]byte("The quick brown fox jumps over the lazy dog."),
+			"USm3fpXnKG5EUBx2ndxBDMPVciP5hGey2Jh4NDv6gmeo1LkMeiKrLJUUBk6Z"},
+		{builtin.Expect(hex.DecodeString("0000287fb4cd")), "11233QC4"},
+		{bytes.Repeat([]byte{0xff}, 8), "jpXCZedGfVQ"},
+		{bytes.Repeat([]byte{0xff}, 9), "4FzkJ37568tQv"},
+	}
+	for _, test := range testdata {
+		assert.Equal(t, test.encoded, string(Encode(test.data)))
+		decoded, err := Decode([]byte(test.encoded))
+		assert.Nil(t, err)
+		assert.SlicesEqual(t, test.data, decoded)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	var prefix = []byte("prefix:")
+	assert.Equal(t, "prefix:2NEpo7TZRRrLZSi2U", string(AppendEncode(prefix, []byte("Hello World!"))))
+	decoded, err := AppendDecode(prefix, []byte("2NEpo7TZRRrLZSi2U"))
+	assert.Nil(t, err)
+	assert.Equal(t, "prefix:Hello World!", string(decoded))
+	decoded, err = AppendDecode(prefix, []byte("2NEpo7TZRRrLZSi2U0"))
+	assert.IsError(t, errors.ErrIllegal, err)
+	assert.Equal(t, "prefix:", string(decoded))
+}
+
+func TestDecodeIllegal(t *testing.T) {
+	for _, encoded := range []string{"0", "O", "I", "l", "1+", "2NEpo7TZRRrLZSi2U\x00"} {
+		_, err := Decode([]byte(encoded))
+		assert.IsError(t, errors.ErrIllegal, err)
+		assert.IsError(t, ErrCharacter, err)
+	}
+}
+
+func FuzzEncode(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 1, 2, 3})
+	f.Add(bytes.Repeat([]byte{0xff}, 65))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var encoded = Encode(data)
+		assert.SlicesEqual(t, encodeReference(data), encoded)
+		decoded, err := Decode(encoded)
+		assert.Nil(t, err)
+		assert.SlicesEqual(t, data, decoded)
+	})
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add([]byte("1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs"))
+	f.Add([]byte("11z"))
+	f.Add([]byte("0"))
+	f.Fuzz(func(t *testing.T, encoded []byte) {
+		decoded, err := Decode(encoded)
+		expected, ok := decodeReference(encoded)
+		assert.Equal(t, ok, err == nil)
+		if ok {
+			assert.SlicesEqual(t, expected, decoded)
+		}
+	})
+}
+
+var benchmarkData = builtin.Expect(hex.DecodeString(
+	"00f54a5851e9372b87810a8e60cdd2e7cfd80b6e31c7f18fe8"))
+
+func BenchmarkEncode(b *testing.B) {
+	var buffer []byte
+	for i := 0; i < b.N; i++ {
+		buffer = AppendEncode(buffer[:0], benchmarkData)
+	}
+}
+
+func BenchmarkEncodeReference(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		encodeReference(benchmarkData)
+	}
+}
+
+func BenchmarkEncodeLarge(b *testing.B) {
+	var data = bytes.Repeat(benchmarkData, 40)
+	var buffer []byte
+	for i := 0; i < b.N; i++ {
+		buffer = AppendEncode(buffer[:0], data)
+	}
+}
+
+func BenchmarkEncodeLargeReference(b *testing.B) {
+	var data = bytes.Repeat(benchmarkData, 40)
+	for i := 0; i < b.N; i++ {
+		encodeReference(data)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	var encoded = Encode(benchmarkData)
+	var buffer []byte
+	for i := 0; i < b.N; i++ {
+		buffer, _ = AppendDecode(buffer[:0], encoded)
+	}
+}
+
+func BenchmarkDecodeReference(b *testing.B) {
+	var encoded = Encode(benchmarkData)
+	for i := 0; i < b.N; i++ {
+		decodeReference(encoded)
+	}
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package base58
+
+import (
+	"math/bits"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+// ErrCharacter indicates that the encoded content contains a character outside of the alphabet. It is
+// reported together with `errors.ErrIllegal`.
+var ErrCharacter = errors.NewStringError("illegal character")
+
+// BitcoinEncoding is the encoding with the alphabet as used by Bitcoin. This is the default encoding.
+var BitcoinEncoding = builtin.Expect(NewEncoding(
+	"123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"))
+
+// RippleEncoding is the encoding with the alphabet as used by Ripple.
+var RippleEncoding = builtin.Expect(NewEncoding(
+	"rpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65jkm8oFqi1tuvAxyz"))
+
+// FlickrEncoding is the encoding with the alphabet as used by Flickr for short URLs.
+var FlickrEncoding = builtin.Expect(NewEncoding(
+	"123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"))
+
+// Encoding is a Base58 encoding defined by its alphabet. The first character of the alphabet, with value 0,
+// represents leading zero-bytes.
+type Encoding struct {
+	alphabet [58]byte
+	// reverse maps characters to their value, or -1 for characters outside of the alphabet.
+	reverse [256]int8
+}
+
+// NewEncoding creates an encoding for the alphabet. The alphabet must consist of 58 unique ASCII characters.
+func NewEncoding(alphabet string) (*Encoding, error) {
+	if len(alphabet) != len(Encoding{}.alphabet) {
+		return nil, errors.Context(errors.ErrIllegal, "alphabet must consist of 58 characters, got "+
+			strconv.FormatIntDecimal(len(alphabet)))
+	}
+	var e Encoding
+	for i := range e.reverse {
+		e.reverse[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		var c = alphabet[i]
+		if c >= 0x80 {
+			return nil, errors.Context(errors.ErrIllegal, "alphabet contains non-ASCII character")
+		}
+		if e.reverse[c] >= 0 {
+			return nil, errors.Context(errors.ErrIllegal, "alphabet contains duplicate character: "+
+				string(c))
+		}
+		e.alphabet[i] = c
+		e.reverse[c] = int8(i)
+	}
+	return &e, nil
+}
+
+// Conversion is performed on limbs, i.e. multi-digit chunks, rather than individual digits: base58-limbs of
+// `limbDigits` digits each, such that a limb fits in an uint64, and base-2^64-limbs of 8 bytes each.
+const (
+	limbDigits = 10
+	// limbBase is 58^limbDigits.
+	limbBase uint64 = 430804206899405824
+)
+
+// powers are the powers of 58 up to and including limbBase.
+var powers = [limbDigits + 1]uint64{1, 58, 3364, 195112, 11316496, 656356768, 38068692544, 2207984167552,
+	128063081718016, 7427658739644928, limbBase}
+
+// Encode encodes the content into Base58.
+func (e *Encoding) Encode(data []byte) []byte {
+	return e.AppendEncode(nil, data)
+}
+
+// AppendEncode appends the Base58-encoding of `src` to `dst` and returns the extended buffer.
+func (e *Encoding) AppendEncode(dst, src []byte) []byte {
+	var zeros int
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+	for i := 0; i < zeros; i++ {
+		dst = append(dst, e.alphabet[0])
+	}
+	src = src[zeros:]
+	// limbs are the base58-limbs, least-significant first. The backing array accommodates typical inputs, such
+	// as addresses and keys, without allocation.
+	var buffer [16]uint64
+	var limbs = buffer[:0]
+	for len(src) > 0 {
+		// The first chunk takes the remainder of bytes, such that subsequent chunks are 8 bytes.
+		var n = (len(src)-1)%8 + 1
+		var carry uint64
+		for _, b := range src[:n] {
+			carry = carry<<8 | uint64(b)
+		}
+		src = src[n:]
+		for i := range limbs {
+			var hi, lo uint64
+			if n == 8 {
+				hi, lo = limbs[i], carry
+			} else {
+				var c uint64
+				hi, lo = bits.Mul64(limbs[i], 1<<(8*n))
+				lo, c = bits.Add64(lo, carry, 0)
+				hi += c
+			}
+			carry, limbs[i] = bits.Div64(hi, lo, limbBase)
+		}
+		for ; carry > 0; carry /= limbBase {
+			limbs = append(limbs, carry%limbBase)
+		}
+	}
+	if len(limbs) == 0 {
+		return dst
+	}
+	// The most-significant limb is written without leading zeroes, other limbs in full.
+	var digits [limbDigits]byte
+	var n = 0
+	for v := limbs[len(limbs)-1]; v > 0; v /= 58 {
+		n++
+		digits[limbDigits-n] = e.alphabet[v%58]
+	}
+	dst = append(dst, digits[limbDigits-n:]...)
+	for i := len(limbs) - 2; i >= 0; i-- {
+		var v = limbs[i]
+		for j := limbDigits - 1; j >= 0; j-- {
+			digits[j] = e.alphabet[v%58]
+			v /= 58
+		}
+		dst = append(dst, digits[:]...)
+	}
+	return dst
+}
+
+// Decode decodes the Base58-encoded content.
+func (e *Encoding) Decode(encoded []byte) ([]byte, error) {
+	return e.AppendDecode(nil, encoded)
+}
+
+// AppendDecode appends the decoded Base58-encoded content of `src` to `dst` and returns the extended buffer.
+// In case of error, `dst` is returned unchanged, together with the error.
+func (e *Encoding) AppendDecode(dst, src []byte) ([]byte, error) {
+	var zeros int
+	for zeros < len(src) && src[zeros] == e.alphabet[0] {
+		zeros++
+	}
+	// limbs are the base-2^64-limbs, least-significant first.
+	var buffer [16]uint64
+	var limbs = buffer[:0]
+	for i := zeros; i < len(src); {
+		var n = min(limbDigits, len(src)-i)
+		var carry uint64
+		for _, c := range src[i : i+n] {
+			var v = e.reverse[c]
+			if v < 0 {
+				return dst, errors.Aggregate(errors.ErrIllegal, "unexpected value in Base58-encoded content: "+
+					strconv.FormatUint(c, 16), ErrCharacter)
+			}
+			carry = carry*58 + uint64(v)
+		}
+		i += n
+		for j := range limbs {
+			var hi, lo, c uint64
+			hi, lo = bits.Mul64(limbs[j], powers[n])
+			limbs[j], c = bits.Add64(lo, carry, 0)
+			carry = hi + c
+		}
+		if carry > 0 {
+			limbs = append(limbs, carry)
+		}
+	}
+	for i := 0; i < zeros; i++ {
+		dst = append(dst, 0)
+	}
+	if len(limbs) == 0 {
+		return dst, nil
+	}
+	// The most-significant limb is written without leading zero-bytes, other limbs in full.
+	var top = limbs[len(limbs)-1]
+	for shift := (bits.Len64(top) - 1) / 8 * 8; shift >= 0; shift -= 8 {
+		dst = append(dst, byte(top>>shift))
+	}
+	for i := len(limbs) - 2; i >= 0; i-- {
+		for shift := 56; shift >= 0; shift -= 8 {
+			dst = append(dst, byte(limbs[i]>>shift))
+		}
+	}
+	return dst, nil
+}
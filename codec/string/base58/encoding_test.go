@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package base58
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestNewEncoding(t *testing.T) {
+	var alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	var custom = builtin.Expect(NewEncoding(alphabet))
+	assert.Equal(t, *BitcoinEncoding, *custom)
+	for _, invalid := range []string{"", alphabet[1:], alphabet + "0", "0" + alphabet[1:] + "0",
+		"é" + alphabet[2:]} {
+		_, err := NewEncoding(invalid)
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+}
+
+func TestRippleEncoding(t *testing.T) {
+	var account = builtin.Expect(hex.DecodeString("b5f762798a53d543a014caf8b297cff8f2f937e8"))
+	var encoded = "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+	assert.Equal(t, encoded, string(RippleEncoding.EncodeVersioned([]byte{0x00}, account)))
+	decoded, err := RippleEncoding.DecodeVersioned([]byte{0x00}, []byte(encoded))
+	assert.Nil(t, err)
+	assert.SlicesEqual(t, account, decoded)
+	// leading zero-bytes are encoded with the first character of the alphabet
+	assert.Equal(t, "rrp", string(RippleEncoding.Encode([]byte{0, 0, 1})))
+	_, err = RippleEncoding.Decode([]byte("rrl"))
+	assert.IsError(t, ErrCharacter, err)
+}
+
+func TestFlickrEncoding(t *testing.T) {
+	// Digits are equal in value, only the characters of the alphabet differ.
+	for _, data := range [][]byte{{}, {0, 0, 1}, []byte("Hello World!"), bytes.Repeat([]byte{0xff}, 33)} {
+		var encoded = FlickrEncoding.Encode(data)
+		assert.Equal(t, translate(BitcoinEncoding, FlickrEncoding, Encode(data)), string(encoded))
+		decoded, err := FlickrEncoding.Decode(encoded)
+		assert.Nil(t, err)
+		assert.SlicesEqual(t, data, decoded)
+	}
+	_, err := FlickrEncoding.Decode([]byte("l"))
+	assert.IsError(t, ErrCharacter, err)
+}
+
+// translate translates the characters of `encoded` from one alphabet to the other.
+func translate(from, to *Encoding, encoded []byte) string {
+	var result = make([]byte, len(encoded))
+	for i, c := range encoded {
+		result[i] = to.alphabet[from.reverse[c]]
+	}
+	return string(result)
+}
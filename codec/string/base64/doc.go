@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// base64 provides the base64-encodings of RFC 4648, with the conventions of the other codecs, on top of the
+// standard library's `encoding/base64`.
+//
+// Decoding is strict: characters outside of the alphabet, incorrect padding and non-zero trailing bits are
+// rejected. As with the standard library, newline characters (`\r`, `\n`) are ignored. Padding is optional
+// for every encoding, see `Encoding.WithPadding`.
+//
+// Decoding errors are `errors.ErrIllegal`, as for the other codecs, together with one of the errors below
+// that indicates the specific cause.
+//
+// ref: <https://www.rfc-editor.org/rfc/rfc4648#section-4>
+package base64
+
+import "github.com/cobratbq/goutils/std/errors"
+
+// ErrCharacter indicates a character outside of the alphabet.
+var ErrCharacter = errors.NewStringError("illegal character")
+
+// ErrLength indicates that the number of characters cannot be the result of encoding.
+var ErrLength = errors.NewStringError("invalid length")
+
+// ErrPadding indicates missing or incorrect padding, or non-zero bits in the final character.
+var ErrPadding = errors.NewStringError("invalid padding")
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package base64
+
+import (
+	base64_ "encoding/base64"
+	"strings"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+// StdEncoding is the standard base64-encoding of RFC 4648, with padding.
+var StdEncoding = newEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/")
+
+// URLEncoding is the URL- and filename-safe base64-encoding of RFC 4648, with padding. JSON Web Tokens and
+// similar formats use this alphabet without padding.
+var URLEncoding = newEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_")
+
+// Encode encodes the content into base64, using `StdEncoding`.
+func Encode(data []byte) []byte {
+	return StdEncoding.Encode(data)
+}
+
+// Decode decodes the base64-encoded content, using `StdEncoding`.
+func Decode(encoded []byte) ([]byte, error) {
+	return StdEncoding.Decode(encoded)
+}
+
+// MustDecode decodes the base64-encoded content, using `StdEncoding`, and panics on failure.
+func MustDecode(encoded []byte) []byte {
+	return StdEncoding.MustDecode(encoded)
+}
+
+// Encoding is a base64 encoding, defined by its alphabet and whether or not padding is used.
+type Encoding struct {
+	// alphabet is retained to determine the cause of decoding errors.
+	alphabet string
+	encoding *base64_.Encoding
+}
+
+func newEncoding(alphabet string) *Encoding {
+	return &Encoding{alphabet: alphabet, encoding: base64_.NewEncoding(alphabet).Strict()}
+}
+
+// WithPadding returns a copy of the encoding with padding enabled or disabled.
+func (e *Encoding) WithPadding(padding bool) *Encoding {
+	var char = base64_.NoPadding
+	if padding {
+		char = base64_.StdPadding
+	}
+	return &Encoding{alphabet: e.alphabet, encoding: e.encoding.WithPadding(char).Strict()}
+}
+
+// EncodedLen returns the length of the encoding of `n` bytes.
+func (e *Encoding) EncodedLen(n int) int {
+	return e.encoding.EncodedLen(n)
+}
+
+// Encode encodes the content into base64.
+func (e *Encoding) Encode(data []byte) []byte {
+	return e.encoding.AppendEncode(make([]byte, 0, e.EncodedLen(len(data))), data)
+}
+
+// AppendEncode appends the base64-encoding of `src` to `dst` and returns the extended buffer.
+func (e *Encoding) AppendEncode(dst, src []byte) []byte {
+	return e.encoding.AppendEncode(dst, src)
+}
+
+// Decode decodes the base64-encoded content.
+func (e *Encoding) Decode(encoded []byte) ([]byte, error) {
+	return e.AppendDecode(nil, encoded)
+}
+
+// MustDecode decodes the base64-encoded content, and panics on failure.
+func (e *Encoding) MustDecode(encoded []byte) []byte {
+	return builtin.Expect(e.Decode(encoded))
+}
+
+// MustDecodeString decodes the base64-encoded string, and panics on failure.
+func (e *Encoding) MustDecodeString(encoded string) []byte {
+	return builtin.Expect(e.Decode([]byte(encoded)))
+}
+
+// AppendDecode appends the decoded base64-encoded content of `src` to `dst` and returns the extended buffer.
+// In case of error, `dst` is returned unchanged, together with the error.
+func (e *Encoding) AppendDecode(dst, src []byte) ([]byte, error) {
+	result, err := e.encoding.AppendDecode(dst, src)
+	if corrupt, ok := err.(base64_.CorruptInputError); ok {
+		return dst, errors.Aggregate(errors.ErrIllegal, "illegal base64-encoded content at position "+
+			strconv.FormatIntDecimal(int64(corrupt)), e.cause(src))
+	} else if err != nil {
+		return dst, err
+	}
+	return result, nil
+}
+
+// cause determines the specific cause of illegal content, as the standard library only reports a position.
+func (e *Encoding) cause(src []byte) error {
+	var n int
+	var padded bool
+	for _, c := range src {
+		switch {
+		case c == '\r' || c == '\n':
+		case c == '=':
+			padded = true
+		case strings.IndexByte(e.alphabet, c) < 0:
+			return ErrCharacter
+		case !padded:
+			n++
+		}
+	}
+	if n%4 == 1 {
+		return ErrLength
+	}
+	return ErrPadding
+}
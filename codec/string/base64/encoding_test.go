@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package base64
+
+import (
+	"testing"
+
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	testdata := []struct {
+		data   string
+		std    string
+		rawStd string
+		rawURL string
+	}{
+		{"", "", "", ""},
+		{"f", "Zg==", "Zg", "Zg"},
+		{"fo", "Zm8=", "Zm8", "Zm8"},
+		{"foo", "Zm9v", "Zm9v", "Zm9v"},
+		{"foob", "Zm9vYg==", "Zm9vYg", "Zm9vYg"},
+		{"foobar", "Zm9vYmFy", "Zm9vYmFy", "Zm9vYmFy"},
+		{"\xfb\xff\xbf", "+/+/", "+/+/", "-_-_"},
+	}
+	for _, test := range testdata {
+		for encoding, expected := range map[*Encoding]string{StdEncoding: test.std,
+			StdEncoding.WithPadding(false): test.rawStd, URLEncoding.WithPadding(false): test.rawURL} {
+			assert.Equal(t, expected, string(encoding.Encode([]byte(test.data))))
+			assert.Equal(t, len(expected), encoding.EncodedLen(len(test.data)))
+			assert.Equal(t, test.data, string(encoding.MustDecodeString(expected)))
+		}
+	}
+	assert.Equal(t, "Zg==", string(URLEncoding.Encode([]byte("f"))))
+}
+
+func TestAppend(t *testing.T) {
+	var prefix = []byte("prefix:")
+	assert.Equal(t, "prefix:Zm9v", string(StdEncoding.AppendEncode(prefix, []byte("foo"))))
+	decoded, err := StdEncoding.AppendDecode(prefix, []byte("Zm9v"))
+	assert.Nil(t, err)
+	assert.Equal(t, "prefix:foo", string(decoded))
+	decoded, err = StdEncoding.AppendDecode(prefix, []byte("Zm9"))
+	assert.IsError(t, errors.ErrIllegal, err)
+	assert.Equal(t, "prefix:", string(decoded))
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	testdata := []struct {
+		encoding *Encoding
+		encoded  string
+		err      error
+	}{
+		{StdEncoding, "Zg", ErrPadding},
+		{StdEncoding, "Zg=", ErrPadding},
+		{StdEncoding, "Zh==", ErrPadding},
+		{StdEncoding, "Zg==Zg==", ErrPadding},
+		{StdEncoding, "Zm9v*", ErrCharacter},
+		{StdEncoding, "-_-_", ErrCharacter},
+		{StdEncoding, "Zm9vY", ErrLength},
+		{StdEncoding, "Z===", ErrLength},
+		{StdEncoding.WithPadding(false), "Zg==", ErrPadding},
+		{StdEncoding.WithPadding(false), "Z", ErrLength},
+		{URLEncoding, "+/+/", ErrCharacter},
+	}
+	for _, test := range testdata {
+		_, err := test.encoding.Decode([]byte(test.encoded))
+		assert.IsError(t, errors.ErrIllegal, err)
+		assert.IsError(t, test.err, err)
+	}
+}
+
+func TestPackageFunctions(t *testing.T) {
+	assert.Equal(t, "Zm9vYg==", string(Encode([]byte("foob"))))
+	assert.Equal(t, "foob", string(MustDecode([]byte("Zm9vYg=="))))
+	decoded, err := Decode([]byte("Zm9vYg=="))
+	assert.Nil(t, err)
+	assert.Equal(t, "foob", string(decoded))
+	_, err = Decode([]byte("Zm9vYg"))
+	assert.IsError(t, ErrPadding, err)
+}
+
+func TestMustDecodeIllegal(t *testing.T) {
+	defer assert.RequirePanic(t)
+	MustDecode([]byte("Zm9v*"))
+}
+
+func TestWithPadding(t *testing.T) {
+	var raw = URLEncoding.WithPadding(false)
+	assert.Equal(t, "Zg", string(raw.Encode([]byte("f"))))
+	assert.Equal(t, "Zg==", string(raw.WithPadding(true).Encode([]byte("f"))))
+	assert.Equal(t, "Zg==", string(URLEncoding.Encode([]byte("f"))))
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bech32
+
+import (
+	"strings"
+
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// reverse maps (lower-case) characters to their value, or -1 for characters outside of the charset.
+var reverse [128]int8
+
+func init() {
+	for i := range reverse {
+		reverse[i] = -1
+	}
+	for i := 0; i < len(charset); i++ {
+		reverse[charset[i]] = int8(i)
+	}
+}
+
+var generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// polymod updates the BCH checksum `chk` with 5-bit values.
+func polymod(chk uint32, values ...byte) uint32 {
+	for _, v := range values {
+		var top = chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := range generator {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// checksumHRP calculates the checksum over the expanded (lower-case) human-readable part.
+func checksumHRP(hrp string) uint32 {
+	var chk uint32 = 1
+	for i := 0; i < len(hrp); i++ {
+		chk = polymod(chk, hrp[i]>>5)
+	}
+	chk = polymod(chk, 0)
+	for i := 0; i < len(hrp); i++ {
+		chk = polymod(chk, hrp[i]&31)
+	}
+	return chk
+}
+
+// Encode encodes the human-readable part and data, consisting of 5-bit values, with the checksum of the
+// variant. The human-readable part may be upper-case, but the result is always lower-case. Returns
+// `errors.ErrIllegal` (with context) for a variant other than `Bech32` and `Bech32m`.
+func Encode(hrp string, data []byte, variant Variant) (string, error) {
+	if variant != Bech32 && variant != Bech32m {
+		return "", errors.Context(errors.ErrIllegal, "unknown variant: "+strconv.FormatUintDecimal(variant))
+	}
+	if len(hrp) < 1 {
+		return "", errors.Aggregate(errors.ErrIllegal, "human-readable part is empty", ErrSeparator)
+	}
+	if len(hrp)+1+len(data)+checksumLength > MaxLength {
+		return "", errors.Aggregate(errors.ErrIllegal, "encoding exceeds "+
+			strconv.FormatIntDecimal(MaxLength)+" characters", ErrLength)
+	}
+	if err := validateHRP(hrp); err != nil {
+		return "", err
+	}
+	hrp = strings.ToLower(hrp)
+	var b strings.Builder
+	b.Grow(len(hrp) + 1 + len(data) + checksumLength)
+	b.WriteString(hrp)
+	b.WriteByte('1')
+	var chk = checksumHRP(hrp)
+	for i, v := range data {
+		if v >= 32 {
+			return "", errors.Aggregate(errors.ErrIllegal, "data value at index "+strconv.FormatIntDecimal(i)+
+				" exceeds 5 bits", ErrCharacter)
+		}
+		chk = polymod(chk, v)
+		b.WriteByte(charset[v])
+	}
+	chk = polymod(chk, 0, 0, 0, 0, 0, 0) ^ variant.constant()
+	for i := checksumLength - 1; i >= 0; i-- {
+		b.WriteByte(charset[(chk>>(5*i))&31])
+	}
+	return b.String(), nil
+}
+
+// Decode decodes the encoded string into the (lower-case) human-readable part and data, consisting of 5-bit
+// values, without checksum. The variant is determined by the checksum.
+func Decode(encoded string) (string, []byte, Variant, error) {
+	if len(encoded) > MaxLength {
+		return "", nil, 0, errors.Aggregate(errors.ErrIllegal, "encoding exceeds "+
+			strconv.FormatIntDecimal(MaxLength)+" characters", ErrLength)
+	}
+	if err := validateHRP(encoded); err != nil {
+		return "", nil, 0, err
+	}
+	encoded = strings.ToLower(encoded)
+	var sep = strings.LastIndexByte(encoded, '1')
+	if sep < 0 {
+		return "", nil, 0, errors.Aggregate(errors.ErrIllegal, "separator '1' not found", ErrSeparator)
+	} else if sep == 0 {
+		return "", nil, 0, errors.Aggregate(errors.ErrIllegal, "human-readable part is empty", ErrSeparator)
+	}
+	if len(encoded)-sep-1 < checksumLength {
+		return "", nil, 0, errors.Aggregate(errors.ErrIllegal, "data-part at position "+
+			strconv.FormatIntDecimal(sep+1)+" is too short to contain checksum", ErrLength)
+	}
+	var hrp = encoded[:sep]
+	var chk = checksumHRP(hrp)
+	var data = make([]byte, len(encoded)-sep-1)
+	for i := range data {
+		var v = reverse[encoded[sep+1+i]]
+		if v < 0 {
+			return "", nil, 0, errors.Aggregate(errors.ErrIllegal, "character '"+encoded[sep+1+i:sep+2+i]+
+				"' at position "+strconv.FormatIntDecimal(sep+1+i)+" is not in the charset", ErrCharacter)
+		}
+		data[i] = byte(v)
+		chk = polymod(chk, data[i])
+	}
+	var variant Variant
+	switch chk {
+	case Bech32.constant():
+		variant = Bech32
+	case Bech32m.constant():
+		variant = Bech32m
+	default:
+		return "", nil, 0, errors.Aggregate(errors.ErrIllegal, "checksum does not match bech32 or bech32m",
+			ErrChecksum)
+	}
+	return hrp, data[:len(data)-checksumLength], variant, nil
+}
+
+// validateHRP checks that all characters are in the range [33,126] and that the characters are not of mixed
+// case.
+func validateHRP(s string) error {
+	var lower, upper bool
+	for i := 0; i < len(s); i++ {
+		var c = s[i]
+		if c < 33 || c > 126 {
+			return errors.Aggregate(errors.ErrIllegal, "character at position "+strconv.FormatIntDecimal(i)+
+				" is outside of the range [33,126]", ErrCharacter)
+		}
+		lower = lower || c >= 'a' && c <= 'z'
+		upper = upper || c >= 'A' && c <= 'Z'
+		if lower && upper {
+			return errors.Aggregate(errors.ErrIllegal, "mixed case at position "+strconv.FormatIntDecimal(i),
+				ErrCharacter)
+		}
+	}
+	return nil
+}
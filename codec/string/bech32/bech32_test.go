@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bech32
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestDecodeValid(t *testing.T) {
+	testdata := []struct {
+		encoded string
+		variant Variant
+	}{
+		{"A12UEL5L", Bech32},
+		{"a12uel5l", Bech32},
+		{"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs", Bech32},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", Bech32},
+		{"11" + strings.Repeat("q", 82) + "c8247j", Bech32},
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", Bech32},
+		{"?1ezyfcl", Bech32},
+		{"A1LQFN3A", Bech32m},
+		{"a1lqfn3a", Bech32m},
+		{"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6", Bech32m},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", Bech32m},
+		{"11" + strings.Repeat("l", 83) + "udsr8", Bech32m},
+		{"split1checkupstagehandshakeupstreamerranterredcaperredlc445v", Bech32m},
+		{"?1v759aa", Bech32m},
+	}
+	for _, test := range testdata {
+		hrp, data, variant, err := Decode(test.encoded)
+		assert.Nil(t, err)
+		assert.Equal(t, test.variant, variant)
+		// re-encoding produces the lower-case original
+		assert.Equal(t, strings.ToLower(test.encoded), builtin.Expect(Encode(hrp, data, variant)))
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	testdata := []struct {
+		encoded string
+		err     error
+	}{
+		{"\x201nwldj5", ErrCharacter},
+		{"\x7f1axkwrx", ErrCharacter},
+		{"\x801eym55h", ErrCharacter},
+		{"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx",
+			ErrLength},
+		{"pzry9x0s0muk", ErrSeparator},
+		{"1pzry9x0s0muk", ErrSeparator},
+		{"x1b4n0q5v", ErrCharacter},
+		{"li1dgmt3", ErrLength},
+		{"de1lg7wt\xff", ErrCharacter},
+		{"A1G7SGD8", ErrChecksum},
+		{"10a06t8", ErrSeparator},
+		{"1qzzfhee", ErrSeparator},
+		{"A12uEL5L", ErrCharacter},
+		{"a12uel5m", ErrChecksum},
+		{"M1VUXWEZ", ErrChecksum},
+		{"y1b0jsk6g", ErrCharacter},
+		{"lt1igcx5c0", ErrCharacter},
+		{"in1muywd", ErrLength},
+		{"mm1crxm3i", ErrCharacter},
+		{"au1s5cgom", ErrCharacter},
+	}
+	for _, test := range testdata {
+		_, _, _, err := Decode(test.encoded)
+		assert.IsError(t, test.err, err)
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+}
+
+func TestDecodeErrorPosition(t *testing.T) {
+	_, _, _, err := Decode("x1b4n0q5v")
+	assert.True(t, strings.Contains(err.Error(), "at position 2"))
+	_, _, _, err = Decode("a12uEl5l")
+	assert.True(t, strings.Contains(err.Error(), "at position 4"))
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	_, err := Encode("", nil, Bech32)
+	assert.IsError(t, ErrSeparator, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = Encode("a", nil, Variant(0))
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = Encode("a", nil, Bech32m+1)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = Encode("a", []byte{32}, Bech32)
+	assert.IsError(t, ErrCharacter, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = Encode("aB", nil, Bech32)
+	assert.IsError(t, ErrCharacter, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = Encode("a b", nil, Bech32)
+	assert.IsError(t, ErrCharacter, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = Encode("a", make([]byte, MaxLength-len("a1")-checksumLength), Bech32m)
+	assert.Nil(t, err)
+	_, err = Encode("a", make([]byte, MaxLength-len("a1")-checksumLength+1), Bech32m)
+	assert.IsError(t, ErrLength, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+}
+
+func TestVariantString(t *testing.T) {
+	assert.Equal(t, "bech32", Bech32.String())
+	assert.Equal(t, "bech32m", Bech32m.String())
+	assert.Equal(t, "unknown", Variant(0).String())
+}
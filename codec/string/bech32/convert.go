@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bech32
+
+import (
+	"github.com/cobratbq/goutils/assert"
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+// ConvertBits regroups data of `from`-bit values into `to`-bit values, e.g. bytes into 5-bit values for
+// encoding and back for decoding. With `pad`, the final group is padded with zero-bits. Without, any
+// remaining bits must be fewer than `from` and zero, which is the case when decoding regrouped bytes.
+// Bit-sizes must be in range [1,8].
+func ConvertBits(data []byte, from, to uint8, pad bool) ([]byte, error) {
+	assert.True(from >= 1 && from <= 8 && to >= 1 && to <= 8)
+	var result = make([]byte, 0, (len(data)*int(from)+int(to)-1)/int(to))
+	var acc uint32
+	var bits uint8
+	var mask uint32 = 1<<to - 1
+	for i, v := range data {
+		if v>>from != 0 {
+			return nil, errors.Context(errors.ErrIllegal, "value at index "+strconv.FormatIntDecimal(i)+
+				" exceeds "+strconv.FormatUintDecimal(from)+" bits")
+		}
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			result = append(result, byte(acc>>bits&mask))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(to-bits)&mask))
+		}
+	} else if bits >= from {
+		return nil, errors.Aggregate(errors.ErrIllegal, "excess padding", ErrPadding)
+	} else if acc<<(to-bits)&mask != 0 {
+		return nil, errors.Aggregate(errors.ErrIllegal, "non-zero padding", ErrPadding)
+	}
+	return result, nil
+}
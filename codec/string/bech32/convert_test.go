@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bech32
+
+import (
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestConvertBits(t *testing.T) {
+	assert.SlicesEqual(t, []byte{}, builtin.Expect(ConvertBits(nil, 8, 5, true)))
+	assert.SlicesEqual(t, []byte{31, 28}, builtin.Expect(ConvertBits([]byte{0xff}, 8, 5, true)))
+	assert.SlicesEqual(t, []byte{0xff}, builtin.Expect(ConvertBits([]byte{31, 28}, 5, 8, false)))
+	var data = []byte{0x00, 0x01, 0x7f, 0x80, 0xff}
+	var converted = builtin.Expect(ConvertBits(data, 8, 5, true))
+	assert.Equal(t, 8, len(converted))
+	assert.SlicesEqual(t, data, builtin.Expect(ConvertBits(converted, 5, 8, false)))
+	assert.SlicesEqual(t, []byte{0x1, 0x0, 0x1}, builtin.Expect(ConvertBits([]byte{0x5}, 8, 1, true))[5:])
+}
+
+func TestConvertBitsInvalid(t *testing.T) {
+	_, err := ConvertBits([]byte{32}, 5, 8, true)
+	assert.IsError(t, errors.ErrIllegal, err)
+	// non-zero padding
+	_, err = ConvertBits([]byte{31, 29}, 5, 8, false)
+	assert.IsError(t, ErrPadding, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	// excess padding: 3 values of 5 bits, leaves 7 bits after one byte
+	_, err = ConvertBits([]byte{31, 28, 0}, 5, 8, false)
+	assert.IsError(t, ErrPadding, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+}
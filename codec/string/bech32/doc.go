@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Bech32 and Bech32m, the checksummed base32-encodings with a human-readable part (HRP).
+//
+// An encoded string consists of the human-readable part, the separator `1`, and the data-part of 5-bit
+// values followed by a 6-character BCH checksum. The variants differ only in the constant for the
+// checksum. Bech32 is used for segwit version 0 addresses and many other formats, Bech32m for segwit version
+// 1 and higher. Encoded strings are limited to `MaxLength` characters and must not mix cases. Encoding
+// always produces lower-case strings.
+//
+// `ConvertBits` regroups data from bytes into 5-bit values and back. `EncodeSegwit` and `DecodeSegwit`
+// provide segwit addresses, i.e. witness version and witness program, on top of the codec.
+//
+// Errors are `errors.ErrIllegal`, as for the other codecs, together with one of the errors below that
+// indicates the specific cause.
+//
+// ref: <https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki>
+// ref: <https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki>
+package bech32
+
+import "github.com/cobratbq/goutils/std/errors"
+
+// ErrLength indicates that the encoded string, or one of its parts, has an invalid length.
+var ErrLength = errors.NewStringError("invalid length")
+
+// ErrCharacter indicates an illegal character, a value outside of the range of 5-bit values, or mixed case.
+var ErrCharacter = errors.NewStringError("illegal character")
+
+// ErrSeparator indicates that the separator is missing, or that the human-readable part is empty.
+var ErrSeparator = errors.NewStringError("missing separator or human-readable part")
+
+// ErrChecksum indicates that the checksum does not match either variant.
+var ErrChecksum = errors.NewStringError("checksum mismatch")
+
+// ErrPadding indicates excess padding or non-zero padding bits in the regrouping of bits.
+var ErrPadding = errors.NewStringError("invalid padding")
+
+// MaxLength is the maximum length of an encoded string.
+const MaxLength = 90
+
+// checksumLength is the number of characters of the checksum.
+const checksumLength = 6
+
+// Variant is the variant of Bech32-encoding, which determines the checksum.
+type Variant uint8
+
+const (
+	// Bech32 is the original variant, as specified in BIP-173.
+	Bech32 Variant = iota + 1
+	// Bech32m is the modified variant, as specified in BIP-350.
+	Bech32m
+)
+
+func (v Variant) String() string {
+	switch v {
+	case Bech32:
+		return "bech32"
+	case Bech32m:
+		return "bech32m"
+	default:
+		return "unknown"
+	}
+}
+
+// constant is the constant that the checksum of the variant must produce.
+func (v Variant) constant() uint32 {
+	switch v {
+	case Bech32:
+		return 1
+	case Bech32m:
+		return 0x2bc830a3
+	default:
+		panic("BUG: unknown variant")
+	}
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bech32
+
+import (
+	"strings"
+
+	"github.com/cobratbq/goutils/std/errors"
+	"github.com/cobratbq/goutils/std/strconv"
+)
+
+// ErrHRP indicates that the human-readable part is not the expected one.
+var ErrHRP = errors.NewStringError("unexpected human-readable part")
+
+// ErrWitness indicates an invalid witness version or witness program, or a witness version that is encoded
+// with the wrong variant.
+var ErrWitness = errors.NewStringError("invalid witness version or program")
+
+// MaxWitnessVersion is the highest witness version.
+const MaxWitnessVersion = 16
+
+// EncodeSegwit encodes the segwit address for witness version and program, e.g. with human-readable part
+// `bc` for Bitcoin mainnet or `tb` for testnet. Version 0 is encoded as Bech32, others as Bech32m.
+func EncodeSegwit(hrp string, version uint8, program []byte) (string, error) {
+	if err := validateWitness(version, program); err != nil {
+		return "", err
+	}
+	data, err := ConvertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return Encode(hrp, append([]byte{version}, data...), witnessVariant(version))
+}
+
+// DecodeSegwit decodes the segwit address, verifying the expected human-readable part, and returns witness
+// version and program.
+func DecodeSegwit(hrp, address string) (uint8, []byte, error) {
+	decodedHRP, data, variant, err := Decode(address)
+	if err != nil {
+		return 0, nil, err
+	}
+	if decodedHRP != strings.ToLower(hrp) {
+		return 0, nil, errors.Aggregate(errors.ErrIllegal, "expected '"+hrp+"', got '"+decodedHRP+"'", ErrHRP)
+	}
+	if len(data) < 1 {
+		return 0, nil, errors.Aggregate(errors.ErrIllegal, "witness version is missing", ErrWitness)
+	}
+	var version = data[0]
+	if version > MaxWitnessVersion {
+		return 0, nil, errors.Aggregate(errors.ErrIllegal, "witness version "+
+			strconv.FormatUintDecimal(version)+" is not supported", ErrWitness)
+	}
+	if variant != witnessVariant(version) {
+		return 0, nil, errors.Aggregate(errors.ErrIllegal, "witness version "+
+			strconv.FormatUintDecimal(version)+" must be encoded as "+witnessVariant(version).String(),
+			ErrWitness)
+	}
+	var program []byte
+	if program, err = ConvertBits(data[1:], 5, 8, false); err != nil {
+		return 0, nil, err
+	}
+	if err = validateWitness(version, program); err != nil {
+		return 0, nil, err
+	}
+	return version, program, nil
+}
+
+func witnessVariant(version uint8) Variant {
+	if version == 0 {
+		return Bech32
+	}
+	return Bech32m
+}
+
+func validateWitness(version uint8, program []byte) error {
+	if version > MaxWitnessVersion {
+		return errors.Aggregate(errors.ErrIllegal, "witness version "+
+			strconv.FormatUintDecimal(version)+" is not supported", ErrWitness)
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return errors.Aggregate(errors.ErrIllegal, "witness program of "+
+			strconv.FormatIntDecimal(len(program))+" bytes is outside of the range [2,40]", ErrWitness)
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return errors.Aggregate(errors.ErrIllegal, "witness program of "+
+			strconv.FormatIntDecimal(len(program))+" bytes is invalid for version 0", ErrWitness)
+	}
+	return nil
+}
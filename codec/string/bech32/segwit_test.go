@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package bech32
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/cobratbq/goutils/std/builtin"
+	"github.com/cobratbq/goutils/std/errors"
+	assert "github.com/cobratbq/goutils/std/testing"
+)
+
+func TestSegwitValid(t *testing.T) {
+	testdata := []struct {
+		address string
+		version uint8
+		program string
+	}{
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", 0, "751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", 0,
+			"1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", 1,
+			"751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"BC1SW50QGDZ25J", 16, "751e"},
+		{"bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", 2, "751e76e8199196d454941c45d1b3a323"},
+		{"tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", 0,
+			"000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", 1,
+			"000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", 1,
+			"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+	}
+	for _, test := range testdata {
+		var hrp = strings.ToLower(test.address[:2])
+		version, program, err := DecodeSegwit(hrp, test.address)
+		assert.Nil(t, err)
+		assert.Equal(t, test.version, version)
+		assert.Equal(t, test.program, hex.EncodeToString(program))
+		assert.Equal(t, strings.ToLower(test.address), builtin.Expect(EncodeSegwit(hrp, version, program)))
+	}
+}
+
+func TestSegwitInvalid(t *testing.T) {
+	testdata := []struct {
+		address string
+		err     error
+	}{
+		// invalid human-readable part
+		{"tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut", ErrHRP},
+		// invalid checksum
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", ErrWitness},
+		{"tb1z0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqglt7rf", ErrWitness},
+		{"BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL", ErrWitness},
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", ErrWitness},
+		{"tb1q0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq24jc47", ErrWitness},
+		// invalid character in checksum
+		{"bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4", ErrCharacter},
+		// invalid witness version
+		{"BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R", ErrWitness},
+		// invalid program length
+		{"bc1pw5dgrnzv", ErrWitness},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0muaewav253zgeav", ErrWitness},
+		{"BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P", ErrWitness},
+		// mixed case
+		{"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47Zagq", ErrCharacter},
+		// padding
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v07qwwzcrf", ErrPadding},
+		{"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vpggkg4j", ErrPadding},
+		// empty data-part
+		{"bc1gmk9yu", ErrWitness},
+	}
+	for _, test := range testdata {
+		var hrp = "bc"
+		if strings.HasPrefix(strings.ToLower(test.address), "tb") {
+			hrp = "tb"
+		}
+		_, _, err := DecodeSegwit(hrp, test.address)
+		assert.IsError(t, test.err, err)
+		assert.IsError(t, errors.ErrIllegal, err)
+	}
+}
+
+func TestEncodeSegwitInvalid(t *testing.T) {
+	_, err := EncodeSegwit("bc", 17, make([]byte, 20))
+	assert.IsError(t, ErrWitness, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = EncodeSegwit("bc", 0, make([]byte, 21))
+	assert.IsError(t, ErrWitness, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = EncodeSegwit("bc", 1, make([]byte, 1))
+	assert.IsError(t, ErrWitness, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+	_, err = EncodeSegwit("bc", 1, make([]byte, 41))
+	assert.IsError(t, ErrWitness, err)
+	assert.IsError(t, errors.ErrIllegal, err)
+}